@@ -24,7 +24,9 @@ import (
 	"github.com/nebari-dev/nebi/internal/rbac"
 	"github.com/nebari-dev/nebi/internal/service"
 	"github.com/nebari-dev/nebi/internal/store"
+	"github.com/nebari-dev/nebi/internal/versioncache"
 	"github.com/nebari-dev/nebi/internal/worker"
+	"github.com/nebari-dev/nebi/internal/wsevents"
 	"gorm.io/gorm"
 )
 
@@ -179,6 +181,11 @@ func (a *App) startEmbeddedServer(cfg *config.Config, database *gorm.DB) {
 
 	// Create service and worker (desktop app uses local mode, no encryption key needed)
 	svc := service.New(database, jobQueue, exec, true, nil, rbac.NewDefaultProvider())
+	svc.ApplyWorkspacesConfig(cfg.Workspaces)
+	events := wsevents.NewBroker()
+	svc.SetEventBroker(events)
+	versionCache := versioncache.New(cfg.Workspaces.VersionCacheBytes)
+	svc.SetVersionCache(versionCache)
 	jobSvc := service.NewJobService(database, true)
 	w := worker.New(jobQueue, exec, svc, jobSvc, slog.Default(), nil)
 	workerCtx, workerCancel := context.WithCancel(context.Background())
@@ -198,7 +205,7 @@ func (a *App) startEmbeddedServer(cfg *config.Config, database *gorm.DB) {
 
 	// Initialize API router
 	logToFile("startEmbeddedServer: initializing router...")
-	router := api.NewRouter(cfg, database, jobQueue, exec, w.GetBroker(), nil, slog.Default())
+	router := api.NewRouter(cfg, database, jobQueue, exec, w.GetBroker(), events, versionCache, nil, slog.Default())
 	a.router = router
 	close(a.ready) // signal that router is ready for Wails handler
 	logToFile("startEmbeddedServer: router initialized")
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/nebari-dev/nebi/internal/cliclient"
+	"github.com/spf13/cobra"
+)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Server administration (requires an admin account)",
+}
+
+var adminUserWorkspacesJSON bool
+
+var adminUserWorkspacesCmd = &cobra.Command{
+	Use:   "user-workspaces <username>",
+	Short: "List the workspaces a user can access and their role on each",
+	Long: `List every workspace on the server that a user can access, across all
+owners, with the role they hold: owner, or the strongest of their direct
+and group permissions.
+
+Examples:
+  nebi admin user-workspaces alice
+  nebi admin user-workspaces alice --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdminUserWorkspaces,
+}
+
+func init() {
+	adminUserWorkspacesCmd.Flags().BoolVar(&adminUserWorkspacesJSON, "json", false, "Output as JSON")
+	adminCmd.AddCommand(adminUserWorkspacesCmd)
+}
+
+func runAdminUserWorkspaces(cmd *cobra.Command, args []string) error {
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	user, err := findUserByName(client, ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := client.ListUserWorkspaces(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("listing workspaces for %q: %w", user.Username, err)
+	}
+
+	if adminUserWorkspacesJSON {
+		if workspaces == nil {
+			workspaces = []cliclient.UserWorkspace{}
+		}
+		return writeJSON(workspaces)
+	}
+
+	if len(workspaces) == 0 {
+		fmt.Fprintf(os.Stderr, "User %q has no workspaces.\n", user.Username)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tROLE\tOWNER\tSTATUS\tUPDATED")
+	for _, ws := range workspaces {
+		owner := "-"
+		if ws.Owner != nil {
+			owner = ws.Owner.Username
+		}
+		updated := ws.UpdatedAt.Format("2006-01-02 15:04")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ws.Name, ws.Role, owner, ws.Status, updated)
+	}
+	return w.Flush()
+}
+
+// findUserByName resolves a username to a user via the admin user list.
+func findUserByName(client *cliclient.Client, ctx context.Context, username string) (*cliclient.User, error) {
+	users, err := client.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing users: %w", err)
+	}
+	for i := range users {
+		if users[i].Username == username {
+			return &users[i], nil
+		}
+	}
+	return nil, fmt.Errorf("user %q not found", username)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -189,7 +190,7 @@ func findWorkspacesByNameWithSync(s *store.Store, name string) ([]store.LocalWor
 }
 
 // saveOrigin records a push/pull origin for the current working directory.
-func saveOrigin(remoteID, name, tag, action, tomlContent, lockContent string) error {
+func saveOrigin(remoteID, name, tag, action string, version int, tomlContent, lockContent string) error {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("getting working directory: %w", err)
@@ -218,6 +219,7 @@ func saveOrigin(remoteID, name, tag, action, tomlContent, lockContent string) er
 	ws.OriginName = name
 	ws.OriginTag = tag
 	ws.OriginAction = action
+	ws.OriginVersion = version
 	ws.OriginTomlHash = tomlHash
 	ws.OriginLockHash = store.ContentHash(lockContent)
 
@@ -242,9 +244,23 @@ func formatTimestamp(ts string) string {
 	return t.Format("2006-01-02 15:04")
 }
 
+// jsonSchemaVersion is the schema_version field of every --json output
+// (diff, status and info). Bump it when a field is removed or renamed or
+// changes type or meaning; adding a field is not a breaking change.
+//
+// Compatibility notes:
+//
+//	1: first versioned schema.
+const jsonSchemaVersion = 1
+
 // writeJSON marshals v as indented JSON to stdout.
 func writeJSON(v any) error {
-	enc := json.NewEncoder(os.Stdout)
+	return writeJSONTo(os.Stdout, v)
+}
+
+// writeJSONTo marshals v as indented JSON to w.
+func writeJSONTo(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	return enc.Encode(v)
 }
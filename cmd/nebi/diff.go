@@ -3,17 +3,52 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/nebari-dev/nebi/internal/cliclient"
 	"github.com/nebari-dev/nebi/internal/diff"
+	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
 	"github.com/nebari-dev/nebi/internal/store"
 	"github.com/spf13/cobra"
 )
 
-var diffLock bool
+var (
+	diffLock        bool
+	diffSummaryOnly bool
+	diffLockEnv     string
+	diffNoLockHint  bool
+	diffNoHints     bool
+	diffContextSec  bool
+	diffGroupBy     string
+	diffLockStale   bool
+	diffMarkdown    bool
+	diffSincePull   bool
+	diffIgnoreHash  bool
+	diffRefresh     bool
+	diffEnv         string
+	diffEnvDefault  bool
+	diffStatOnly    bool
+	diffCountOnly   bool
+	diffInstalled   bool
+	diffOnly        []string
+	diffJSON        bool
+	diffAgainst     string
+	diffFailOnMajor bool
+	diffFailOnMinor bool
+	diffStrict      bool
+	diffOutputFile  string
+	diffBaseline    string
+	diffLeftToml    string
+	diffRightToml   string
+	diffExitZero    bool
+	diffExitChange  bool
+	diffSecurity    bool
+)
 
 var diffCmd = &cobra.Command{
 	Use:   "diff <ref-a> [ref-b] [--lock]",
@@ -23,22 +58,101 @@ Each reference can be:
   - A path (contains a slash): ./dir, /tmp/project, foo/bar
   - A tracked workspace name (bare word): data-science
   - A server ref (contains a colon): myworkspace:v1
+  - "-" to read one side from stdin (not both)
+
+On stdin, the pixi.toml may be followed by a pixi.lock, separated by a line
+containing exactly "--- pixi.lock ---". Without that line the whole input
+is the pixi.toml and the side has no lock file.
+
+Use --left-toml and --right-toml instead of refs to compare two bare
+pixi.toml files. Either can be "-" for stdin; the other must be a file.
 
 If no refs are given, compares the current directory against the last
-pushed/pulled origin.
+pushed/pulled origin. The origin tag is re-resolved on the server, so if it
+has moved since the pull the comparison includes those upstream changes.
+Use --since-pull to compare against the exact version that was pulled.
+Use --against <tag> to compare the origin tag with another tag of the same
+server workspace instead of the current directory.
 
 If only one ref is given, it is compared against the current directory.
 
+With --env <name> (or --env-default), pixi.toml is compared by the
+effective dependencies of that environment: the default feature merged with
+the environment's features. The pixi.lock comparison is not affected; use
+--lock-env to scope it as well.
+
+Server version content is cached under the data directory for a few
+minutes, so repeated diffs of the same version do not re-download it. Tags
+are re-resolved on every run. Use --refresh to bypass the cache.
+
 Examples:
   nebi diff                                    # local vs origin
+  nebi diff --since-pull                       # local changes since last pull
+  nebi diff --against v2.0                     # origin tag vs v2.0 on the server
+  nebi diff myworkspace:v1 --env gpu           # effective deps of "gpu" vs cwd
   nebi diff ./other-project                    # other dir vs cwd
   nebi diff ./project-a ./project-b            # two local dirs
   nebi diff data-science                       # tracked workspace vs cwd
   nebi diff myworkspace:v1                     # server version vs cwd
   nebi diff myworkspace:v1 myworkspace:v2      # two server versions
   nebi diff myworkspace:v1 ./local-dir         # server vs local dir
+  generate-manifest | nebi diff - ./project    # stdin vs local dir
+  nebi diff --left-toml a.toml --right-toml -  # file vs stdin
 
-Use --lock to also compare pixi.lock files.`,
+Use --lock to also compare pixi.lock files.
+Use --lock-env <name> to limit the lock comparison to one pixi environment.
+Use --context-sections to show each changed pixi.toml table in full.
+Use --no-hints to drop "[Use ...]" guidance footers, e.g. in scripts.
+Use --only <table> (repeatable) to limit the pixi.toml comparison to the
+named tables and the tables nested below them, e.g. --only dependencies or
+--only feature.gpu. The pixi.lock comparison is not affected.
+Use --lock --group-by platform to split lock changes per platform; with
+--json the breakdown is added as lock.platforms.
+Use --fail-if-lock-stale [ref] to check a single source (default: the
+current directory) for dependencies that pixi.lock does not contain yet,
+e.g. after editing pixi.toml without re-running 'pixi lock'.
+Use --installed [dir|workspace] to check that the installed pixi
+environment (default: "default", or --env) matches pixi.lock for the
+current platform, e.g. to catch packages added with a manual 'pip install'.
+It reports packages installed but not locked, locked but not installed, and
+installed at a different version, and fails if there are any.
+Use --fail-on-major (or --fail-on-minor) to exit non-zero when an updated
+pixi.lock package changes its major (or major or minor) version, e.g. to
+block dependency upgrades in CI. Versions that cannot be read as
+major.minor.patch, or a pixi.lock that cannot be parsed at all, produce a
+warning and only fail the check with --strict.
+Use --json for machine-readable output. Every JSON object carries a
+schema_version, which changes only when the shape changes incompatibly.
+Use --markdown to render the changes for a pull request comment.
+Use --highlight-security to flag updated pixi.lock packages whose old
+version has known advisories fixed by the update, or whose new version is
+still affected. It queries the OSV database (set NEBI_ADVISORY_URL to use
+another OSV-compatible source) under the PyPI ecosystem, caches results for
+an hour (--refresh bypasses the cache), and continues without highlights if
+the source is unreachable.
+Use --baseline-file <file> to check the diff against one saved earlier
+with --json --output <file>. It prints the changes that are new ("+") or
+gone ("-") since the baseline and fails if there are any, so intended
+drift is recorded by updating the baseline. Labels of the compared
+sources are ignored.
+Use --output <file> to write the diff (text, JSON or Markdown) to a file
+instead of stdout, e.g. to keep it as a CI artifact; the exit code is the
+same as without it. "-" means stdout.
+On a terminal, output taller than the screen is shown through $PAGER
+(default: less -FRX); pass --no-pager to print it directly.
+Use --summary-only to print just the per-file change counts, e.g.:
+  toml: 3 changed
+  lock: +5 -2 ~7
+Use --stat-only-exit in CI: it prints the same counts on one line, writes
+nothing to stderr, and exits 0 (no differences), 1 (differences) or 2
+(error). Set NEBI_DIFF_EXIT_ON_CHANGE=false (the diff.exit_on_change
+setting) to exit 0 on differences by default. --exit-zero and
+--exit-on-change override it for one run: flag, then environment, then
+the default (exit on change).
+Use --count-only for the simplest gate: it prints only the total number of
+changes (pixi.toml changes plus pixi.lock packages added, removed and
+updated) and exits 0 unless the diff itself fails, e.g.
+  [ "$(nebi diff --count-only)" = 0 ]`,
 	Args:              cobra.RangeArgs(0, 2),
 	RunE:              runDiff,
 	ValidArgsFunction: completeWorkspaceNamesOrPaths,
@@ -46,6 +160,35 @@ Use --lock to also compare pixi.lock files.`,
 
 func init() {
 	diffCmd.Flags().BoolVar(&diffLock, "lock", false, "Also diff pixi.lock files")
+	diffCmd.Flags().BoolVar(&diffSummaryOnly, "summary-only", false, "Print only per-file change counts")
+	diffCmd.Flags().StringVar(&diffLockEnv, "lock-env", "", "Limit the pixi.lock comparison to the named environment")
+	diffCmd.Flags().BoolVar(&diffContextSec, "context-sections", false, "Show each changed pixi.toml table in full, before and after")
+	diffCmd.Flags().StringVar(&diffGroupBy, "group-by", "", "Group --lock package changes by: platform")
+	diffCmd.Flags().BoolVar(&diffLockStale, "fail-if-lock-stale", false, "Check that pixi.lock covers every pixi.toml dependency and fail if not")
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Output as JSON (includes a schema_version field)")
+	diffCmd.Flags().BoolVar(&diffMarkdown, "markdown", false, "Render the diff as Markdown (e.g. for pull request comments)")
+	diffCmd.Flags().StringVar(&diffAgainst, "against", "", "Compare the origin tag with this tag of the origin workspace on the server")
+	diffCmd.Flags().BoolVar(&diffSincePull, "since-pull", false, "Compare the current directory with the exact version it was last pulled from or pushed to")
+	diffCmd.Flags().BoolVar(&diffIgnoreHash, "ignore-lock-hash-only", false, "Treat pixi.lock as unchanged unless a package name or version differs (ignore URL, hash and build changes)")
+	diffCmd.Flags().BoolVar(&diffRefresh, "refresh", false, "Re-fetch server versions instead of using content cached by a recent diff")
+	diffCmd.Flags().StringVar(&diffEnv, "env", "", "Compare the effective dependencies of the named pixi environment (base plus its features)")
+	diffCmd.Flags().BoolVar(&diffEnvDefault, "env-default", false, "Like --env default")
+	diffCmd.Flags().StringArrayVar(&diffOnly, "only", nil, "Only compare the named pixi.toml table, e.g. dependencies or feature.gpu.dependencies (repeatable)")
+	diffCmd.Flags().BoolVar(&diffInstalled, "installed", false, "Compare the installed environment with pixi.lock (requires 'pixi install' to have run)")
+	diffCmd.Flags().BoolVar(&diffFailOnMajor, "fail-on-major", false, "Exit non-zero if an updated pixi.lock package changes major version")
+	diffCmd.Flags().BoolVar(&diffFailOnMinor, "fail-on-minor", false, "Exit non-zero if an updated pixi.lock package changes major or minor version")
+	diffCmd.Flags().BoolVar(&diffStrict, "strict", false, "With --fail-on-major/--fail-on-minor, also fail on versions that cannot be parsed")
+	diffCmd.Flags().StringVar(&diffBaseline, "baseline-file", "", "Fail if the diff differs from one saved with --json (prints what changed)")
+	diffCmd.Flags().StringVarP(&diffOutputFile, "output", "o", "", "Write the diff to this file instead of stdout (\"-\" for stdout)")
+	diffCmd.Flags().BoolVar(&diffCountOnly, "count-only", false, "Print only the total number of changes")
+	diffCmd.Flags().BoolVar(&diffStatOnly, "stat-only-exit", false, "Print only a one-line change count and exit 1 if there are differences (2 on error)")
+	diffCmd.Flags().BoolVar(&diffExitZero, "exit-zero", false, "With --stat-only-exit, exit 0 when there are differences (overrides NEBI_DIFF_EXIT_ON_CHANGE)")
+	diffCmd.Flags().BoolVar(&diffExitChange, "exit-on-change", false, "With --stat-only-exit, exit 1 when there are differences (overrides NEBI_DIFF_EXIT_ON_CHANGE)")
+	diffCmd.Flags().BoolVar(&diffNoLockHint, "no-lock-hint", false, "Don't print the pixi.lock changed footer when --lock is not set")
+	diffCmd.Flags().StringVar(&diffLeftToml, "left-toml", "", "Compare this pixi.toml file (\"-\" for stdin) against --right-toml")
+	diffCmd.Flags().StringVar(&diffRightToml, "right-toml", "", "Compare --left-toml against this pixi.toml file (\"-\" for stdin)")
+	diffCmd.Flags().BoolVar(&diffSecurity, "highlight-security", false, "Flag updated pixi.lock packages with known security advisories (queries OSV)")
+	diffCmd.Flags().BoolVar(&diffNoHints, "no-hints", false, "Don't print \"[Use ...]\" guidance footers")
 }
 
 // diffSource represents a resolved source of pixi files for diffing.
@@ -55,7 +198,258 @@ type diffSource struct {
 	lock  string // pixi.lock content (may be empty)
 }
 
+// diffResult holds the compared sources and their differences.
+type diffResult struct {
+	srcA, srcB  *diffSource
+	toml        *diff.TomlDiff
+	lock        *diff.LockSummary
+	lockChanged bool
+	security    []securityNote // set with --highlight-security
+}
+
+func (r *diffResult) hasChanges() bool {
+	return r.toml.HasChanges() || r.lockChanged
+}
+
 func runDiff(cmd *cobra.Command, args []string) error {
+	// --stat-only-exit reports everything, including errors, through stdout's
+	// single stat line and the exit code, so cobra must not print anything.
+	cmd.SilenceErrors = diffStatOnly
+	cmd.SilenceUsage = diffStatOnly
+	if diffStatOnly {
+		exitOnChange, err := diffExitOnChange()
+		if err != nil {
+			return &exitCodeError{code: 2, err: err}
+		}
+		return runDiffStatOnlyExit(args, exitOnChange)
+	}
+	if diffExitZero || diffExitChange {
+		return fmt.Errorf("--exit-zero and --exit-on-change only apply to --stat-only-exit")
+	}
+	if diffCountOnly {
+		return runDiffCountOnly(args)
+	}
+
+	if diffGroupBy != "" && diffGroupBy != "platform" {
+		return fmt.Errorf("invalid --group-by %q: only \"platform\" is supported", diffGroupBy)
+	}
+
+	if diffMarkdown && diffSummaryOnly {
+		return fmt.Errorf("--markdown and --summary-only cannot be used together")
+	}
+	if diffJSON && (diffMarkdown || diffSummaryOnly) {
+		return fmt.Errorf("--json cannot be combined with --markdown or --summary-only")
+	}
+	gate := bumpGateLevel()
+	if diffStrict && gate == diff.BumpNone {
+		return fmt.Errorf("--strict requires --fail-on-major or --fail-on-minor")
+	}
+	if gate != diff.BumpNone && (diffLockStale || diffInstalled) {
+		return fmt.Errorf("--fail-on-major and --fail-on-minor compare two sources and cannot be combined with --fail-if-lock-stale or --installed")
+	}
+	if diffBaseline != "" && (diffJSON || diffMarkdown || diffSummaryOnly || diffLockStale || diffInstalled) {
+		return fmt.Errorf("--baseline-file cannot be combined with --json, --markdown, --summary-only, --fail-if-lock-stale or --installed")
+	}
+	if diffLockStale {
+		return runLockStaleCheck(args)
+	}
+	if diffInstalled {
+		return runInstalledCheck(args)
+	}
+
+	res, err := computeDiff(args)
+	if err != nil {
+		return err
+	}
+	srcA, srcB := res.srcA, res.srcB
+	tomlDiff, lockSummary, lockChanged := res.toml, res.lock, res.lockChanged
+
+	if diffBaseline != "" {
+		if err := runBaselineCheck(res); err != nil {
+			return err
+		}
+		return checkBumpGate(res, gate)
+	}
+
+	if diffJSON {
+		if err := writeDiffReport(func(w io.Writer) error {
+			return writeJSONTo(w, newDiffJSON(res))
+		}); err != nil {
+			return err
+		}
+		return checkBumpGate(res, gate)
+	}
+
+	if diffSummaryOnly {
+		if err := writeDiffReport(func(w io.Writer) error {
+			_, err := fmt.Fprint(w, formatDiffSummary(tomlDiff, lockSummary, lockChanged))
+			return err
+		}); err != nil {
+			return err
+		}
+		return checkBumpGate(res, gate)
+	}
+
+	out, err := openDiffOutput(true)
+	if err != nil {
+		return err
+	}
+
+	if diffMarkdown {
+		var lock *diff.LockSummary
+		if lockChanged {
+			lock = lockSummary
+		}
+		fmt.Fprint(out, diff.FormatMarkdown(tomlDiff, lock, srcA.label, srcB.label))
+		if len(res.security) > 0 {
+			fmt.Fprint(out, formatSecurityNotesMarkdown(res.security))
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+		return checkBumpGate(res, gate)
+	}
+
+	changed, err := outputDiffText(out, srcA, srcB, tomlDiff, lockSummary, lockChanged)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	if len(res.security) > 0 {
+		fmt.Fprint(out, formatSecurityNotes(res.security))
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Fprintln(os.Stderr, "No differences.")
+	}
+	return checkBumpGate(res, gate)
+}
+
+// openDiffOutput returns where the rendered diff is written: the --output
+// file, or stdout (through the pager when paged is set).
+func openDiffOutput(paged bool) (io.WriteCloser, error) {
+	if diffOutputFile != "" && diffOutputFile != "-" {
+		f, err := os.Create(diffOutputFile)
+		if err != nil {
+			return nil, fmt.Errorf("creating output file: %w", err)
+		}
+		return f, nil
+	}
+	if paged {
+		return newPagedOutput(), nil
+	}
+	return stdoutCloser{os.Stdout}, nil
+}
+
+// stdoutCloser lets stdout stand in for an --output file; closing it is a
+// no-op.
+type stdoutCloser struct{ io.Writer }
+
+func (stdoutCloser) Close() error { return nil }
+
+// writeDiffReport writes a check's report with openDiffOutput.
+func writeDiffReport(write func(w io.Writer) error) error {
+	out, err := openDiffOutput(false)
+	if err != nil {
+		return err
+	}
+	err = write(out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// bumpGateLevel returns the smallest version bump that fails the diff, as
+// set by --fail-on-major or --fail-on-minor.
+func bumpGateLevel() diff.Bump {
+	switch {
+	case diffFailOnMinor:
+		return diff.BumpMinor
+	case diffFailOnMajor:
+		return diff.BumpMajor
+	default:
+		return diff.BumpNone
+	}
+}
+
+// checkBumpGate lists the updated pixi.lock packages whose version bump
+// reaches gate and fails if there are any. Packages whose versions cannot
+// be parsed are listed too, but only fail the check with --strict.
+func checkBumpGate(res *diffResult, gate diff.Bump) error {
+	if gate == diff.BumpNone || !res.lockChanged || res.lock == nil {
+		return nil
+	}
+	if res.lock.PackagesUpdated < 0 {
+		// The lock files could not be parsed, so there are no package
+		// versions to compare.
+		fmt.Fprintf(os.Stderr, "Warning: pixi.lock could not be parsed; the %s version check was not evaluated\n", bumpGateLabel(gate))
+		if diffStrict {
+			return fmt.Errorf("pixi.lock could not be parsed, so the %s version check could not be evaluated (--strict)", bumpGateLabel(gate))
+		}
+		return nil
+	}
+	offending, unparseable := diff.CheckBumps(res.lock.Updated, gate)
+
+	if len(offending) > 0 {
+		fmt.Fprintf(os.Stderr, "Packages with a %s version change:\n", bumpGateLabel(gate))
+		for _, p := range offending {
+			fmt.Fprintf(os.Stderr, "  %s %s -> %s (%s)\n", p.Name, p.OldVersion, p.NewVersion, p.Bump)
+		}
+	}
+	if len(unparseable) > 0 {
+		fmt.Fprintln(os.Stderr, "Packages with versions that could not be compared:")
+		for _, u := range unparseable {
+			fmt.Fprintf(os.Stderr, "  %s %s -> %s\n", u.Name, u.OldVersion, u.NewVersion)
+		}
+	}
+
+	if !diffStrict {
+		unparseable = nil
+	}
+	switch {
+	case len(offending) > 0 && len(unparseable) > 0:
+		return fmt.Errorf("%d package(s) have a %s version change and %d could not be compared", len(offending), bumpGateLabel(gate), len(unparseable))
+	case len(offending) > 0:
+		return fmt.Errorf("%d package(s) have a %s version change", len(offending), bumpGateLabel(gate))
+	case len(unparseable) > 0:
+		return fmt.Errorf("%d package(s) have versions that could not be compared (--strict)", len(unparseable))
+	}
+	return nil
+}
+
+func bumpGateLabel(gate diff.Bump) string {
+	if gate == diff.BumpMinor {
+		return "major or minor"
+	}
+	return "major"
+}
+
+// resolveDiffSources resolves the two sides of a diff from the command
+// arguments. With no arguments the current directory is compared against
+// the current content of its origin tag; --since-pull instead compares it
+// against the exact version recorded by the last push or pull.
+func resolveDiffSources(args []string) (*diffSource, *diffSource, error) {
+	if diffLeftToml != "" || diffRightToml != "" {
+		return resolveNamedTomlSources(args)
+	}
+	if len(args) == 2 && args[0] == stdinRef && args[1] == stdinRef {
+		return nil, nil, fmt.Errorf("only one side of a diff can be read from stdin")
+	}
+	if diffSincePull && len(args) > 0 {
+		return nil, nil, fmt.Errorf("--since-pull compares the current directory with its origin and takes no refs")
+	}
+	if diffAgainst != "" {
+		if len(args) > 0 || diffSincePull {
+			return nil, nil, fmt.Errorf("--against compares the origin tag with another tag and takes no refs or --since-pull")
+		}
+		if strings.Contains(diffAgainst, ":") {
+			return nil, nil, fmt.Errorf("--against takes a tag of the origin workspace, not a ref: %q", diffAgainst)
+		}
+	}
+
 	var refA, refB string
 
 	switch len(args) {
@@ -63,13 +457,33 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		// No args — diff origin vs local (origin is baseline, local shows changes)
 		origin, err := lookupOrigin()
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		if origin == nil {
-			return fmt.Errorf("no origin set; use 'nebi diff <ref>' or push/pull first")
+			return nil, nil, fmt.Errorf("no origin set; use 'nebi diff <ref>' or push/pull first")
+		}
+		if diffSincePull {
+			if origin.OriginVersion > 0 {
+				srcA, err := resolveOriginVersionSource(origin)
+				if err != nil {
+					return nil, nil, err
+				}
+				srcB, err := resolveSource(".", "")
+				if err != nil {
+					return nil, nil, fmt.Errorf("resolving .: %w", err)
+				}
+				return srcA, srcB, nil
+			}
+			if !quietDiff() {
+				fmt.Fprintf(os.Stderr, "Note: origin has no recorded version (pushed or pulled by an older nebi); comparing against the current %s:%s\n",
+					origin.OriginName, origin.OriginTag)
+			}
 		}
 		refA = origin.OriginName + ":" + origin.OriginTag
 		refB = "."
+		if diffAgainst != "" {
+			refB = origin.OriginName + ":" + diffAgainst
+		}
 	case 1:
 		refA = "."
 		refB = args[0]
@@ -80,66 +494,507 @@ func runDiff(cmd *cobra.Command, args []string) error {
 
 	srcA, err := resolveSource(refA, "")
 	if err != nil {
-		return fmt.Errorf("resolving %s: %w", refA, err)
+		return nil, nil, fmt.Errorf("resolving %s: %w", refA, err)
 	}
 
 	srcB, err := resolveSource(refB, "")
 	if err != nil {
-		return fmt.Errorf("resolving %s: %w", refB, err)
+		return nil, nil, fmt.Errorf("resolving %s: %w", refB, err)
 	}
+	return srcA, srcB, nil
+}
+
+// diffJSONOutput is the --json form of a two-source comparison.
+type diffJSONOutput struct {
+	SchemaVersion int               `json:"schema_version"`
+	Source        string            `json:"source"`
+	Target        string            `json:"target"`
+	HasChanges    bool              `json:"has_changes"`
+	Toml          []diff.Change     `json:"toml"`
+	LockChanged   bool              `json:"lock_changed"`
+	Lock          *diff.LockSummary `json:"lock,omitempty"`     // Package changes; absent when pixi.lock is unchanged
+	Security      []securityNote    `json:"security,omitempty"` // Updated packages with advisories (--highlight-security)
+}
+
+func newDiffJSON(res *diffResult) diffJSONOutput {
+	out := diffJSONOutput{
+		SchemaVersion: jsonSchemaVersion,
+		Source:        res.srcA.label,
+		Target:        res.srcB.label,
+		HasChanges:    res.hasChanges(),
+		Toml:          res.toml.Changes,
+		LockChanged:   res.lockChanged,
+		Security:      res.security,
+	}
+	if out.Toml == nil {
+		out.Toml = []diff.Change{}
+	}
+	if res.lockChanged {
+		out.Lock = res.lock
+	}
+	return out
+}
 
-	hasOutput := false
+// computeDiff resolves the sources named by args and compares them.
+func computeDiff(args []string) (*diffResult, error) {
+	env := diffEnv
+	if diffEnvDefault {
+		if env != "" && env != "default" {
+			return nil, fmt.Errorf("--env-default cannot be combined with --env %s", env)
+		}
+		env = "default"
+	}
+
+	srcA, srcB, err := resolveDiffSources(args)
+	if err != nil {
+		return nil, err
+	}
+	if env != "" {
+		if err := resolveSourceEnvironments(env, srcA, srcB); err != nil {
+			return nil, err
+		}
+	}
 
 	// Semantic TOML diff
 	tomlDiff, err := diff.CompareToml([]byte(srcA.toml), []byte(srcB.toml))
 	if err != nil {
-		return fmt.Errorf("comparing pixi.toml: %w", err)
+		return nil, fmt.Errorf("comparing pixi.toml: %w", err)
+	}
+	if len(diffOnly) > 0 {
+		tomlDiff = tomlDiff.OnlySections(diffOnly)
+	}
+
+	lockSummary, lockChanged, err := compareSourceLocks(srcA, srcB)
+	if err != nil {
+		return nil, fmt.Errorf("comparing pixi.lock: %w", err)
+	}
+	if diffIgnoreHash && !lockChanged && srcA.lock != srcB.lock && !quietDiff() {
+		fmt.Fprintln(os.Stderr, "pixi.lock: no package changes (URL/hash/build differences ignored)")
+	}
+
+	res := &diffResult{srcA: srcA, srcB: srcB, toml: tomlDiff, lock: lockSummary, lockChanged: lockChanged}
+	if lockChanged {
+		res.security = diffSecurityNotes(res)
+	}
+	return res, nil
+}
+
+// runDiffStatOnlyExit implements --stat-only-exit: one stat line on stdout
+// and nothing on stderr. The exit code is 0 without differences, 1 with
+// differences (0 when exitOnChange is false), and 2 on any error.
+func runDiffStatOnlyExit(args []string, exitOnChange bool) error {
+	if diffCountOnly || otherDiffOutputFlags() {
+		return &exitCodeError{code: 2, err: fmt.Errorf("--stat-only-exit cannot be combined with other output flags")}
+	}
+
+	res, err := computeDiff(args)
+	if err != nil {
+		return &exitCodeError{code: 2, err: err}
+	}
+	fmt.Print(formatDiffStat(res.toml, res.lock, res.lockChanged))
+	if res.hasChanges() && exitOnChange {
+		return &exitCodeError{code: 1}
+	}
+	return nil
+}
+
+// diffExitOnChange reports whether differences make --stat-only-exit exit
+// non-zero. --exit-zero and --exit-on-change win; otherwise
+// NEBI_DIFF_EXIT_ON_CHANGE decides, and the default is true.
+func diffExitOnChange() (bool, error) {
+	switch {
+	case diffExitZero && diffExitChange:
+		return false, fmt.Errorf("--exit-zero and --exit-on-change cannot be used together")
+	case diffExitZero:
+		return false, nil
+	case diffExitChange:
+		return true, nil
+	}
+	if v := os.Getenv("NEBI_DIFF_EXIT_ON_CHANGE"); v != "" {
+		exit, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid NEBI_DIFF_EXIT_ON_CHANGE %q: expected true or false", v)
+		}
+		return exit, nil
+	}
+	return true, nil
+}
+
+// runDiffCountOnly implements --count-only: the total number of changes on
+// stdout and nothing else. It exits 0 whether or not there are changes.
+func runDiffCountOnly(args []string) error {
+	if otherDiffOutputFlags() {
+		return fmt.Errorf("--count-only cannot be combined with other output flags")
+	}
+	res, err := computeDiff(args)
+	if err != nil {
+		return err
+	}
+	fmt.Println(changeCount(res))
+	return nil
+}
+
+// changeCount totals the pixi.toml changes and pixi.lock package changes of
+// a diff. A lock that changed but could not be parsed counts as one change.
+func changeCount(res *diffResult) int {
+	n := len(res.toml.Changes)
+	switch {
+	case !res.lockChanged:
+	case res.lock == nil || res.lock.PackagesUpdated < 0:
+		n++
+	default:
+		n += res.lock.PackagesAdded + res.lock.PackagesRemoved + res.lock.PackagesUpdated
+	}
+	return n
+}
+
+// otherDiffOutputFlags reports whether any flag is set that conflicts with
+// the single-line modes --stat-only-exit and --count-only.
+func otherDiffOutputFlags() bool {
+	return diffSummaryOnly || diffMarkdown || diffJSON || diffLockStale || diffInstalled || diffLock ||
+		diffContextSec || diffOutputFile != "" || diffBaseline != "" || diffSecurity || bumpGateLevel() != diff.BumpNone
+}
+
+// quietDiff reports whether informational notes on stderr are suppressed.
+func quietDiff() bool {
+	return diffStatOnly || diffCountOnly
+}
+
+// diffHints reports whether "[Use ...]" guidance footers are printed. They
+// are on by default and turned off by --no-hints or any quiet mode.
+func diffHints() bool {
+	return !diffNoHints && !quietDiff()
+}
+
+// resolveSourceEnvironments replaces each source's pixi.toml with the
+// effective dependency set of env, so feature rearrangements that do not
+// change what the environment installs are not reported.
+func resolveSourceEnvironments(env string, sources ...*diffSource) error {
+	for _, src := range sources {
+		resolved, err := diff.ResolveEnvironment([]byte(src.toml), env)
+		if err != nil {
+			return fmt.Errorf("resolving environment %q for %s: %w", env, src.label, err)
+		}
+		src.toml = string(resolved)
+		src.label += " [" + env + "]"
+	}
+	return nil
+}
+
+// resolveOriginVersionSource fetches the server version recorded as the
+// current directory's origin, even if its tag has since moved.
+func resolveOriginVersionSource(origin *store.LocalWorkspace) (*diffSource, error) {
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return nil, err
+	}
+	label := fmt.Sprintf("%s:%s@%d", origin.OriginName, origin.OriginTag, origin.OriginVersion)
+	return fetchServerSource(client, context.Background(), origin.OriginID, int32(origin.OriginVersion), label)
+}
+
+// runLockStaleCheck implements --fail-if-lock-stale: it checks one source
+// for manifest dependencies missing from its lock file and returns an
+// error (non-zero exit) listing them.
+func runLockStaleCheck(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("--fail-if-lock-stale takes at most one ref")
+	}
+	ref := "."
+	if len(args) == 1 {
+		ref = args[0]
+	}
+
+	src, err := resolveSource(ref, "")
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", ref, err)
+	}
+
+	stale, err := diff.CheckLockStale([]byte(src.toml), []byte(src.lock))
+	if err != nil {
+		return fmt.Errorf("checking pixi.lock for %s: %w", src.label, err)
+	}
+	if diffJSON {
+		if stale == nil {
+			stale = []diff.StaleDependency{}
+		}
+		if err := writeDiffReport(func(w io.Writer) error {
+			return writeJSONTo(w, struct {
+				SchemaVersion int                    `json:"schema_version"`
+				Source        string                 `json:"source"`
+				Stale         []diff.StaleDependency `json:"stale"`
+			}{jsonSchemaVersion, src.label, stale})
+		}); err != nil {
+			return err
+		}
+		if len(stale) > 0 {
+			return fmt.Errorf("pixi.lock is stale for %s (%d missing); run 'pixi lock' to update it", src.label, len(stale))
+		}
+		return nil
 	}
+	if len(stale) == 0 {
+		fmt.Fprintf(os.Stderr, "pixi.lock is up to date with pixi.toml (%s).\n", src.label)
+		return nil
+	}
+
+	if err := writeDiffReport(func(w io.Writer) error {
+		for _, d := range stale {
+			if _, err := fmt.Fprintln(w, d); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return fmt.Errorf("pixi.lock is stale for %s (%d missing); run 'pixi lock' to update it", src.label, len(stale))
+}
 
+// runInstalledCheck implements --installed: it compares the packages
+// installed in a workspace's pixi environment with what its pixi.lock pins
+// for the current platform and returns an error (non-zero exit) listing any
+// drift.
+func runInstalledCheck(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("--installed takes at most one directory or workspace")
+	}
+	ref := "."
+	if len(args) == 1 {
+		ref = args[0]
+	}
+	dir, err := resolveInstalledDir(ref)
+	if err != nil {
+		return err
+	}
+
+	env := diffEnv
+	if env == "" {
+		env = "default"
+	}
+	platform := pixi.CurrentPlatform()
+	if platform == "" {
+		return fmt.Errorf("--installed is not supported on %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+
+	lock, err := os.ReadFile(filepath.Join(dir, "pixi.lock"))
+	if err != nil {
+		return fmt.Errorf("reading pixi.lock: %w", err)
+	}
+	prefix := pixi.EnvPrefix(dir, env)
+	if _, err := os.Stat(prefix); err != nil {
+		return fmt.Errorf("environment %q is not installed in %s; run 'pixi install' first", env, dir)
+	}
+	installed, err := pixi.InstalledPackages(prefix)
+	if err != nil {
+		return err
+	}
+
+	summary, err := diff.CompareInstalled(lock, installed, env, platform)
+	if err != nil {
+		return err
+	}
+	drift := summary.PackagesAdded + summary.PackagesRemoved + summary.PackagesUpdated
+	if diffJSON {
+		if err := writeDiffReport(func(w io.Writer) error {
+			return writeJSONTo(w, struct {
+				SchemaVersion int               `json:"schema_version"`
+				Environment   string            `json:"environment"`
+				Platform      string            `json:"platform"`
+				Drift         *diff.LockSummary `json:"drift"`
+			}{jsonSchemaVersion, env, platform, summary})
+		}); err != nil {
+			return err
+		}
+		if drift > 0 {
+			return fmt.Errorf("installed environment %q differs from pixi.lock (%d package(s)); run 'pixi install' to restore it", env, drift)
+		}
+		return nil
+	}
+	if drift == 0 {
+		fmt.Fprintf(os.Stderr, "Installed environment %q matches pixi.lock (%s).\n", env, platform)
+		return nil
+	}
+
+	if err := writeDiffReport(func(w io.Writer) error {
+		_, err := fmt.Fprint(w, formatInstalledDrift(summary))
+		return err
+	}); err != nil {
+		return err
+	}
+	return fmt.Errorf("installed environment %q differs from pixi.lock (%d package(s)); run 'pixi install' to restore it", env, drift)
+}
+
+// resolveInstalledDir maps a --installed argument to a workspace
+// directory: a path, or the name of a tracked workspace.
+func resolveInstalledDir(ref string) (string, error) {
+	if ref == "." || isPath(ref) {
+		return filepath.Abs(ref)
+	}
+	if strings.Contains(ref, ":") {
+		return "", fmt.Errorf("--installed needs a local directory or tracked workspace, not a server ref")
+	}
+
+	s, err := store.New()
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+	workspaces, err := findWorkspacesByNameWithSync(s, ref)
+	if err != nil {
+		return "", err
+	}
+	switch len(workspaces) {
+	case 0:
+		return "", fmt.Errorf("workspace %q is not tracked; pass a directory instead", ref)
+	case 1:
+		return workspaces[0].Path, nil
+	}
+	ws, err := pickWorkspace(workspaces, ref)
+	if err != nil {
+		return "", err
+	}
+	return ws.Path, nil
+}
+
+// formatInstalledDrift renders a diff.CompareInstalled summary.
+func formatInstalledDrift(summary *diff.LockSummary) string {
+	var sb strings.Builder
+	if len(summary.Added) > 0 {
+		sb.WriteString("Installed but not locked:\n")
+		for _, p := range summary.Added {
+			sb.WriteString("  + " + p + "\n")
+		}
+	}
+	if len(summary.Removed) > 0 {
+		sb.WriteString("Locked but not installed:\n")
+		for _, p := range summary.Removed {
+			sb.WriteString("  - " + p + "\n")
+		}
+	}
+	if len(summary.Updated) > 0 {
+		sb.WriteString("Installed version differs (locked -> installed):\n")
+		for _, u := range summary.Updated {
+			fmt.Fprintf(&sb, "  ~ %s %s -> %s\n", u.Name, u.OldVersion, u.NewVersion)
+		}
+	}
+	return sb.String()
+}
+
+// outputDiffText writes the human-readable diff of two sources to w and
+// reports whether any difference was found. When the lock changed but
+// --lock was not given, a short summary footer is printed unless
+// --no-lock-hint is set; --no-hints keeps the summary but drops the "[Use
+// --lock ...]" line. With --context-sections, changed pixi.toml tables
+// are printed in full instead of as changed lines only.
+func outputDiffText(w io.Writer, srcA, srcB *diffSource, tomlDiff *diff.TomlDiff, lockSummary *diff.LockSummary, lockChanged bool) (bool, error) {
 	if tomlDiff.HasChanges() {
-		fmt.Print(diff.FormatUnifiedDiff(tomlDiff, srcA.label, srcB.label))
-		hasOutput = true
+		if diffContextSec {
+			out, err := diff.FormatSectionContext(tomlDiff, []byte(srcA.toml), []byte(srcB.toml), srcA.label, srcB.label)
+			if err != nil {
+				return false, fmt.Errorf("formatting pixi.toml sections: %w", err)
+			}
+			fmt.Fprint(w, out)
+		} else {
+			fmt.Fprint(w, diff.FormatUnifiedDiff(tomlDiff, srcA.label, srcB.label))
+		}
 	}
 
-	// Lock file diff
-	if srcA.lock != srcB.lock {
-		lockSummary, _ := diff.CompareLock([]byte(srcA.lock), []byte(srcB.lock))
+	if lockChanged {
 		if diffLock && lockSummary != nil {
-			fmt.Println()
-			fmt.Print(diff.FormatLockDiffText(lockSummary))
-			hasOutput = true
-		} else {
-			fmt.Println()
-			fmt.Println("@@ pixi.lock (changed) @@")
+			fmt.Fprintln(w)
+			if diffGroupBy == "platform" {
+				fmt.Fprint(w, diff.FormatLockDiffTextByPlatform(lockSummary))
+			} else {
+				fmt.Fprint(w, diff.FormatLockDiffText(lockSummary))
+			}
+		} else if !diffNoLockHint {
+			fmt.Fprintln(w)
+			if diffLockEnv != "" {
+				fmt.Fprintf(w, "@@ pixi.lock [%s] (changed) @@\n", diffLockEnv)
+			} else {
+				fmt.Fprintln(w, "@@ pixi.lock (changed) @@")
+			}
 			if lockSummary != nil {
 				total := lockSummary.PackagesAdded + lockSummary.PackagesRemoved + lockSummary.PackagesUpdated
 				if total > 0 {
-					fmt.Printf("  %d packages changed", total)
+					fmt.Fprintf(w, "  %d packages changed", total)
 					if lockSummary.PackagesAdded > 0 {
-						fmt.Printf(", %d added", lockSummary.PackagesAdded)
+						fmt.Fprintf(w, ", %d added", lockSummary.PackagesAdded)
 					}
 					if lockSummary.PackagesRemoved > 0 {
-						fmt.Printf(", %d removed", lockSummary.PackagesRemoved)
+						fmt.Fprintf(w, ", %d removed", lockSummary.PackagesRemoved)
 					}
 					if lockSummary.PackagesUpdated > 0 {
-						fmt.Printf(", %d updated", lockSummary.PackagesUpdated)
+						fmt.Fprintf(w, ", %d updated", lockSummary.PackagesUpdated)
 					}
-					fmt.Println()
+					fmt.Fprintln(w)
 				}
 			}
-			fmt.Println("[Use --lock for full lock file details]")
-			hasOutput = true
+			if diffHints() {
+				fmt.Fprintln(w, "[Use --lock for full lock file details]")
+			}
 		}
 	}
 
-	if !hasOutput {
-		fmt.Fprintln(os.Stderr, "No differences.")
+	return tomlDiff.HasChanges() || lockChanged, nil
+}
+
+// compareSourceLocks diffs the lock files of two sources. With --lock-env the
+// comparison is scoped to that environment, and the lock only counts as
+// changed when the environment's packages differ. --ignore-lock-hash-only
+// applies the same rule to the whole file, so a re-solve that only changes
+// URLs, hashes or build strings is not reported. The per-platform breakdown
+// is only computed for --group-by platform.
+func compareSourceLocks(a, b *diffSource) (*diff.LockSummary, bool, error) {
+	if a.lock == b.lock {
+		return nil, false, nil
+	}
+	opts := diff.LockOptions{Environment: diffLockEnv, GroupByPlatform: diffGroupBy == "platform"}
+	if diffLockEnv == "" && !diffIgnoreHash {
+		summary, _ := diff.CompareLockWithOptions([]byte(a.lock), []byte(b.lock), opts)
+		return summary, true, nil
+	}
+
+	summary, err := diff.CompareLockWithOptions([]byte(a.lock), []byte(b.lock), opts)
+	if err != nil {
+		return nil, false, err
+	}
+	changed := summary.PackagesUpdated < 0 || summary.PackagesAdded+summary.PackagesRemoved+summary.PackagesUpdated > 0
+	return summary, changed, nil
+}
+
+// formatDiffSummary renders the label-oriented counts used by --summary-only:
+// one "toml:" line and one "lock:" line. A lock file that changed but could
+// not be parsed into packages is reported as "lock: changed".
+func formatDiffSummary(tomlDiff *diff.TomlDiff, lockSummary *diff.LockSummary, lockChanged bool) string {
+	return fmt.Sprintf("toml: %d changed\nlock: %s\n", len(tomlDiff.Changes), lockStat(lockSummary, lockChanged))
+}
+
+// formatDiffStat renders the --summary-only counts on a single line, as
+// printed by --stat-only-exit.
+func formatDiffStat(tomlDiff *diff.TomlDiff, lockSummary *diff.LockSummary, lockChanged bool) string {
+	return fmt.Sprintf("toml: %d changed, lock: %s\n", len(tomlDiff.Changes), lockStat(lockSummary, lockChanged))
+}
+
+// lockStat renders lock package counts as "+added -removed ~updated", or
+// "changed" for a lock file that could not be parsed into packages.
+func lockStat(lockSummary *diff.LockSummary, lockChanged bool) string {
+	switch {
+	case lockChanged && (lockSummary == nil || lockSummary.PackagesUpdated < 0):
+		return "changed"
+	case lockSummary != nil:
+		return fmt.Sprintf("+%d -%d ~%d", lockSummary.PackagesAdded, lockSummary.PackagesRemoved, lockSummary.PackagesUpdated)
+	default:
+		return "+0 -0 ~0"
 	}
-	return nil
 }
 
-// resolveSource resolves a ref (directory, workspace name, or workspace:tag) into a diffSource.
+// resolveSource resolves a ref (directory, workspace name, workspace:tag, or
+// "-" for stdin) into a diffSource.
 func resolveSource(ref, defaultLabel string) (*diffSource, error) {
+	if ref == stdinRef {
+		return resolveStdinSource()
+	}
+
 	// 1. Local directory path (must contain a slash, e.g. ./foo, /tmp/foo, foo/bar)
 	if isPath(ref) {
 		return resolveLocalSource(ref, defaultLabel)
@@ -221,16 +1076,32 @@ func resolveServerSource(ref string) (*diffSource, error) {
 		return nil, err
 	}
 
-	toml, err := client.GetVersionPixiToml(ctx, ws.ID, versionNumber)
+	label := wsName
+	if tag != "" {
+		label = wsName + ":" + tag
+	}
+	return fetchServerSource(client, ctx, ws.ID, versionNumber, label)
+}
+
+// fetchServerSource downloads the pixi.toml and pixi.lock of one server
+// version, reusing content cached by a recent diff unless --refresh is set.
+func fetchServerSource(client *cliclient.Client, ctx context.Context, wsID string, versionNumber int32, label string) (*diffSource, error) {
+	if !diffRefresh {
+		if cached, ok := loadCachedVersion(client.BaseURL(), wsID, versionNumber); ok {
+			return &diffSource{label: label, toml: cached.Toml, lock: cached.Lock}, nil
+		}
+	}
+
+	toml, err := client.GetVersionPixiToml(ctx, wsID, versionNumber)
 	if err != nil {
 		return nil, fmt.Errorf("fetching pixi.toml: %w", err)
 	}
 
-	lock, _ := client.GetVersionPixiLock(ctx, ws.ID, versionNumber)
-
-	label := wsName
-	if tag != "" {
-		label = wsName + ":" + tag
+	// A failed lock fetch is diffed as an empty lock but not cached, so the
+	// next run fetches it again.
+	lock, err := client.GetVersionPixiLock(ctx, wsID, versionNumber)
+	if err == nil {
+		saveCachedVersion(client.BaseURL(), wsID, versionNumber, toml, lock)
 	}
 
 	return &diffSource{
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// loadDiffBaseline reads a diff previously saved with 'nebi diff --json'.
+func loadDiffBaseline(path string) (*diffJSONOutput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading baseline: %w", err)
+	}
+	var baseline diffJSONOutput
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("parsing baseline %s: %w", path, err)
+	}
+	if baseline.SchemaVersion != jsonSchemaVersion {
+		return nil, fmt.Errorf("baseline %s has schema_version %d, expected %d; save it again with 'nebi diff --json'",
+			path, baseline.SchemaVersion, jsonSchemaVersion)
+	}
+	return &baseline, nil
+}
+
+// diffEntries flattens a diff into one line per change, ignoring the
+// source and target labels so that a baseline stays valid when the
+// compared paths or tags differ.
+func diffEntries(d *diffJSONOutput) []string {
+	var entries []string
+	for _, c := range d.Toml {
+		entry := fmt.Sprintf("pixi.toml [%s] %s %s", c.Section, c.Key, c.Type)
+		switch {
+		case c.OldValue != "" && c.NewValue != "":
+			entry += fmt.Sprintf(": %s -> %s", c.OldValue, c.NewValue)
+		case c.NewValue != "":
+			entry += ": " + c.NewValue
+		case c.OldValue != "":
+			entry += ": " + c.OldValue
+		}
+		entries = append(entries, entry)
+	}
+	if !d.LockChanged {
+		return entries
+	}
+	if d.Lock == nil || d.Lock.PackagesUpdated < 0 {
+		return append(entries, "pixi.lock changed")
+	}
+	for _, p := range d.Lock.Added {
+		entries = append(entries, "pixi.lock added "+p)
+	}
+	for _, p := range d.Lock.Removed {
+		entries = append(entries, "pixi.lock removed "+p)
+	}
+	for _, u := range d.Lock.Updated {
+		entries = append(entries, fmt.Sprintf("pixi.lock updated %s %s -> %s", u.Name, u.OldVersion, u.NewVersion))
+	}
+	return entries
+}
+
+// baselineDelta returns the changes that differ between a saved diff and
+// the current one: "+ " lines are only in the current diff, "- " lines
+// only in the baseline.
+func baselineDelta(baseline, current *diffJSONOutput) []string {
+	inBaseline := make(map[string]bool)
+	for _, e := range diffEntries(baseline) {
+		inBaseline[e] = true
+	}
+	var delta []string
+	inCurrent := make(map[string]bool)
+	for _, e := range diffEntries(current) {
+		inCurrent[e] = true
+		if !inBaseline[e] {
+			delta = append(delta, "+ "+e)
+		}
+	}
+	for _, e := range diffEntries(baseline) {
+		if !inCurrent[e] {
+			delta = append(delta, "- "+e)
+		}
+	}
+	return delta
+}
+
+// runBaselineCheck implements --baseline-file: it compares the current diff
+// with a saved one and fails, listing the delta, when they differ.
+func runBaselineCheck(res *diffResult) error {
+	baseline, err := loadDiffBaseline(diffBaseline)
+	if err != nil {
+		return err
+	}
+	current := newDiffJSON(res)
+	delta := baselineDelta(baseline, &current)
+	if len(delta) == 0 {
+		fmt.Fprintf(os.Stderr, "Diff matches baseline %s.\n", diffBaseline)
+		return nil
+	}
+
+	if err := writeDiffReport(func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, strings.Join(delta, "\n"))
+		return err
+	}); err != nil {
+		return err
+	}
+	return fmt.Errorf("diff differs from baseline %s (%d change(s)); if intended, save the new baseline with --json --output %s",
+		diffBaseline, len(delta), diffBaseline)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nebari-dev/nebi/internal/advisory"
+	"github.com/nebari-dev/nebi/internal/diff"
+	"github.com/nebari-dev/nebi/internal/store"
+)
+
+// advisoryCacheTTL is how long advisory lookups by --highlight-security are
+// reused. Advisories are published continuously, so entries are kept for a
+// short time only.
+const advisoryCacheTTL = time.Hour
+
+// securityNote flags an updated pixi.lock package with known advisories.
+type securityNote struct {
+	Name       string   `json:"name"`
+	OldVersion string   `json:"old"`
+	NewVersion string   `json:"new"`
+	Fixed      []string `json:"fixed,omitempty"`    // Advisories affecting the old version but not the new one
+	Affected   []string `json:"affected,omitempty"` // Advisories still affecting the new version
+}
+
+// describe summarizes the note's advisories, e.g.
+// "fixes GHSA-1; still affected by GHSA-2".
+func (n securityNote) describe() string {
+	var parts []string
+	if len(n.Fixed) > 0 {
+		parts = append(parts, "fixes "+strings.Join(n.Fixed, ", "))
+	}
+	if len(n.Affected) > 0 {
+		parts = append(parts, "still affected by "+strings.Join(n.Affected, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// cachedAdvisories is one package version's advisory IDs as stored in the
+// advisory cache.
+type cachedAdvisories struct {
+	IDs       []string  `json:"ids"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// advisoryURL returns the advisory source: NEBI_ADVISORY_URL, or the public
+// OSV API.
+func advisoryURL() string {
+	if u := os.Getenv("NEBI_ADVISORY_URL"); u != "" {
+		return u
+	}
+	return advisory.DefaultURL
+}
+
+// advisoryCacheDir returns the directory holding cached advisory lookups.
+func advisoryCacheDir() (string, error) {
+	dataDir, err := store.DefaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "cache", "advisories"), nil
+}
+
+// advisoryCacheKey names the cache entry for one package version looked up
+// at one advisory source.
+func advisoryCacheKey(sourceURL string, p advisory.Package) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%s\n%s", sourceURL, p.Ecosystem, p.Name, p.Version)))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// lookupAdvisories returns the advisory IDs affecting each package, from
+// the cache where possible (unless --refresh is set) and from the advisory
+// source otherwise.
+func lookupAdvisories(client *advisory.Client, pkgs []advisory.Package) ([][]string, error) {
+	dir, dirErr := advisoryCacheDir()
+	results := make([][]string, len(pkgs))
+	var missing []int
+	for i, p := range pkgs {
+		if dirErr == nil && !diffRefresh {
+			data, err := os.ReadFile(filepath.Join(dir, advisoryCacheKey(client.BaseURL(), p)))
+			var entry cachedAdvisories
+			if err == nil && json.Unmarshal(data, &entry) == nil && time.Since(entry.FetchedAt) <= advisoryCacheTTL {
+				results[i] = entry.IDs
+				continue
+			}
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	query := make([]advisory.Package, len(missing))
+	for j, i := range missing {
+		query[j] = pkgs[i]
+	}
+	ids, err := client.QueryBatch(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range missing {
+		results[i] = ids[j]
+		if dirErr != nil {
+			continue
+		}
+		if data, err := json.Marshal(cachedAdvisories{IDs: ids[j], FetchedAt: time.Now()}); err == nil {
+			writeCacheFile(dir, advisoryCacheKey(client.BaseURL(), pkgs[i]), data, advisoryCacheTTL)
+		}
+	}
+	return results, nil
+}
+
+// highlightSecurity looks up the old and new version of every updated
+// pixi.lock package and returns a note for each one with advisories.
+func highlightSecurity(lock *diff.LockSummary) ([]securityNote, error) {
+	if lock == nil || len(lock.Updated) == 0 {
+		return nil, nil
+	}
+
+	pkgs := make([]advisory.Package, 0, 2*len(lock.Updated))
+	for _, u := range lock.Updated {
+		name := strings.TrimSuffix(u.Name, " (pypi)")
+		pkgs = append(pkgs,
+			advisory.Package{Ecosystem: advisory.EcosystemPyPI, Name: name, Version: u.OldVersion},
+			advisory.Package{Ecosystem: advisory.EcosystemPyPI, Name: name, Version: u.NewVersion},
+		)
+	}
+	ids, err := lookupAdvisories(advisory.NewClient(advisoryURL()), pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []securityNote
+	for i, u := range lock.Updated {
+		oldIDs, newIDs := ids[2*i], ids[2*i+1]
+		stillAffected := make(map[string]bool, len(newIDs))
+		for _, id := range newIDs {
+			stillAffected[id] = true
+		}
+		var fixed []string
+		for _, id := range oldIDs {
+			if !stillAffected[id] {
+				fixed = append(fixed, id)
+			}
+		}
+		if len(fixed) == 0 && len(newIDs) == 0 {
+			continue
+		}
+		notes = append(notes, securityNote{
+			Name:       u.Name,
+			OldVersion: u.OldVersion,
+			NewVersion: u.NewVersion,
+			Fixed:      fixed,
+			Affected:   newIDs,
+		})
+	}
+	return notes, nil
+}
+
+// diffSecurityNotes implements --highlight-security. An unreachable
+// advisory source is reported on stderr and the diff goes on without
+// highlights.
+func diffSecurityNotes(res *diffResult) []securityNote {
+	if !diffSecurity {
+		return nil
+	}
+	notes, err := highlightSecurity(res.lock)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not query security advisories at %s; continuing without highlights: %v\n", advisoryURL(), err)
+		return nil
+	}
+	return notes
+}
+
+// formatSecurityNotes renders --highlight-security notes as a diff section,
+// one line per updated package with advisories.
+func formatSecurityNotes(notes []securityNote) string {
+	var sb strings.Builder
+	sb.WriteString("\n@@ security advisories @@\n")
+	for _, n := range notes {
+		fmt.Fprintf(&sb, "!%s %s -> %s: %s\n", n.Name, n.OldVersion, n.NewVersion, n.describe())
+	}
+	return sb.String()
+}
+
+// formatSecurityNotesMarkdown renders --highlight-security notes as a
+// Markdown section to follow diff.FormatMarkdown.
+func formatSecurityNotesMarkdown(notes []securityNote) string {
+	var sb strings.Builder
+	sb.WriteString("#### Security advisories\n\n")
+	for _, n := range notes {
+		fmt.Fprintf(&sb, "- `%s` %s → %s: %s\n", n.Name, n.OldVersion, n.NewVersion, n.describe())
+	}
+	return sb.String()
+}
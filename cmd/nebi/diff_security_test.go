@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/diff"
+)
+
+func TestHighlightSecurity(t *testing.T) {
+	t.Setenv("NEBI_DATA_DIR", t.TempDir())
+	affected := map[string][]string{
+		"requests 2.0": {"GHSA-old", "GHSA-both"},
+		"requests 2.1": {"GHSA-both"},
+		"urllib3 1.0":  {"GHSA-fixed"},
+	}
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req struct {
+			Queries []struct {
+				Package struct{ Name string } `json:"package"`
+				Version string                `json:"version"`
+			} `json:"queries"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		type vuln struct {
+			ID string `json:"id"`
+		}
+		results := make([]map[string][]vuln, len(req.Queries))
+		for i, q := range req.Queries {
+			results[i] = map[string][]vuln{}
+			for _, id := range affected[q.Package.Name+" "+q.Version] {
+				results[i]["vulns"] = append(results[i]["vulns"], vuln{id})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	t.Setenv("NEBI_ADVISORY_URL", srv.URL)
+
+	lock := &diff.LockSummary{Updated: []diff.PackageUpdate{
+		{Name: "requests", OldVersion: "2.0", NewVersion: "2.1"},
+		{Name: "urllib3", OldVersion: "1.0", NewVersion: "2.0"},
+		{Name: "numpy", OldVersion: "1.0", NewVersion: "2.0"},
+	}}
+	want := []securityNote{
+		{Name: "requests", OldVersion: "2.0", NewVersion: "2.1", Fixed: []string{"GHSA-old"}, Affected: []string{"GHSA-both"}},
+		{Name: "urllib3", OldVersion: "1.0", NewVersion: "2.0", Fixed: []string{"GHSA-fixed"}},
+	}
+
+	notes, err := highlightSecurity(lock)
+	if err != nil {
+		t.Fatalf("highlight: %v", err)
+	}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("got %+v, want %+v", notes, want)
+	}
+	if got := notes[0].describe(); got != "fixes GHSA-old; still affected by GHSA-both" {
+		t.Errorf("describe = %q", got)
+	}
+
+	// A second run is answered from the cache.
+	srv.Close()
+	notes, err = highlightSecurity(lock)
+	if err != nil || !reflect.DeepEqual(notes, want) || calls != 1 {
+		t.Errorf("expected cached result without a second query, got %+v err=%v calls=%d", notes, err, calls)
+	}
+
+	// An unreachable source is an error for the caller to degrade on.
+	lock.Updated[2].NewVersion = "3.0"
+	if _, err := highlightSecurity(lock); err == nil {
+		t.Error("expected an error for an unreachable advisory source")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinRef is the ref that reads one side of a diff from stdin.
+const stdinRef = "-"
+
+// stdinLockSeparator is the line that splits a framed stdin payload into
+// its pixi.toml and pixi.lock parts. It is not valid TOML, so it cannot
+// appear in a manifest outside a multi-line string.
+const stdinLockSeparator = "--- pixi.lock ---"
+
+// parseFramedStdin splits a stdin payload into pixi.toml and pixi.lock
+// content. Without a separator line the whole payload is the pixi.toml and
+// the lock is empty.
+func parseFramedStdin(data string) (toml, lock string) {
+	rest := data
+	for offset := 0; rest != ""; {
+		line, after, found := strings.Cut(rest, "\n")
+		if strings.TrimRight(line, "\r") == stdinLockSeparator {
+			return data[:offset], after
+		}
+		if !found {
+			break
+		}
+		offset += len(line) + 1
+		rest = after
+	}
+	return data, ""
+}
+
+// resolveStdinSource reads a framed pixi.toml (and optional pixi.lock)
+// payload from stdin.
+func resolveStdinSource() (*diffSource, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("reading stdin: %w", err)
+	}
+	toml, lock := parseFramedStdin(string(data))
+	return &diffSource{label: "stdin", toml: toml, lock: lock}, nil
+}
+
+// resolveNamedTomlSources resolves the two sides of a diff from
+// --left-toml and --right-toml. Each names a pixi.toml file, or "-" for
+// stdin; at most one side can come from stdin. Neither side has a lock file.
+func resolveNamedTomlSources(args []string) (*diffSource, *diffSource, error) {
+	if diffLeftToml == "" || diffRightToml == "" {
+		return nil, nil, fmt.Errorf("--left-toml and --right-toml must be given together")
+	}
+	if len(args) > 0 || diffSincePull || diffAgainst != "" {
+		return nil, nil, fmt.Errorf("--left-toml and --right-toml name both sides and take no refs, --since-pull or --against")
+	}
+	if diffLeftToml == stdinRef && diffRightToml == stdinRef {
+		return nil, nil, fmt.Errorf("only one of --left-toml and --right-toml can read stdin; pass the other as a file")
+	}
+
+	srcA, err := readTomlInput(diffLeftToml)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading --left-toml: %w", err)
+	}
+	srcB, err := readTomlInput(diffRightToml)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading --right-toml: %w", err)
+	}
+	return srcA, srcB, nil
+}
+
+// readTomlInput reads a bare pixi.toml from path, or from stdin for "-".
+func readTomlInput(path string) (*diffSource, error) {
+	if path == stdinRef {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return &diffSource{label: "stdin", toml: string(data)}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &diffSource{label: path, toml: string(data)}, nil
+}
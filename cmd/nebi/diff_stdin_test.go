@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestParseFramedStdin(t *testing.T) {
+	tests := []struct {
+		name, in, toml, lock string
+	}{
+		{"toml only", "[project]\nname = \"a\"\n", "[project]\nname = \"a\"\n", ""},
+		{"toml and lock", "[project]\n--- pixi.lock ---\nversion: 6\n", "[project]\n", "version: 6\n"},
+		{"crlf separator", "[project]\r\n--- pixi.lock ---\r\nversion: 6\r\n", "[project]\r\n", "version: 6\r\n"},
+		{"separator first", "--- pixi.lock ---\nversion: 6\n", "", "version: 6\n"},
+		{"separator not on its own line", "# --- pixi.lock ---\n", "# --- pixi.lock ---\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toml, lock := parseFramedStdin(tt.in)
+			if toml != tt.toml || lock != tt.lock {
+				t.Errorf("got (%q, %q), want (%q, %q)", toml, lock, tt.toml, tt.lock)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/diff"
+)
+
+func TestFormatDiffSummary(t *testing.T) {
+	tomlDiff := &diff.TomlDiff{Changes: []diff.Change{
+		{Section: "dependencies", Key: "numpy", Type: diff.ChangeAdded},
+		{Section: "dependencies", Key: "scipy", Type: diff.ChangeRemoved},
+		{Section: "dependencies", Key: "python", Type: diff.ChangeModified},
+	}}
+
+	tests := []struct {
+		name        string
+		tomlDiff    *diff.TomlDiff
+		lock        *diff.LockSummary
+		lockChanged bool
+		want        string
+	}{
+		{
+			name:     "no changes",
+			tomlDiff: &diff.TomlDiff{},
+			want:     "toml: 0 changed\nlock: +0 -0 ~0\n",
+		},
+		{
+			name:        "toml and lock changes",
+			tomlDiff:    tomlDiff,
+			lock:        &diff.LockSummary{PackagesAdded: 5, PackagesRemoved: 2, PackagesUpdated: 7},
+			lockChanged: true,
+			want:        "toml: 3 changed\nlock: +5 -2 ~7\n",
+		},
+		{
+			name:        "unparseable lock",
+			tomlDiff:    &diff.TomlDiff{},
+			lock:        &diff.LockSummary{PackagesUpdated: -1},
+			lockChanged: true,
+			want:        "toml: 0 changed\nlock: changed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatDiffSummary(tt.tomlDiff, tt.lock, tt.lockChanged)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDiffStat(t *testing.T) {
+	tomlDiff := &diff.TomlDiff{Changes: []diff.Change{
+		{Section: "dependencies", Key: "numpy", Type: diff.ChangeAdded},
+	}}
+	lock := &diff.LockSummary{PackagesAdded: 1, PackagesUpdated: 2}
+
+	got := formatDiffStat(tomlDiff, lock, true)
+	if want := "toml: 1 changed, lock: +1 -0 ~2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestOutputDiffTextNoLockHint(t *testing.T) {
+	srcA := &diffSource{label: "a"}
+	srcB := &diffSource{label: "b"}
+	lock := &diff.LockSummary{PackagesAdded: 1}
+
+	t.Cleanup(func() { diffNoLockHint = false })
+
+	var buf bytes.Buffer
+	changed, err := outputDiffText(&buf, srcA, srcB, &diff.TomlDiff{}, lock, true)
+	if err != nil || !changed {
+		t.Fatalf("expected lock change to be reported, got changed=%v err=%v", changed, err)
+	}
+	if !strings.Contains(buf.String(), "@@ pixi.lock (changed) @@") {
+		t.Errorf("expected lock footer, got %q", buf.String())
+	}
+
+	diffNoLockHint = true
+	buf.Reset()
+	changed, err = outputDiffText(&buf, srcA, srcB, &diff.TomlDiff{}, lock, true)
+	if err != nil || !changed {
+		t.Errorf("expected lock change to be reported with --no-lock-hint, got changed=%v err=%v", changed, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output with --no-lock-hint, got %q", buf.String())
+	}
+}
+
+func TestCompareSourceLocksIgnoreHashOnly(t *testing.T) {
+	srcA := &diffSource{lock: `version: 6
+packages:
+- conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+  sha256: abc123
+`}
+	srcB := &diffSource{lock: `version: 6
+packages:
+- conda: https://prefix.dev/conda-forge/linux-64/numpy-1.24.0-py311h5678_1.conda
+  sha256: def456
+`}
+
+	if _, changed, err := compareSourceLocks(srcA, srcB); err != nil || !changed {
+		t.Fatalf("expected byte-level lock change, got changed=%v err=%v", changed, err)
+	}
+
+	diffIgnoreHash = true
+	t.Cleanup(func() { diffIgnoreHash = false })
+
+	summary, changed, err := compareSourceLocks(srcA, srcB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Errorf("expected URL/hash/build-only change to be ignored, got %+v", summary)
+	}
+
+	srcB.lock = strings.Replace(srcB.lock, "numpy-1.24.0", "numpy-1.26.0", 1)
+	summary, changed, err = compareSourceLocks(srcA, srcB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || summary.PackagesUpdated != 1 {
+		t.Errorf("expected version change to be reported, got changed=%v summary=%+v", changed, summary)
+	}
+}
+
+func TestCompareSourceLocksGroupByPlatform(t *testing.T) {
+	srcA := &diffSource{lock: `version: 6
+environments:
+  default:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+packages:
+- conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+`}
+	srcB := &diffSource{lock: strings.ReplaceAll(srcA.lock, "numpy-1.24.0", "numpy-1.26.0")}
+
+	summary, _, err := compareSourceLocks(srcA, srcB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(diffJSONOutput{Lock: summary})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"platforms"`) {
+		t.Errorf("default JSON should stay flattened, got %s", data)
+	}
+
+	diffGroupBy = "platform"
+	t.Cleanup(func() { diffGroupBy = "" })
+
+	summary, _, err = compareSourceLocks(srcA, srcB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linux := summary.Platforms["linux-64"]; linux == nil || linux.PackagesUpdated != 1 {
+		t.Errorf("expected linux-64 breakdown with --group-by platform, got %+v", summary.Platforms)
+	}
+}
+
+func TestBaselineDelta(t *testing.T) {
+	baseline := &diffJSONOutput{
+		Source: "a", Target: "b",
+		Toml: []diff.Change{
+			{Section: "dependencies", Key: "numpy", Type: diff.ChangeAdded, NewValue: `"*"`},
+		},
+		LockChanged: true,
+		Lock: &diff.LockSummary{
+			Added:   []string{"numpy 1.26.4"},
+			Updated: []diff.PackageUpdate{{Name: "python", OldVersion: "3.11.8", NewVersion: "3.11.9"}},
+		},
+	}
+
+	// Only the labels differ: no delta.
+	same := *baseline
+	same.Source, same.Target = "./x", "ws:v2"
+	if delta := baselineDelta(baseline, &same); len(delta) != 0 {
+		t.Errorf("expected no delta for relabelled diff, got %v", delta)
+	}
+
+	current := &diffJSONOutput{
+		Toml: []diff.Change{
+			{Section: "dependencies", Key: "numpy", Type: diff.ChangeAdded, NewValue: `"*"`},
+			{Section: "dependencies", Key: "scipy", Type: diff.ChangeAdded, NewValue: `"*"`},
+		},
+		LockChanged: true,
+		Lock: &diff.LockSummary{
+			Added: []string{"numpy 1.26.4"},
+		},
+	}
+	want := []string{
+		`+ pixi.toml [dependencies] scipy added: "*"`,
+		"- pixi.lock updated python 3.11.8 -> 3.11.9",
+	}
+	if got := baselineDelta(baseline, current); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("delta = %q, want %q", got, want)
+	}
+}
+
+func TestChangeCount(t *testing.T) {
+	toml := &diff.TomlDiff{Changes: []diff.Change{
+		{Section: "dependencies", Key: "numpy", Type: diff.ChangeAdded},
+		{Section: "dependencies", Key: "scipy", Type: diff.ChangeRemoved},
+	}}
+
+	tests := []struct {
+		name string
+		res  diffResult
+		want int
+	}{
+		{"no changes", diffResult{toml: &diff.TomlDiff{}}, 0},
+		{"toml only", diffResult{toml: toml}, 2},
+		{"toml and lock", diffResult{toml: toml, lockChanged: true, lock: &diff.LockSummary{PackagesAdded: 3, PackagesRemoved: 1, PackagesUpdated: 2}}, 8},
+		{"unparseable lock", diffResult{toml: &diff.TomlDiff{}, lockChanged: true, lock: &diff.LockSummary{PackagesUpdated: -1}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changeCount(&tt.res); got != tt.want {
+				t.Errorf("changeCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBumpGateUnparseableLock(t *testing.T) {
+	res := &diffResult{toml: &diff.TomlDiff{}, lockChanged: true, lock: &diff.LockSummary{PackagesUpdated: -1}}
+
+	t.Cleanup(func() { diffStrict = false })
+
+	diffStrict = false
+	if err := checkBumpGate(res, diff.BumpMajor); err != nil {
+		t.Errorf("without --strict an unparseable lock should only warn, got %v", err)
+	}
+
+	diffStrict = true
+	err := checkBumpGate(res, diff.BumpMajor)
+	if err == nil || !strings.Contains(err.Error(), "could not be parsed") {
+		t.Errorf("with --strict an unparseable lock should fail the gate, got %v", err)
+	}
+}
+
+func TestOutputDiffTextNoHints(t *testing.T) {
+	srcA := &diffSource{label: "a"}
+	srcB := &diffSource{label: "b"}
+	lock := &diff.LockSummary{PackagesAdded: 1}
+
+	t.Cleanup(func() { diffNoHints = false })
+
+	var buf bytes.Buffer
+	if _, err := outputDiffText(&buf, srcA, srcB, &diff.TomlDiff{}, lock, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "[Use --lock") {
+		t.Errorf("expected hint by default, got %q", buf.String())
+	}
+
+	diffNoHints = true
+	buf.Reset()
+	if _, err := outputDiffText(&buf, srcA, srcB, &diff.TomlDiff{}, lock, true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "[Use") {
+		t.Errorf("expected no hint with --no-hints, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "@@ pixi.lock (changed) @@") {
+		t.Errorf("expected lock summary to remain with --no-hints, got %q", buf.String())
+	}
+}
+
+func TestDiffExitOnChange(t *testing.T) {
+	t.Cleanup(func() { diffExitZero, diffExitChange = false, false })
+
+	tests := []struct {
+		env              string
+		exitZero, change bool
+		want             bool
+		wantErr          bool
+	}{
+		{want: true},
+		{env: "false", want: false},
+		{env: "true", want: true},
+		{env: "false", change: true, want: true},
+		{env: "true", exitZero: true, want: false},
+		{exitZero: true, change: true, wantErr: true},
+		{env: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("NEBI_DIFF_EXIT_ON_CHANGE", tt.env)
+		diffExitZero, diffExitChange = tt.exitZero, tt.change
+		got, err := diffExitOnChange()
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("env=%q exit-zero=%v exit-on-change=%v: got %v, %v; want %v (error %v)",
+				tt.env, tt.exitZero, tt.change, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
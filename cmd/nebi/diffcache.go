@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nebari-dev/nebi/internal/contenthash"
+	"github.com/nebari-dev/nebi/internal/store"
+)
+
+// diffCacheTTL is how long server version content fetched by nebi diff is
+// reused. Versions are immutable and tags are re-resolved on every run, so
+// a moved tag points at a different cache entry; the TTL only keeps the
+// cache directory from growing without bound.
+const diffCacheTTL = 10 * time.Minute
+
+// cachedVersion is one server version's spec files as stored in the diff cache.
+type cachedVersion struct {
+	Toml      string    `json:"pixi_toml"`
+	Lock      string    `json:"pixi_lock"`
+	Digest    string    `json:"digest"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// diffCacheDir returns the directory holding cached version content.
+func diffCacheDir() (string, error) {
+	dataDir, err := store.DefaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "cache", "diff"), nil
+}
+
+// diffCacheKey names the cache entry for one version on one server.
+func diffCacheKey(serverURL, wsID string, versionNumber int32) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%d", serverURL, wsID, versionNumber)))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// loadCachedVersion returns the cached content for a version if present,
+// younger than diffCacheTTL, and intact.
+func loadCachedVersion(serverURL, wsID string, versionNumber int32) (*cachedVersion, bool) {
+	dir, err := diffCacheDir()
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, diffCacheKey(serverURL, wsID, versionNumber)))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cachedVersion
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if time.Since(entry.FetchedAt) > diffCacheTTL || entry.Digest != contenthash.Hash(entry.Toml, entry.Lock) {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// saveCachedVersion stores fetched content and prunes expired entries.
+// Failures are ignored: the cache is only an optimization.
+func saveCachedVersion(serverURL, wsID string, versionNumber int32, toml, lock string) {
+	dir, err := diffCacheDir()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cachedVersion{
+		Toml:      toml,
+		Lock:      lock,
+		Digest:    contenthash.Hash(toml, lock),
+		FetchedAt: time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	writeCacheFile(dir, diffCacheKey(serverURL, wsID, versionNumber), data, diffCacheTTL)
+}
+
+// writeCacheFile stores one cache entry in dir after pruning entries older
+// than ttl. Failures are ignored: the caches are only an optimization.
+func writeCacheFile(dir, name string, data []byte, ttl time.Duration) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+	pruneCacheDir(dir, ttl)
+
+	// Write to a temp file and rename so a concurrent diff never reads a
+	// partial entry.
+	tmp, err := os.CreateTemp(dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// pruneCacheDir removes entries older than ttl.
+func pruneCacheDir(dir string, ttl time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || e.IsDir() {
+			continue
+		}
+		if time.Since(info.ModTime()) > ttl {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
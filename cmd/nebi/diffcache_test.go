@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nebari-dev/nebi/internal/cliclient"
+)
+
+func TestDiffCache(t *testing.T) {
+	t.Setenv("NEBI_DATA_DIR", t.TempDir())
+	const server = "http://nebi.example/api/v1"
+
+	if _, ok := loadCachedVersion(server, "ws-1", 3); ok {
+		t.Fatal("expected empty cache")
+	}
+
+	saveCachedVersion(server, "ws-1", 3, "[workspace]\n", "version: 6\n")
+	got, ok := loadCachedVersion(server, "ws-1", 3)
+	if !ok || got.Toml != "[workspace]\n" || got.Lock != "version: 6\n" {
+		t.Fatalf("expected cached content, got %+v ok=%v", got, ok)
+	}
+
+	// A moved tag resolves to another version number, which is a different entry.
+	if _, ok := loadCachedVersion(server, "ws-1", 4); ok {
+		t.Error("expected no entry for a different version")
+	}
+	if _, ok := loadCachedVersion("http://other.example/api/v1", "ws-1", 3); ok {
+		t.Error("expected no entry for a different server")
+	}
+
+	// Entries whose content no longer matches the digest are ignored.
+	dir, _ := diffCacheDir()
+	path := filepath.Join(dir, diffCacheKey(server, "ws-1", 3))
+	data, _ := os.ReadFile(path)
+	corrupted := []byte(string(data[:len(data)-2]) + "x}")
+	if err := os.WriteFile(path, corrupted, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadCachedVersion(server, "ws-1", 3); ok {
+		t.Error("expected corrupted entry to be ignored")
+	}
+
+	// Expired entries are ignored and pruned on the next save.
+	saveCachedVersion(server, "ws-1", 3, "[workspace]\n", "")
+	old := time.Now().Add(-2 * diffCacheTTL)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	saveCachedVersion(server, "ws-2", 1, "[workspace]\n", "")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected expired entry to be pruned, stat err=%v", err)
+	}
+}
+
+func TestFetchServerSourceSkipsCacheOnLockError(t *testing.T) {
+	t.Setenv("NEBI_DATA_DIR", t.TempDir())
+	lockFails := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pixi-toml"):
+			fmt.Fprint(w, "[workspace]\n")
+		case strings.HasSuffix(r.URL.Path, "/pixi-lock") && lockFails:
+			http.Error(w, `{"error":"unavailable"}`, http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, "version: 6\n")
+		}
+	}))
+	defer srv.Close()
+	client := cliclient.New(srv.URL, "token")
+	ctx := context.Background()
+
+	src, err := fetchServerSource(client, ctx, "ws-1", 2, "ws:v2")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if src.lock != "" {
+		t.Errorf("expected an empty lock after a failed fetch, got %q", src.lock)
+	}
+	if _, ok := loadCachedVersion(client.BaseURL(), "ws-1", 2); ok {
+		t.Fatal("a version whose lock fetch failed should not be cached")
+	}
+
+	lockFails = false
+	src, err = fetchServerSource(client, ctx, "ws-1", 2, "ws:v2")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if src.lock != "version: 6\n" {
+		t.Errorf("expected the lock to be fetched again, got %q", src.lock)
+	}
+	if got, ok := loadCachedVersion(client.BaseURL(), "ws-1", 2); !ok || got.Lock != "version: 6\n" {
+		t.Errorf("expected the complete version to be cached, got %+v ok=%v", got, ok)
+	}
+}
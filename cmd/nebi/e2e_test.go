@@ -6,20 +6,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/nebari-dev/nebi/internal/cliclient"
+	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
 	"github.com/nebari-dev/nebi/internal/server"
 	"github.com/nebari-dev/nebi/internal/store"
 )
@@ -148,11 +152,47 @@ type runResult struct {
 // resetFlags resets all package-level flag variables to their zero values
 // to prevent state leaking between in-process CLI invocations.
 func resetFlags() {
+	// pager.go
+	noPager = false
+
 	// diff.go
 	diffLock = false
+	diffSummaryOnly = false
+	diffLockEnv = ""
+	diffNoLockHint = false
+	diffNoHints = false
+	diffContextSec = false
+	diffGroupBy = ""
+	diffLockStale = false
+	diffMarkdown = false
+	diffSincePull = false
+	diffIgnoreHash = false
+	diffRefresh = false
+	diffEnv = ""
+	diffEnvDefault = false
+	diffStatOnly = false
+	diffCountOnly = false
+	diffInstalled = false
+	diffOnly = nil
+	diffJSON = false
+	diffAgainst = ""
+	diffFailOnMajor = false
+	diffFailOnMinor = false
+	diffStrict = false
+	diffOutputFile = ""
+	diffBaseline = ""
+	diffLeftToml = ""
+	diffRightToml = ""
+	diffExitZero = false
+	diffExitChange = false
+	diffSecurity = false
 	// pull.go
 	pullOutput = "."
 	pullForce = false
+	pullHook = ""
+	pullSaveHook = false
+	pullNoHook = false
+	pullManifestOnly = false
 	// push.go
 	pushForce = false
 	pushJSON = false
@@ -161,9 +201,21 @@ func resetFlags() {
 	wsListJSON = false
 	wsListInstalled = false
 	wsTagsJSON = false
+	wsStatsJSON = false
+	wsAutoLatestClear = false
 	wsRemoveRemote = false
+	wsRemoveYes = false
+	wsForkHistory = false
+	// workspace_export.go
+	wsExportAllVersions = false
+	wsExportOutput = ""
+	wsRestoreName = ""
+	// solve.go
+	solvePlatforms = nil
 	// login.go
 	loginToken = ""
+	loginSSO = false
+	loginNoVerify = false
 	// publish.go
 	publishRegistry = ""
 	publishTag = ""
@@ -186,8 +238,13 @@ func resetFlags() {
 	registryLocal = false
 	// status.go
 	statusJSON = false
+	statusOffline = false
 	// info.go
 	infoJSON = false
+	infoFields = ""
+	infoResolveSymbolic = false
+	// admin.go
+	adminUserWorkspacesJSON = false
 }
 
 // runCLI executes a CLI command in-process and captures output.
@@ -230,6 +287,10 @@ func runCLI(t *testing.T, workDir string, args ...string) runResult {
 	rootCmd.SetArgs(args)
 	if err := rootCmd.Execute(); err != nil {
 		result.ExitCode = 1
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.code
+		}
 	}
 
 	stdoutW.Close()
@@ -374,6 +435,418 @@ func TestE2E_DiffLocalDir(t *testing.T) {
 	}
 }
 
+func TestE2E_DiffFailIfLockStale(t *testing.T) {
+	setupLocalStore(t)
+
+	dir := t.TempDir()
+	toml := "[workspace]\nname = \"stale-check\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n\n[dependencies]\nnumpy = \"*\"\n"
+	lock := `version: 6
+environments:
+  default:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.26.4-py312h1234_0.conda
+packages:
+- conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.26.4-py312h1234_0.conda
+`
+	writePixiFiles(t, dir, toml, lock)
+
+	res := runCLI(t, dir, "diff", "--fail-if-lock-stale")
+	if res.ExitCode != 0 {
+		t.Fatalf("expected current lock to pass (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	// Edit pixi.toml without re-locking.
+	writePixiFiles(t, dir, toml+"scipy = \"*\"\n", lock)
+	res = runCLI(t, dir, "diff", "--fail-if-lock-stale")
+	if res.ExitCode == 0 {
+		t.Fatalf("expected stale lock to fail, got stdout: %s", res.Stdout)
+	}
+	if !strings.Contains(res.Stdout, "scipy (conda) is not locked") {
+		t.Errorf("expected report of scipy, got stdout: %s", res.Stdout)
+	}
+	if !strings.Contains(res.Stderr, "stale") {
+		t.Errorf("expected stale error, got stderr: %s", res.Stderr)
+	}
+}
+
+func TestE2E_DiffInstalled(t *testing.T) {
+	setupLocalStore(t)
+
+	platform := pixi.CurrentPlatform()
+	dir := t.TempDir()
+	toml := "[workspace]\nname = \"installed\"\nchannels = [\"conda-forge\"]\nplatforms = [\"" + platform + "\"]\n"
+	lock := "version: 6\nenvironments:\n  default:\n    packages:\n      " + platform + ":\n" +
+		"      - conda: https://conda.anaconda.org/conda-forge/" + platform + "/numpy-1.26.4-py312_0.conda\n" +
+		"packages:\n- conda: https://conda.anaconda.org/conda-forge/" + platform + "/numpy-1.26.4-py312_0.conda\n"
+	writePixiFiles(t, dir, toml, lock)
+
+	res := runCLI(t, dir, "diff", "--installed")
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "pixi install") {
+		t.Fatalf("expected missing environment error, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+
+	prefix := pixi.EnvPrefix(dir, "default")
+	os.MkdirAll(filepath.Join(prefix, "conda-meta"), 0755)
+	os.WriteFile(filepath.Join(prefix, "conda-meta", "numpy.json"), []byte(`{"name": "numpy", "version": "1.26.4"}`), 0644)
+	res = runCLI(t, dir, "diff", "--installed")
+	if res.ExitCode != 0 {
+		t.Fatalf("expected installed env to match (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	// A manual pip install shows up as drift.
+	distInfo := filepath.Join(prefix, "lib", "python3.12", "site-packages", "rich-13.7.0.dist-info")
+	os.MkdirAll(distInfo, 0755)
+	os.WriteFile(filepath.Join(distInfo, "INSTALLER"), []byte("pip\n"), 0644)
+	res = runCLI(t, dir, "diff", "--installed")
+	if res.ExitCode == 0 {
+		t.Fatalf("expected drift to fail, got stdout: %s", res.Stdout)
+	}
+	if !strings.Contains(res.Stdout, "Installed but not locked:") || !strings.Contains(res.Stdout, "+ rich (pypi) 13.7.0") {
+		t.Errorf("expected pip package report, got stdout: %s", res.Stdout)
+	}
+}
+
+func TestE2E_DiffStatOnlyExit(t *testing.T) {
+	setupLocalStore(t)
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	toml := "[workspace]\nname = \"stat\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir1, toml, "version: 6\n")
+	writePixiFiles(t, dir2, toml, "version: 6\n")
+
+	res := runCLI(t, dir1, "diff", dir2, "--stat-only-exit")
+	if res.ExitCode != 0 {
+		t.Fatalf("expected exit 0 without differences, got %d:\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if res.Stdout != "toml: 0 changed, lock: +0 -0 ~0\n" || res.Stderr != "" {
+		t.Errorf("unexpected output:\nstdout: %q\nstderr: %q", res.Stdout, res.Stderr)
+	}
+
+	writePixiFiles(t, dir2, toml+"\n[dependencies]\nnumpy = \"*\"\n", "version: 6\n")
+	res = runCLI(t, dir1, "diff", dir2, "--stat-only-exit")
+	if res.ExitCode != 1 {
+		t.Fatalf("expected exit 1 with differences, got %d:\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if res.Stdout != "toml: 1 changed, lock: +0 -0 ~0\n" || res.Stderr != "" {
+		t.Errorf("unexpected output:\nstdout: %q\nstderr: %q", res.Stdout, res.Stderr)
+	}
+
+	res = runCLI(t, dir1, "diff", filepath.Join(dir2, "missing"), "--stat-only-exit")
+	if res.ExitCode != 2 {
+		t.Fatalf("expected exit 2 on error, got %d", res.ExitCode)
+	}
+	if res.Stdout != "" || res.Stderr != "" {
+		t.Errorf("expected no output on error:\nstdout: %q\nstderr: %q", res.Stdout, res.Stderr)
+	}
+}
+
+func TestE2E_DiffHighlightSecurity(t *testing.T) {
+	setupLocalStore(t)
+
+	lockWith := func(pkg string) string {
+		url := "https://conda.anaconda.org/conda-forge/noarch/" + pkg + "-0.conda"
+		return "version: 6\nenvironments:\n  default:\n    packages:\n      linux-64:\n      - conda: " + url + "\npackages:\n- conda: " + url + "\n"
+	}
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	toml := "[workspace]\nname = \"sec\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir1, toml, lockWith("requests-2.30.0-pyhd8ed1ab"))
+	writePixiFiles(t, dir2, toml, lockWith("requests-2.32.0-pyhd8ed1ab"))
+
+	osv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Old version first, then new: only the old one is affected.
+		w.Write([]byte(`{"results":[{"vulns":[{"id":"GHSA-9wx4-h78v-vm56"}]},{}]}`))
+	}))
+	defer osv.Close()
+	t.Setenv("NEBI_ADVISORY_URL", osv.URL)
+
+	res := runCLI(t, dir1, "diff", dir2, "--highlight-security")
+	if res.ExitCode != 0 {
+		t.Fatalf("diff failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "!requests 2.30.0 -> 2.32.0: fixes GHSA-9wx4-h78v-vm56") {
+		t.Errorf("expected requests to be highlighted, got stdout: %s", res.Stdout)
+	}
+
+	// An unreachable source degrades to a warning.
+	t.Setenv("NEBI_ADVISORY_URL", "http://127.0.0.1:1")
+	res = runCLI(t, dir1, "diff", dir2, "--highlight-security", "--refresh", "--json")
+	if res.ExitCode != 0 || !strings.Contains(res.Stderr, "continuing without highlights") || strings.Contains(res.Stdout, `"security"`) {
+		t.Errorf("expected a warning and no highlights (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+}
+
+func TestE2E_DiffExitOnChangeConfig(t *testing.T) {
+	setupLocalStore(t)
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	toml := "[workspace]\nname = \"exit\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir1, toml, "version: 6\n")
+	writePixiFiles(t, dir2, toml+"\n[dependencies]\nnumpy = \"*\"\n", "version: 6\n")
+
+	t.Setenv("NEBI_DIFF_EXIT_ON_CHANGE", "false")
+	res := runCLI(t, dir1, "diff", dir2, "--stat-only-exit")
+	if res.ExitCode != 0 || res.Stdout != "toml: 1 changed, lock: +0 -0 ~0\n" {
+		t.Errorf("expected exit 0 with the config off, got %d: %q", res.ExitCode, res.Stdout)
+	}
+	res = runCLI(t, dir1, "diff", dir2, "--stat-only-exit", "--exit-on-change")
+	if res.ExitCode != 1 {
+		t.Errorf("expected --exit-on-change to override the config, got exit %d", res.ExitCode)
+	}
+
+	t.Setenv("NEBI_DIFF_EXIT_ON_CHANGE", "")
+	res = runCLI(t, dir1, "diff", dir2, "--stat-only-exit", "--exit-zero")
+	if res.ExitCode != 0 {
+		t.Errorf("expected --exit-zero to exit 0, got %d", res.ExitCode)
+	}
+	res = runCLI(t, dir1, "diff", dir2, "--exit-zero")
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "--stat-only-exit") {
+		t.Errorf("expected --exit-zero without --stat-only-exit to fail, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestE2E_DiffFailOnMajor(t *testing.T) {
+	setupLocalStore(t)
+
+	lockWith := func(pkgs ...string) string {
+		var refs, entries strings.Builder
+		for _, p := range pkgs {
+			url := "https://conda.anaconda.org/conda-forge/linux-64/" + p + "-0.conda"
+			refs.WriteString("      - conda: " + url + "\n")
+			entries.WriteString("- conda: " + url + "\n")
+		}
+		return "version: 6\nenvironments:\n  default:\n    packages:\n      linux-64:\n" + refs.String() + "packages:\n" + entries.String()
+	}
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	toml := "[workspace]\nname = \"gate\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir1, toml, lockWith("numpy-1.26.4-py312_0", "pandas-2.1.0-py312_0"))
+	writePixiFiles(t, dir2, toml, lockWith("numpy-1.26.4-py312_0", "pandas-2.2.0-py312_0"))
+
+	res := runCLI(t, dir1, "diff", dir2, "--fail-on-major")
+	if res.ExitCode != 0 {
+		t.Fatalf("minor bump should pass --fail-on-major (exit %d):\nstderr: %s", res.ExitCode, res.Stderr)
+	}
+	res = runCLI(t, dir1, "diff", dir2, "--fail-on-minor")
+	if res.ExitCode == 0 {
+		t.Fatalf("minor bump should fail --fail-on-minor, got stderr: %s", res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "pandas 2.1.0 -> 2.2.0 (minor)") {
+		t.Errorf("expected pandas to be listed, got stderr: %s", res.Stderr)
+	}
+
+	writePixiFiles(t, dir2, toml, lockWith("numpy-2.0.0-py312_0", "pandas-2.1.0-py312_0"))
+	res = runCLI(t, dir1, "diff", dir2, "--fail-on-major")
+	if res.ExitCode == 0 {
+		t.Fatalf("major bump should fail --fail-on-major, got stderr: %s", res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "numpy 1.26.4 -> 2.0.0 (major)") || !strings.Contains(res.Stderr, "1 package(s) have a major version change") {
+		t.Errorf("expected numpy to be reported, got stderr: %s", res.Stderr)
+	}
+
+	res = runCLI(t, dir1, "diff", dir2, "--strict")
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "--strict requires") {
+		t.Errorf("expected --strict without a gate to be rejected, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestE2E_DiffOutputFile(t *testing.T) {
+	setupLocalStore(t)
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	toml := "[workspace]\nname = \"out\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir1, toml, "version: 6\n")
+	writePixiFiles(t, dir2, toml+"\n[dependencies]\nnumpy = \"*\"\n", "version: 6\n")
+	outDir := t.TempDir()
+
+	for _, mode := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"text", nil, "+numpy"},
+		{"markdown", []string{"--markdown"}, "numpy"},
+		{"json", []string{"--json"}, `"schema_version"`},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			path := filepath.Join(outDir, mode.name+".out")
+			res := runCLI(t, dir1, append([]string{"diff", dir2, "--output", path}, mode.args...)...)
+			if res.ExitCode != 0 {
+				t.Fatalf("diff failed (exit %d):\nstderr: %s", res.ExitCode, res.Stderr)
+			}
+			if res.Stdout != "" {
+				t.Errorf("expected nothing on stdout, got: %s", res.Stdout)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading output file: %v", err)
+			}
+			if !strings.Contains(string(got), mode.want) {
+				t.Errorf("expected %q in output file, got: %s", mode.want, got)
+			}
+		})
+	}
+
+	// A failing check still writes its report and keeps the exit code.
+	lock := "version: 6\nenvironments:\n  default:\n    packages:\n      linux-64: []\npackages: []\n"
+	writePixiFiles(t, dir2, toml+"\n[dependencies]\nnumpy = \"*\"\n", lock)
+	path := filepath.Join(outDir, "stale.out")
+	res := runCLI(t, dir2, "diff", "--fail-if-lock-stale", "-o", path)
+	if res.ExitCode == 0 {
+		t.Fatalf("expected stale lock to fail, got stderr: %s", res.Stderr)
+	}
+	got, _ := os.ReadFile(path)
+	if !strings.Contains(string(got), "numpy (conda) is not locked") {
+		t.Errorf("expected stale report in output file, got: %s", got)
+	}
+
+	// "-" writes to stdout.
+	res = runCLI(t, dir1, "diff", dir2, "-o", "-")
+	if res.ExitCode != 0 || !strings.Contains(res.Stdout, "+numpy") {
+		t.Errorf("expected diff on stdout with -o -, got exit %d: %s", res.ExitCode, res.Stdout)
+	}
+}
+
+func TestE2E_DiffBaselineFile(t *testing.T) {
+	setupLocalStore(t)
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	toml := "[workspace]\nname = \"base\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir1, toml, "version: 6\n")
+	writePixiFiles(t, dir2, toml+"\n[dependencies]\nnumpy = \"*\"\n", "version: 6\n")
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+
+	res := runCLI(t, dir1, "diff", dir2, "--json", "--output", baseline)
+	if res.ExitCode != 0 {
+		t.Fatalf("saving baseline failed (exit %d):\nstderr: %s", res.ExitCode, res.Stderr)
+	}
+
+	res = runCLI(t, dir1, "diff", dir2, "--baseline-file", baseline)
+	if res.ExitCode != 0 {
+		t.Fatalf("expected diff to match its baseline (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "matches baseline") {
+		t.Errorf("expected match message, got stderr: %s", res.Stderr)
+	}
+
+	writePixiFiles(t, dir2, toml+"\n[dependencies]\nscipy = \"*\"\n", "version: 6\n")
+	res = runCLI(t, dir1, "diff", dir2, "--baseline-file", baseline)
+	if res.ExitCode == 0 {
+		t.Fatalf("expected drift from the baseline to fail, got stdout: %s", res.Stdout)
+	}
+	if !strings.Contains(res.Stdout, "+ pixi.toml [dependencies] scipy added") || !strings.Contains(res.Stdout, "- pixi.toml [dependencies] numpy added") {
+		t.Errorf("expected delta lines, got stdout: %s", res.Stdout)
+	}
+	if !strings.Contains(res.Stderr, "differs from baseline") {
+		t.Errorf("expected baseline error, got stderr: %s", res.Stderr)
+	}
+}
+
+func TestE2E_DiffCountOnly(t *testing.T) {
+	setupLocalStore(t)
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	toml := "[workspace]\nname = \"count\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir1, toml, "version: 6\n")
+	writePixiFiles(t, dir2, toml, "version: 6\n")
+
+	res := runCLI(t, dir1, "diff", dir2, "--count-only")
+	if res.ExitCode != 0 || res.Stdout != "0\n" || res.Stderr != "" {
+		t.Fatalf("unexpected result (exit %d):\nstdout: %q\nstderr: %q", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	writePixiFiles(t, dir2, toml+"\n[dependencies]\nnumpy = \"*\"\nscipy = \"*\"\n", "version: 6\n")
+	res = runCLI(t, dir1, "diff", dir2, "--count-only")
+	if res.ExitCode != 0 || res.Stdout != "2\n" {
+		t.Errorf("expected 2 changes and exit 0, got exit %d: %q", res.ExitCode, res.Stdout)
+	}
+
+	res = runCLI(t, dir1, "diff", filepath.Join(dir2, "missing"), "--count-only")
+	if res.ExitCode == 0 || res.Stdout != "" {
+		t.Errorf("expected an error without a count, got exit %d: %q", res.ExitCode, res.Stdout)
+	}
+}
+
+func TestE2E_DiffStdin(t *testing.T) {
+	setupLocalStore(t)
+
+	dir := t.TempDir()
+	toml := "[workspace]\nname = \"stdin\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir, toml, "version: 6\n")
+	changed := toml + "\n[dependencies]\nnumpy = \"*\"\n"
+
+	// "-" reads a framed pixi.toml + pixi.lock payload.
+	res := runCLIWithStdin(t, dir, changed+"--- pixi.lock ---\nversion: 6\n", "diff", "./", "-", "--count-only")
+	if res.ExitCode != 0 || res.Stdout != "1\n" {
+		t.Errorf("expected 1 change from stdin, got exit %d: %q %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	// --left-toml/--right-toml compare bare manifests, one from stdin.
+	left := filepath.Join(dir, "pixi.toml")
+	res = runCLIWithStdin(t, dir, changed, "diff", "--left-toml", left, "--right-toml", "-")
+	if res.ExitCode != 0 || !strings.Contains(res.Stdout, "numpy") {
+		t.Errorf("expected numpy in diff, got exit %d:\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	res = runCLIWithStdin(t, dir, changed, "diff", "--left-toml", "-", "--right-toml", "-")
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "only one") {
+		t.Errorf("expected both sides on stdin to fail, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+	res = runCLIWithStdin(t, dir, changed, "diff", "-", "-")
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "only one") {
+		t.Errorf("expected both refs on stdin to fail, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestE2E_DiffJSONSchemaVersion(t *testing.T) {
+	setupLocalStore(t)
+
+	platform := pixi.CurrentPlatform()
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	toml := "[workspace]\nname = \"json\"\nchannels = [\"conda-forge\"]\nplatforms = [\"" + platform + "\"]\n"
+	lock := "version: 6\nenvironments:\n  default:\n    packages:\n      " + platform + ": []\npackages: []\n"
+	writePixiFiles(t, dir1, toml, lock)
+	writePixiFiles(t, dir2, toml+"\n[dependencies]\nnumpy = \"*\"\n", lock)
+	os.MkdirAll(filepath.Join(pixi.EnvPrefix(dir1, "default"), "conda-meta"), 0755)
+
+	variants := []struct {
+		name     string
+		args     []string
+		wantExit bool
+		key      string
+	}{
+		{"compare", []string{"diff", dir2, "--json"}, false, "toml"},
+		{"lock-stale", []string{"diff", dir2, "--fail-if-lock-stale", "--json"}, true, "stale"},
+		{"installed", []string{"diff", "--installed", "--json"}, false, "drift"},
+	}
+	for _, v := range variants {
+		t.Run(v.name, func(t *testing.T) {
+			res := runCLI(t, dir1, v.args...)
+			if (res.ExitCode != 0) != v.wantExit {
+				t.Fatalf("unexpected exit %d:\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+			}
+			var result map[string]interface{}
+			if err := json.Unmarshal([]byte(res.Stdout), &result); err != nil {
+				t.Fatalf("invalid JSON: %v\nraw: %s", err, res.Stdout)
+			}
+			if result["schema_version"] != float64(jsonSchemaVersion) {
+				t.Errorf("expected schema_version %d, got: %v", jsonSchemaVersion, result["schema_version"])
+			}
+			if _, ok := result[v.key]; !ok {
+				t.Errorf("expected %q in output, got: %s", v.key, res.Stdout)
+			}
+		})
+	}
+}
+
 func TestE2E_DiffAgainstServer(t *testing.T) {
 	setupLocalStore(t)
 
@@ -624,6 +1097,49 @@ func TestE2E_LoginWithToken(t *testing.T) {
 	if !strings.Contains(res.Stderr, "Logged in") {
 		t.Errorf("expected 'Logged in' message, got stderr: %s", res.Stderr)
 	}
+	if !strings.Contains(res.Stderr, "Connected to "+e2eEnv.serverURL) {
+		t.Errorf("expected server version report, got stderr: %s", res.Stderr)
+	}
+}
+
+func TestE2E_LoginVerifiesServer(t *testing.T) {
+	setupLocalStore(t)
+
+	dir := t.TempDir()
+
+	// Nothing listens on the discard port.
+	unreachable := "http://127.0.0.1:9"
+	res := runCLI(t, dir, "login", unreachable, "--token", "fake-token-123")
+	if res.ExitCode == 0 {
+		t.Fatalf("expected login to an unreachable server to fail, got stderr: %s", res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "cannot reach") || !strings.Contains(res.Stderr, "--no-verify") {
+		t.Errorf("expected reachability error, got stderr: %s", res.Stderr)
+	}
+
+	res = runCLI(t, dir, "login", unreachable, "--token", "fake-token-123", "--no-verify")
+	if res.ExitCode != 0 {
+		t.Fatalf("expected --no-verify to save the server (exit %d):\nstderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "Logged in to "+unreachable) {
+		t.Errorf("expected 'Logged in' message, got stderr: %s", res.Stderr)
+	}
+}
+
+func TestE2E_LoginSSORequiresDeviceFlow(t *testing.T) {
+	setupLocalStore(t)
+
+	dir := t.TempDir()
+
+	// The e2e server has no OIDC provider, so --sso must fail rather than
+	// fall back to a username/password prompt.
+	res := runCLI(t, dir, "login", e2eEnv.serverURL, "--sso")
+	if res.ExitCode == 0 {
+		t.Fatalf("expected --sso login to fail without device flow, got stderr: %s", res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "does not have SSO") {
+		t.Errorf("expected SSO-not-configured error, got stderr: %s", res.Stderr)
+	}
 }
 
 func TestE2E_WorkspaceRemove(t *testing.T) {
@@ -690,44 +1206,256 @@ func TestE2E_WorkspaceRemoveServer(t *testing.T) {
 		"[project]\nname = \"remove-server-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n",
 		"version: 6\n",
 	)
-
-	res := runCLI(t, srcDir, "push", wsName+":"+tag)
-	if res.ExitCode != 0 {
+
+	res := runCLI(t, srcDir, "push", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	// Verify it exists on the server
+	res = runCLI(t, srcDir, "workspace", "list", "--remote")
+	if res.ExitCode != 0 {
+		t.Fatalf("workspace list failed: %s %s", res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, wsName) {
+		t.Fatalf("expected %s in server workspace list, got: %s", wsName, res.Stdout)
+	}
+
+	// Remove from server
+	res = runCLI(t, srcDir, "workspace", "remove", wsName, "--remote", "--yes")
+	if res.ExitCode != 0 {
+		t.Fatalf("workspace remove --remote failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "Deleted") {
+		t.Errorf("expected 'Deleted' message, got stderr: %s", res.Stderr)
+	}
+
+	// Wait for async deletion to complete, then verify it's gone
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		res = runCLI(t, srcDir, "workspace", "list", "--remote")
+		if res.ExitCode != 0 {
+			t.Fatalf("workspace list failed: %s %s", res.Stdout, res.Stderr)
+		}
+		if !strings.Contains(res.Stdout, wsName) {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	if strings.Contains(res.Stdout, wsName) {
+		t.Errorf("workspace still on server after remove: %s", res.Stdout)
+	}
+}
+
+func TestE2E_WorkspaceRemoveServerShowsImpact(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-remove-impact"
+
+	srcDir := t.TempDir()
+	writePixiFiles(t, srcDir,
+		"[project]\nname = \"remove-impact-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n",
+		"version: 6\n",
+	)
+	res := runCLI(t, srcDir, "push", wsName+":v1.0")
+	if res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	// Declining the prompt keeps the workspace.
+	res = runCLIWithStdin(t, srcDir, "n\n", "workspace", "remove", wsName, "--remote")
+	if res.ExitCode != 0 {
+		t.Fatalf("workspace remove --remote failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	for _, want := range []string{"version(s)", "3 tag(s)", "0 publication record(s)", "Aborted"} {
+		if !strings.Contains(res.Stderr, want) {
+			t.Errorf("expected %q in impact summary, got stderr: %s", want, res.Stderr)
+		}
+	}
+	res = runCLI(t, srcDir, "workspace", "list", "--remote")
+	if !strings.Contains(res.Stdout, wsName) {
+		t.Errorf("workspace should remain after declining, got: %s", res.Stdout)
+	}
+
+	res = runCLIWithStdin(t, srcDir, "y\n", "workspace", "remove", wsName, "--remote")
+	if res.ExitCode != 0 || !strings.Contains(res.Stderr, "Deleted") {
+		t.Fatalf("expected confirmed delete, exit %d stderr: %s", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestE2E_WorkspaceSetAutoLatest(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-auto-latest"
+	dir := t.TempDir()
+	toml := "[project]\nname = \"auto-latest\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir, toml, "version: 6\n")
+	if res := runCLI(t, dir, "push", wsName+":v1.0"); res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	res := runCLI(t, dir, "workspace", "set-auto-latest", wsName, "stable")
+	if res.ExitCode != 0 {
+		t.Fatalf("set-auto-latest failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	// Each push moves "stable" without naming it.
+	writePixiFiles(t, dir, toml+"\n[dependencies]\nnumpy = \"*\"\n", "version: 6\n")
+	res = runCLI(t, dir, "push", wsName)
+	if res.ExitCode != 0 || !strings.Contains(res.Stderr+res.Stdout, "stable") {
+		t.Fatalf("expected push to move stable (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	res = runCLI(t, dir, "workspace", "tags", wsName, "--json")
+	var tags []cliclient.WorkspaceTag
+	if err := json.Unmarshal([]byte(res.Stdout), &tags); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, res.Stdout)
+	}
+	versions := map[string]int{}
+	for _, tag := range tags {
+		versions[tag.Tag] = tag.VersionNumber
+	}
+	if versions["stable"] == 0 || versions["stable"] != versions["latest"] || versions["stable"] == versions["v1.0"] {
+		t.Errorf("expected stable at the newest version, got %v", versions)
+	}
+
+	res = runCLI(t, dir, "workspace", "set-auto-latest", wsName)
+	if res.ExitCode == 0 {
+		t.Errorf("expected a missing tag without --clear to fail")
+	}
+	res = runCLI(t, dir, "workspace", "set-auto-latest", wsName, "--clear")
+	if res.ExitCode != 0 {
+		t.Errorf("--clear failed (exit %d): %s", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestE2E_WorkspaceFork(t *testing.T) {
+	setupLocalStore(t)
+
+	srcName := "e2e-fork-src"
+	forkName := "e2e-fork-dst"
+
+	srcDir := t.TempDir()
+	writePixiFiles(t, srcDir,
+		"[project]\nname = \"fork-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n",
+		"version: 6\n",
+	)
+	if res := runCLI(t, srcDir, "push", srcName+":v1.0"); res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	res := runCLI(t, srcDir, "workspace", "fork", srcName, forkName, "--history")
+	if res.ExitCode != 0 {
+		t.Fatalf("workspace fork failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	// The fork is pullable at the source's tag.
+	pullDir := t.TempDir()
+	res = runCLI(t, pullDir, "pull", forkName+":v1.0")
+	if res.ExitCode != 0 {
+		t.Fatalf("pull of fork failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	got, _ := os.ReadFile(filepath.Join(pullDir, "pixi.toml"))
+	if !strings.Contains(string(got), "fork-test") {
+		t.Errorf("unexpected forked pixi.toml: %s", got)
+	}
+
+	res = runCLI(t, srcDir, "workspace", "fork", srcName, forkName)
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "already exists") {
+		t.Errorf("expected fork onto an existing name to fail, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestE2E_WorkspaceExportRestore(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-export-src"
+	srcDir := t.TempDir()
+	toml1 := "[project]\nname = \"export-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	toml2 := toml1 + "\n[dependencies]\nnumpy = \"*\"\n"
+	writePixiFiles(t, srcDir, toml1, "version: 6\n")
+	if res := runCLI(t, srcDir, "push", wsName+":v1.0"); res.ExitCode != 0 {
+		t.Fatalf("push v1.0 failed: %s %s", res.Stdout, res.Stderr)
+	}
+	writePixiFiles(t, srcDir, toml2, "version: 6\n")
+	if res := runCLI(t, srcDir, "push", wsName+":v2.0"); res.ExitCode != 0 {
+		t.Fatalf("push v2.0 failed: %s %s", res.Stdout, res.Stderr)
+	}
+	// Reassign "stable" from the first content to the second.
+	writePixiFiles(t, srcDir, toml1, "version: 6\n")
+	runCLI(t, srcDir, "push", wsName+":stable")
+	writePixiFiles(t, srcDir, toml2, "version: 6\n")
+	if res := runCLI(t, srcDir, "push", wsName+":stable", "--force"); res.ExitCode != 0 {
+		t.Fatalf("reassigning stable failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	res := runCLI(t, srcDir, "workspace", "export", wsName, "--all-versions", "-o", archive)
+	if res.ExitCode != 0 {
+		t.Fatalf("export failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	res = runCLI(t, srcDir, "workspace", "restore", archive)
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "already exists") {
+		t.Errorf("expected restore onto an existing name to fail, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+
+	restored := "e2e-export-dst"
+	res = runCLI(t, srcDir, "workspace", "restore", archive, "--name", restored)
+	if res.ExitCode != 0 {
+		t.Fatalf("restore failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	for tag, want := range map[string]string{"v1.0": toml1, "v2.0": toml2, "stable": toml2} {
+		pullDir := t.TempDir()
+		if res := runCLI(t, pullDir, "pull", restored+":"+tag); res.ExitCode != 0 {
+			t.Fatalf("pull %s failed: %s %s", tag, res.Stdout, res.Stderr)
+		}
+		got, _ := os.ReadFile(filepath.Join(pullDir, "pixi.toml"))
+		if string(got) != want {
+			t.Errorf("restored %s has pixi.toml:\n%s\nwant:\n%s", tag, got, want)
+		}
+	}
+}
+
+func TestE2E_Solve(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-solve"
+	srcDir := t.TempDir()
+	writePixiFiles(t, srcDir,
+		"[project]\nname = \"solve-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n",
+		"version: 6\n",
+	)
+	if res := runCLI(t, srcDir, "push", wsName+":v1.0"); res.ExitCode != 0 {
 		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
 	}
 
-	// Verify it exists on the server
-	res = runCLI(t, srcDir, "workspace", "list", "--remote")
+	res := runCLI(t, srcDir, "solve", wsName)
 	if res.ExitCode != 0 {
-		t.Fatalf("workspace list failed: %s %s", res.Stdout, res.Stderr)
+		t.Fatalf("solve failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
 	}
-	if !strings.Contains(res.Stdout, wsName) {
-		t.Fatalf("expected %s in server workspace list, got: %s", wsName, res.Stdout)
+	if !strings.Contains(res.Stderr, "linux-64 (from manifest)") {
+		t.Errorf("expected manifest platforms to be reported, got stderr: %s", res.Stderr)
 	}
 
-	// Remove from server
-	res = runCLI(t, srcDir, "workspace", "remove", wsName, "--remote")
+	res = runCLI(t, srcDir, "solve", wsName, "--platform", "linux-64", "--platform", "osx-arm64")
 	if res.ExitCode != 0 {
-		t.Fatalf("workspace remove --remote failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+		t.Fatalf("solve --platform failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
 	}
-	if !strings.Contains(res.Stderr, "Deleted") {
-		t.Errorf("expected 'Deleted' message, got stderr: %s", res.Stderr)
+	pullDir := t.TempDir()
+	if res := runCLI(t, pullDir, "pull", wsName); res.ExitCode != 0 {
+		t.Fatalf("pull failed: %s %s", res.Stdout, res.Stderr)
 	}
-
-	// Wait for async deletion to complete, then verify it's gone
-	deadline := time.Now().Add(5 * time.Second)
-	for time.Now().Before(deadline) {
-		res = runCLI(t, srcDir, "workspace", "list", "--remote")
-		if res.ExitCode != 0 {
-			t.Fatalf("workspace list failed: %s %s", res.Stdout, res.Stderr)
-		}
-		if !strings.Contains(res.Stdout, wsName) {
-			break
-		}
-		time.Sleep(200 * time.Millisecond)
+	// The override applies to that solve only; the declared platforms stay.
+	got, _ := os.ReadFile(filepath.Join(pullDir, "pixi.toml"))
+	if !strings.Contains(string(got), `platforms = ["linux-64"]`) {
+		t.Errorf("expected declared platforms kept in pixi.toml, got: %s", got)
 	}
-	if strings.Contains(res.Stdout, wsName) {
-		t.Errorf("workspace still on server after remove: %s", res.Stdout)
+
+	res = runCLI(t, srcDir, "solve", wsName, "--platform", "linux-x86_64")
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "unknown platform") {
+		t.Errorf("expected unknown platform to be rejected, got exit %d: %s", res.ExitCode, res.Stderr)
 	}
 }
 
@@ -837,6 +1565,43 @@ func TestE2E_WorkspacePruneNoop(t *testing.T) {
 	}
 }
 
+func TestE2E_AdminUserWorkspaces(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-admin-user-ws"
+	dir := t.TempDir()
+	writePixiFiles(t, dir,
+		"[project]\nname = \"admin-ws\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n",
+		"version: 6\n",
+	)
+	if res := runCLI(t, dir, "push", wsName+":v1.0"); res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	res := runCLI(t, dir, "admin", "user-workspaces", "admin", "--json")
+	if res.ExitCode != 0 {
+		t.Fatalf("admin user-workspaces failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	var workspaces []cliclient.UserWorkspace
+	if err := json.Unmarshal([]byte(res.Stdout), &workspaces); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, res.Stdout)
+	}
+	var role string
+	for _, ws := range workspaces {
+		if ws.Name == wsName {
+			role = ws.Role
+		}
+	}
+	if role != "owner" {
+		t.Errorf("expected admin to own %s, got role %q in %+v", wsName, role, workspaces)
+	}
+
+	res = runCLI(t, dir, "admin", "user-workspaces", "no-such-user")
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "not found") {
+		t.Errorf("expected unknown user to fail, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+}
+
 func TestE2E_RegistryListDefault(t *testing.T) {
 	setupLocalStore(t)
 
@@ -1312,6 +2077,9 @@ func TestE2E_PullOverwritePromptDefaultsNo(t *testing.T) {
 		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
 	}
 
+	// Edit pixi.toml so the pull has something to overwrite
+	writePixiFiles(t, dir, toml+"version = \"0.2.0\"\n", lock)
+
 	// Pull into same dir WITHOUT --force (stdin is closed/empty, so prompt defaults to N → abort)
 	res = runCLI(t, dir, "pull", wsName+":"+tag)
 	// Should exit 0 because abort is not an error — it prints "Aborted." and returns nil
@@ -1352,6 +2120,228 @@ func TestE2E_PullForceSkipsPrompt(t *testing.T) {
 	}
 }
 
+func TestE2E_PullHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use sh syntax")
+	}
+	setupLocalStore(t)
+
+	wsName := "e2e-pull-hook"
+	tag := "v1.0"
+
+	dir := t.TempDir()
+	toml := "[project]\nname = \"hook-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir, toml, "version: 6\n")
+
+	if res := runCLI(t, dir, "init"); res.ExitCode != 0 {
+		t.Fatalf("init failed: %s %s", res.Stdout, res.Stderr)
+	}
+	if res := runCLI(t, dir, "push", wsName+":"+tag); res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	hookOut := filepath.Join(dir, "hook.out")
+	hook := `echo "$NEBI_WORKSPACE:$NEBI_TAG:$NEBI_VERSION" > hook.out`
+	res := runCLI(t, dir, "pull", wsName+":"+tag, "--force", "--hook", hook, "--save-hook")
+	if res.ExitCode != 0 {
+		t.Fatalf("pull --hook failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	got, err := os.ReadFile(hookOut)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if !strings.HasPrefix(string(got), wsName+":"+tag+":") || strings.TrimSpace(string(got)) == wsName+":"+tag+":" {
+		t.Errorf("unexpected hook environment: %q", got)
+	}
+
+	// The saved hook runs by default and is skipped with --no-hook.
+	os.Remove(hookOut)
+	if res := runCLI(t, dir, "pull", "--force", "--no-hook"); res.ExitCode != 0 {
+		t.Fatalf("pull --no-hook failed: %s %s", res.Stdout, res.Stderr)
+	}
+	if _, err := os.Stat(hookOut); err == nil {
+		t.Error("saved hook ran despite --no-hook")
+	}
+	if res := runCLI(t, dir, "pull", "--force"); res.ExitCode != 0 {
+		t.Fatalf("pull failed: %s %s", res.Stdout, res.Stderr)
+	}
+	if _, err := os.Stat(hookOut); err != nil {
+		t.Error("saved hook did not run")
+	}
+
+	// A failing hook fails the pull with its exit code.
+	res = runCLI(t, dir, "pull", "--force", "--hook", "exit 3")
+	if res.ExitCode != 3 {
+		t.Errorf("expected hook exit code 3, got %d:\nstderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "hook exited with code 3") {
+		t.Errorf("expected hook failure report, got stderr: %s", res.Stderr)
+	}
+}
+
+func TestE2E_PullManifestOnly(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-pull-manifest-only"
+	src := t.TempDir()
+	toml := "[workspace]\nname = \"manifest-only\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, src, toml, "version: 6\n# server lock\n")
+	if res := runCLI(t, src, "init"); res.ExitCode != 0 {
+		t.Fatalf("init failed: %s %s", res.Stdout, res.Stderr)
+	}
+	if res := runCLI(t, src, "push", wsName+":v1"); res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	dst := t.TempDir()
+	localLock := "version: 6\n# solved on this machine\n"
+	os.WriteFile(filepath.Join(dst, "pixi.lock"), []byte(localLock), 0644)
+
+	res := runCLI(t, dst, "pull", wsName+":v1", "--manifest-only")
+	if res.ExitCode != 0 {
+		t.Fatalf("pull --manifest-only failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "pixi.toml")); string(got) != toml {
+		t.Errorf("pixi.toml = %q, want the pulled manifest", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "pixi.lock")); string(got) != localLock {
+		t.Errorf("pixi.lock was modified: %q", got)
+	}
+	if !strings.Contains(res.Stderr, "run 'pixi lock'") {
+		t.Errorf("expected re-solve warning, got stderr: %s", res.Stderr)
+	}
+}
+
+func TestE2E_PullRepairsIncompleteCheckout(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-pull-repair"
+	tag := "v1.0"
+
+	dir := t.TempDir()
+	toml := "[project]\nname = \"repair-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	lock := "version: 6\n"
+	writePixiFiles(t, dir, toml, lock)
+
+	res := runCLI(t, dir, "init")
+	if res.ExitCode != 0 {
+		t.Fatalf("init failed: %s %s", res.Stdout, res.Stderr)
+	}
+	res = runCLI(t, dir, "push", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	// Simulate a pull interrupted after pixi.toml was written
+	pullDir := t.TempDir()
+	os.WriteFile(filepath.Join(pullDir, "pixi.toml"), []byte(toml), 0644)
+
+	// No --force and empty stdin: a repair of missing files must not prompt
+	res = runCLI(t, pullDir, "pull", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("pull failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "incomplete checkout") {
+		t.Errorf("expected incomplete checkout notice, got stderr: %s", res.Stderr)
+	}
+	if strings.Contains(res.Stderr, "Aborted") {
+		t.Errorf("repair should not prompt for overwrite, got stderr: %s", res.Stderr)
+	}
+	got, err := os.ReadFile(filepath.Join(pullDir, "pixi.lock"))
+	if err != nil {
+		t.Fatalf("pixi.lock not restored: %v", err)
+	}
+	if string(got) != lock {
+		t.Errorf("pixi.lock = %q, want %q", got, lock)
+	}
+}
+
+func TestE2E_PullLocalEditNotTreatedAsIncomplete(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-pull-local-edit"
+	tag := "v1.0"
+
+	dir := t.TempDir()
+	toml := "[project]\nname = \"local-edit-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	lock := "version: 6\n"
+	writePixiFiles(t, dir, toml, lock)
+
+	res := runCLI(t, dir, "init")
+	if res.ExitCode != 0 {
+		t.Fatalf("init failed: %s %s", res.Stdout, res.Stderr)
+	}
+	res = runCLI(t, dir, "push", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	pullDir := t.TempDir()
+	res = runCLI(t, pullDir, "pull", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("pull failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	// Edit pixi.lock by hand while pixi.toml still matches the server
+	edited := "version: 6\n# pinned locally\n"
+	os.WriteFile(filepath.Join(pullDir, "pixi.lock"), []byte(edited), 0644)
+
+	// Empty stdin declines the overwrite prompt
+	res = runCLI(t, pullDir, "pull", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("pull failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if strings.Contains(res.Stderr, "incomplete checkout") {
+		t.Errorf("local edit reported as an incomplete checkout, stderr: %s", res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "Aborted") {
+		t.Errorf("expected overwrite prompt to be declined, got stderr: %s", res.Stderr)
+	}
+	got, _ := os.ReadFile(filepath.Join(pullDir, "pixi.lock"))
+	if string(got) != edited {
+		t.Errorf("local edit was overwritten: pixi.lock = %q", got)
+	}
+}
+
+func TestE2E_PullAlreadyUpToDate(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-pull-up-to-date"
+	tag := "v1.0"
+
+	dir := t.TempDir()
+	toml := "[project]\nname = \"up-to-date-test\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	lock := "version: 6\n"
+	writePixiFiles(t, dir, toml, lock)
+
+	res := runCLI(t, dir, "init")
+	if res.ExitCode != 0 {
+		t.Fatalf("init failed: %s %s", res.Stdout, res.Stderr)
+	}
+	res = runCLI(t, dir, "push", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	pullDir := t.TempDir()
+	res = runCLI(t, pullDir, "pull", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("pull failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	// No --force and empty stdin: a matching checkout must not prompt
+	res = runCLI(t, pullDir, "pull", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("pull failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "Already up to date") {
+		t.Errorf("expected up-to-date notice, got stderr: %s", res.Stderr)
+	}
+	if strings.Contains(res.Stderr, "Overwrite?") || strings.Contains(res.Stderr, "Aborted") {
+		t.Errorf("up-to-date pull should not prompt, got stderr: %s", res.Stderr)
+	}
+}
+
 func TestE2E_DiffNoArgs(t *testing.T) {
 	setupLocalStore(t)
 
@@ -1396,6 +2386,96 @@ func TestE2E_DiffNoArgs(t *testing.T) {
 	}
 }
 
+func TestE2E_DiffSincePull(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-diff-since-pull"
+	tag := "v1.0"
+	toml := "[project]\nname = \"diff-since-pull\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+
+	dir := t.TempDir()
+	writePixiFiles(t, dir, toml, "version: 6\n")
+
+	res := runCLI(t, dir, "init")
+	if res.ExitCode != 0 {
+		t.Fatalf("init failed: %s %s", res.Stdout, res.Stderr)
+	}
+	res = runCLI(t, dir, "push", wsName+":"+tag)
+	if res.ExitCode != 0 {
+		t.Fatalf("push failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	// Move the tag from another directory so the origin tag no longer
+	// points at the version this directory pushed.
+	other := t.TempDir()
+	writePixiFiles(t, other, toml+"\n[dependencies]\nscipy = \"*\"\n", "version: 6\n")
+	res = runCLI(t, other, "push", wsName+":"+tag, "--force")
+	if res.ExitCode != 0 {
+		t.Fatalf("force push failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	// The plain no-arg diff follows the moved tag and sees scipy.
+	res = runCLI(t, dir, "diff")
+	if res.ExitCode != 0 {
+		t.Fatalf("diff failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "scipy") {
+		t.Errorf("expected no-arg diff to include upstream scipy change, got stdout: %s", res.Stdout)
+	}
+
+	// --since-pull compares against the version this directory pushed.
+	res = runCLI(t, dir, "diff", "--since-pull")
+	if res.ExitCode != 0 {
+		t.Fatalf("diff --since-pull failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "No differences") {
+		t.Errorf("expected 'No differences' with --since-pull, got stderr: %s stdout: %s", res.Stderr, res.Stdout)
+	}
+
+	res = runCLI(t, dir, "diff", "--since-pull", "other:v1")
+	if res.ExitCode == 0 {
+		t.Fatal("expected --since-pull with a ref to fail")
+	}
+}
+
+func TestE2E_DiffAgainst(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-diff-against"
+	toml := "[project]\nname = \"diff-against\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+
+	dir := t.TempDir()
+	writePixiFiles(t, dir, toml, "version: 6\n")
+	if res := runCLI(t, dir, "push", wsName+":v1.0"); res.ExitCode != 0 {
+		t.Fatalf("push failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	other := t.TempDir()
+	writePixiFiles(t, other, toml+"\n[dependencies]\nscipy = \"*\"\n", "version: 6\n")
+	if res := runCLI(t, other, "push", wsName+":v2.0"); res.ExitCode != 0 {
+		t.Fatalf("push v2.0 failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+
+	// A local edit must not show up: both sides come from the server.
+	writePixiFiles(t, dir, toml+"\n[dependencies]\nnumpy = \"*\"\n", "version: 6\n")
+
+	res := runCLI(t, dir, "diff", "--against", "v2.0")
+	if res.ExitCode != 0 {
+		t.Fatalf("diff --against failed (exit %d):\nstdout: %s\nstderr: %s", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "scipy") || strings.Contains(res.Stdout, "numpy") {
+		t.Errorf("expected the v1.0 to v2.0 change only, got stdout: %s", res.Stdout)
+	}
+	if !strings.Contains(res.Stdout, wsName+":v2.0") {
+		t.Errorf("expected v2.0 in the diff labels, got stdout: %s", res.Stdout)
+	}
+
+	res = runCLI(t, dir, "diff", "--against", "v2.0", "other:v1")
+	if res.ExitCode == 0 {
+		t.Fatal("expected --against with a ref to fail")
+	}
+}
+
 func TestE2E_DiffNoArgsNoOrigin(t *testing.T) {
 	setupLocalStore(t)
 
@@ -1841,6 +2921,50 @@ func TestE2E_WorkspaceListJSONEmpty(t *testing.T) {
 	}
 }
 
+func TestE2E_StatusAheadBehind(t *testing.T) {
+	setupLocalStore(t)
+
+	wsName := "e2e-status-behind"
+	dir := t.TempDir()
+	toml := "[project]\nname = \"status-behind\"\nchannels = [\"conda-forge\"]\nplatforms = [\"linux-64\"]\n"
+	writePixiFiles(t, dir, toml, "version: 6\n")
+	if res := runCLI(t, dir, "push", wsName+":v1"); res.ExitCode != 0 {
+		t.Fatalf("push failed: %s %s", res.Stdout, res.Stderr)
+	}
+
+	res := runCLI(t, dir, "status")
+	if !strings.Contains(res.Stdout, "behind 0 / ahead 0") {
+		t.Errorf("expected 'behind 0 / ahead 0', got: %s", res.Stdout)
+	}
+
+	// Someone else pushes a newer version.
+	other := t.TempDir()
+	writePixiFiles(t, other, toml+"\n[dependencies]\nnumpy = \"*\"\n", "version: 6\n")
+	if res := runCLI(t, other, "push", wsName+":v2"); res.ExitCode != 0 {
+		t.Fatalf("second push failed: %s %s", res.Stdout, res.Stderr)
+	}
+	writePixiFiles(t, dir, toml+"\n[dependencies]\nscipy = \"*\"\n", "version: 6\n")
+
+	res = runCLI(t, dir, "status")
+	if !strings.Contains(res.Stdout, "behind 1 / ahead 1") {
+		t.Errorf("expected 'behind 1 / ahead 1', got: %s", res.Stdout)
+	}
+
+	res = runCLI(t, dir, "status", "--json")
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(res.Stdout), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, res.Stdout)
+	}
+	if result["behind"] != float64(1) || result["ahead"] != float64(1) {
+		t.Errorf("expected behind 1 / ahead 1 in JSON, got: %s", res.Stdout)
+	}
+
+	res = runCLI(t, dir, "status", "--offline")
+	if !strings.Contains(res.Stdout, "ahead 1 (offline") || strings.Contains(res.Stdout, " / ahead") {
+		t.Errorf("expected offline status without server lookups, got: %s", res.Stdout)
+	}
+}
+
 func TestE2E_StatusJSON(t *testing.T) {
 	setupLocalStore(t)
 
@@ -1864,6 +2988,9 @@ func TestE2E_StatusJSON(t *testing.T) {
 	if result["path"] != dir {
 		t.Errorf("expected path %q, got: %v", dir, result["path"])
 	}
+	if result["schema_version"] != float64(jsonSchemaVersion) {
+		t.Errorf("expected schema_version %d, got: %v", jsonSchemaVersion, result["schema_version"])
+	}
 }
 
 func TestE2E_StatusJSONNotTracked(t *testing.T) {
@@ -1954,6 +3081,9 @@ func TestE2E_Info(t *testing.T) {
 	if result["server_status"] != "reachable" {
 		t.Errorf("expected server_status='reachable', got: %v", result["server_status"])
 	}
+	if result["schema_version"] != float64(jsonSchemaVersion) {
+		t.Errorf("expected schema_version %d, got: %v", jsonSchemaVersion, result["schema_version"])
+	}
 }
 
 func TestE2E_InfoWorkspace(t *testing.T) {
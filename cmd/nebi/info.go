@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"sort"
 	"strings"
@@ -12,14 +14,21 @@ import (
 
 	"github.com/nebari-dev/nebi/internal/cliclient"
 	"github.com/nebari-dev/nebi/internal/config"
+	"github.com/nebari-dev/nebi/internal/contenthash"
 	"github.com/nebari-dev/nebi/internal/store"
 	"github.com/spf13/cobra"
 )
 
-var infoJSON bool
+var (
+	infoJSON            bool
+	infoFields          string
+	infoResolveSymbolic bool
+)
 
 func init() {
 	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "Output as JSON")
+	infoCmd.Flags().StringVar(&infoFields, "fields", "", "Comma-separated list of fields to output (e.g. server_url,username)")
+	infoCmd.Flags().BoolVar(&infoResolveSymbolic, "resolve-symbolic", false, "Show the version a mutable origin tag (e.g. latest) currently points to")
 }
 
 var infoCmd = &cobra.Command{
@@ -30,12 +39,17 @@ authentication status, and current workspace.
 
 Examples:
   nebi info
-  nebi info --json`,
+  nebi info --json
+  nebi info --fields server_url,username
+  nebi info --fields workspace,origin --json
+  nebi info --resolve-symbolic`,
 	Args: cobra.NoArgs,
 	RunE: runInfo,
 }
 
 type infoResult struct {
+	SchemaVersion int `json:"schema_version"`
+
 	// Nebi section
 	Version       string `json:"version"`
 	Platform      string `json:"platform"`
@@ -59,13 +73,20 @@ type infoResult struct {
 	WorkspacePath  string `json:"workspace_path,omitempty"`
 	PackageManager string `json:"package_manager,omitempty"`
 	Origin         string `json:"origin,omitempty"`
+	OriginResolved string `json:"origin_resolved,omitempty"`
 	LocalEdits     string `json:"local_edits,omitempty"`
 }
 
 func runInfo(cmd *cobra.Command, args []string) error {
+	fields, err := parseInfoFields(infoFields)
+	if err != nil {
+		return err
+	}
+
 	result := infoResult{
-		Version:  Version,
-		Platform: runtime.GOOS + "-" + runtime.GOARCH,
+		SchemaVersion: jsonSchemaVersion,
+		Version:       Version,
+		Platform:      runtime.GOOS + "-" + runtime.GOARCH,
 	}
 
 	// Data dir
@@ -117,7 +138,14 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	}
 
 	// Workspace section
-	fillWorkspaceInfo(&result)
+	ws := fillWorkspaceInfo(&result)
+	if infoResolveSymbolic && ws != nil && ws.OriginName != "" {
+		result.OriginResolved = resolveOriginTag(ws.OriginName, ws.OriginTag)
+	}
+
+	if len(fields) > 0 {
+		return printInfoFields(result, fields)
+	}
 
 	if infoJSON {
 		return writeJSON(result)
@@ -127,6 +155,92 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// infoFieldNames returns the selectable field names, in declaration order,
+// taken from the JSON tags of infoResult.
+func infoFieldNames() []string {
+	t := reflect.TypeOf(infoResult{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseInfoFields splits and validates a --fields value. Duplicates are
+// dropped while preserving the requested order.
+func parseInfoFields(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]bool)
+	for _, name := range infoFieldNames() {
+		known[name] = true
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" || seen[f] {
+			continue
+		}
+		if !known[f] {
+			return nil, fmt.Errorf("unknown field %q; valid fields: %s", f, strings.Join(infoFieldNames(), ", "))
+		}
+		seen[f] = true
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("--fields requires at least one field name")
+	}
+	return fields, nil
+}
+
+// infoFieldValues flattens an infoResult into a map keyed by JSON field
+// name. Fields omitted by omitempty are reported as empty strings.
+func infoFieldValues(r infoResult) (map[string]any, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	for _, name := range infoFieldNames() {
+		if _, ok := values[name]; !ok {
+			values[name] = ""
+		}
+	}
+	return values, nil
+}
+
+// printInfoFields prints only the requested fields, as "key: value" lines
+// or as a JSON object when --json is set.
+func printInfoFields(r infoResult, fields []string) error {
+	values, err := infoFieldValues(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode info: %w", err)
+	}
+
+	if infoJSON {
+		selected := make(map[string]any, len(fields))
+		for _, f := range fields {
+			selected[f] = values[f]
+		}
+		return writeJSON(selected)
+	}
+
+	for _, f := range fields {
+		fmt.Fprintf(os.Stdout, "%s: %v\n", f, values[f])
+	}
+	return nil
+}
+
 func resolveInfoAuth() (serverURL, token, username, source string) {
 	if envToken := os.Getenv("NEBI_AUTH_TOKEN"); envToken != "" {
 		if envURL := os.Getenv("NEBI_REMOTE_URL"); envURL != "" {
@@ -152,27 +266,29 @@ func resolveInfoAuth() (serverURL, token, username, source string) {
 	return url, "", "", "none"
 }
 
-func fillWorkspaceInfo(result *infoResult) {
+// fillWorkspaceInfo populates the workspace section for the tracked
+// workspace in the current directory and returns it (nil if none).
+func fillWorkspaceInfo(result *infoResult) *store.LocalWorkspace {
 	cwd, err := os.Getwd()
 	if err != nil {
-		return
+		return nil
 	}
 
 	// Check for pixi.toml in current directory
 	pixiPath := filepath.Join(cwd, "pixi.toml")
 	if _, err := os.Stat(pixiPath); err != nil {
-		return
+		return nil
 	}
 
 	s, err := store.New()
 	if err != nil {
-		return
+		return nil
 	}
 	defer s.Close()
 
 	ws, err := s.FindWorkspaceByPath(cwd)
 	if err != nil || ws == nil {
-		return
+		return nil
 	}
 
 	result.Workspace = ws.Name
@@ -208,6 +324,55 @@ func fillWorkspaceInfo(result *infoResult) {
 	} else {
 		result.Origin = "none"
 	}
+	return ws
+}
+
+// resolveOriginTag looks up the version a mutable origin tag currently
+// points to on the server, e.g. "v5 (sha-1a2b3c4d5e6f)". Content-hash tags
+// are immutable, so they resolve to nothing.
+func resolveOriginTag(wsName, tag string) string {
+	if tag == "" || contenthash.IsHashTag(tag) {
+		return ""
+	}
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return "unavailable (not logged in)"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ws, err := findWsByName(client, ctx, wsName)
+	if err != nil {
+		return "unavailable (workspace not found on server)"
+	}
+	tags, err := client.GetWorkspaceTags(ctx, ws.ID)
+	if err != nil {
+		return "unavailable (server unreachable)"
+	}
+	return describeTagResolution(tags, tag)
+}
+
+// describeTagResolution finds tag in tags and names the version it points
+// to, along with that version's content-hash tag when there is one.
+func describeTagResolution(tags []cliclient.WorkspaceTag, tag string) string {
+	version := -1
+	for _, t := range tags {
+		if t.Tag == tag {
+			version = t.VersionNumber
+			break
+		}
+	}
+	if version < 0 {
+		return fmt.Sprintf("tag %q no longer exists on server", tag)
+	}
+
+	for _, t := range tags {
+		if t.VersionNumber == version && contenthash.IsHashTag(t.Tag) {
+			return fmt.Sprintf("v%d (%s)", version, t.Tag)
+		}
+	}
+	return fmt.Sprintf("v%d", version)
 }
 
 func formatFeatures(features map[string]bool) string {
@@ -277,6 +442,9 @@ func printInfo(r infoResult) {
 		printField("Path", r.WorkspacePath)
 		printField("Package manager", r.PackageManager)
 		printField("Origin", r.Origin)
+		if r.OriginResolved != "" {
+			printField("Resolves to", r.OriginResolved)
+		}
 		if r.LocalEdits != "" {
 			printField("Local edits", r.LocalEdits)
 		}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/cliclient"
+)
+
+func TestParseInfoFields(t *testing.T) {
+	fields, err := parseInfoFields("server_url, username,server_url")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"server_url", "username"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("got %v, want %v", fields, want)
+	}
+
+	if fields, err := parseInfoFields(""); err != nil || fields != nil {
+		t.Errorf("empty input: got %v, %v", fields, err)
+	}
+
+	if _, err := parseInfoFields("server_url,bogus"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+
+	if _, err := parseInfoFields(" , "); err == nil {
+		t.Error("expected error for empty field list")
+	}
+}
+
+func TestInfoFieldValues(t *testing.T) {
+	values, err := infoFieldValues(infoResult{Version: "1.0", LoggedIn: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["version"] != "1.0" {
+		t.Errorf("version = %v", values["version"])
+	}
+	if values["logged_in"] != true {
+		t.Errorf("logged_in = %v", values["logged_in"])
+	}
+	// omitempty fields are still selectable
+	if v, ok := values["workspace"]; !ok || v != "" {
+		t.Errorf("workspace = %v, %v", v, ok)
+	}
+}
+
+func TestDescribeTagResolution(t *testing.T) {
+	tags := []cliclient.WorkspaceTag{
+		{Tag: "sha-0123456789ab", VersionNumber: 1},
+		{Tag: "sha-ba9876543210", VersionNumber: 2},
+		{Tag: "latest", VersionNumber: 2},
+		{Tag: "stable", VersionNumber: 1},
+		{Tag: "untracked", VersionNumber: 3},
+	}
+
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"latest", "v2 (sha-ba9876543210)"},
+		{"stable", "v1 (sha-0123456789ab)"},
+		{"untracked", "v3"},
+		{"gone", `tag "gone" no longer exists on server`},
+	}
+	for _, tt := range tests {
+		if got := describeTagResolution(tags, tt.tag); got != tt.want {
+			t.Errorf("describeTagResolution(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestResolveOriginTag_HashTagIsNoop(t *testing.T) {
+	if got := resolveOriginTag("ws", "sha-0123456789ab"); got != "" {
+		t.Errorf("expected no resolution for content-hash tag, got %q", got)
+	}
+}
@@ -3,9 +3,11 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -24,6 +26,8 @@ var (
 	loginToken         string
 	loginUsername      string
 	loginPasswordStdin bool
+	loginSSO           bool
+	loginNoVerify      bool
 
 	// oidcHTTPClient is used for all direct calls to the OIDC provider (discovery,
 	// device authorization, token polling). Separate from cliclient to avoid
@@ -40,6 +44,9 @@ Examples:
   # Default: device flow via Keycloak (opens browser, works with proxy)
   nebi login https://nebi.company.com
 
+  # Require SSO: device flow only, never fall back to a password prompt
+  nebi login https://nebi.company.com --sso
+
   # Username/password login
   nebi login https://nebi.company.com --username myuser
 
@@ -47,7 +54,15 @@ Examples:
   echo "$PASSWORD" | nebi login https://nebi.company.com --username myuser --password-stdin
 
   # Using an API token (skips interactive login)
-  nebi login https://nebi.company.com --token <api-token>`,
+  nebi login https://nebi.company.com --token <api-token>
+
+  # Save a token for a server that is not reachable from here yet
+  nebi login https://nebi.company.com --token <api-token> --no-verify
+
+Before anything is saved, the server is contacted to check that it is a
+reachable nebi server with a valid TLS certificate, and its version is
+reported. Use --no-verify to skip the check, e.g. when provisioning an
+air-gapped machine.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runLogin,
 }
@@ -56,6 +71,8 @@ func init() {
 	loginCmd.Flags().StringVar(&loginToken, "token", "", "API token (skip interactive login)")
 	loginCmd.Flags().StringVarP(&loginUsername, "username", "u", "", "Username/password login (prompts for password)")
 	loginCmd.Flags().BoolVar(&loginPasswordStdin, "password-stdin", false, "Read password from stdin (requires --username)")
+	loginCmd.Flags().BoolVar(&loginSSO, "sso", false, "Log in through the server's OIDC provider using the device flow only")
+	loginCmd.Flags().BoolVar(&loginNoVerify, "no-verify", false, "Save the server without checking that it is reachable")
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
@@ -72,6 +89,17 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	if loginPasswordStdin && loginUsername == "" {
 		return fmt.Errorf("--password-stdin requires --username")
 	}
+	if loginSSO && (loginToken != "" || loginUsername != "") {
+		return fmt.Errorf("cannot use --sso with --token or --username")
+	}
+
+	if !loginNoVerify {
+		sv, err := verifyServer(serverURL)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Connected to %s (nebi %s)\n", serverURL, sv.Version)
+	}
 
 	var token string
 	var username string
@@ -88,6 +116,14 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		}
 		token = t
 		username = u
+	} else if loginSSO {
+		// SSO mode: device flow or fail
+		t, u, err := ssoLogin(serverURL)
+		if err != nil {
+			return err
+		}
+		token = t
+		username = u
 	} else {
 		// Default: try device flow, fall back to username/password prompt
 		t, u, err := interactiveLogin(serverURL)
@@ -116,6 +152,46 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// verifyServer checks that serverURL answers as a nebi server, so that a
+// mistyped or unreachable URL fails at login instead of on first use. TLS
+// certificate problems are reported separately from connection failures.
+func verifyServer(serverURL string) (*cliclient.ServerVersion, error) {
+	if u, err := url.Parse(serverURL); err == nil && u.Scheme == "http" && !isLoopbackHost(u.Hostname()) {
+		fmt.Fprintf(os.Stderr, "Warning: %s does not use TLS; credentials will be sent unencrypted\n", serverURL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sv, err := cliclient.NewWithoutAuth(serverURL).GetServerVersion(ctx)
+	if err == nil {
+		return sv, nil
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var apiErr *cliclient.APIError
+	switch {
+	case errors.As(err, &certErr):
+		return nil, fmt.Errorf("TLS certificate of %s could not be verified: %v (use --no-verify to save it anyway)", serverURL, certErr.Err)
+	case errors.As(err, &recordErr):
+		return nil, fmt.Errorf("%s did not answer with TLS; check the URL scheme (use --no-verify to save it anyway)", serverURL)
+	case errors.As(err, &apiErr):
+		return nil, fmt.Errorf("%s does not look like a nebi server: GET /api/v1/version returned %d (use --no-verify to save it anyway)", serverURL, apiErr.StatusCode)
+	default:
+		return nil, fmt.Errorf("cannot reach %s: %v (use --no-verify to save it anyway)", serverURL, err)
+	}
+}
+
+// isLoopbackHost reports whether host refers to the local machine.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // interactiveLogin tries RFC 8628 device flow first, falls back to username/password.
 func interactiveLogin(serverURL string) (token, username string, err error) {
 	ctx := context.Background()
@@ -137,6 +213,23 @@ func interactiveLogin(serverURL string) (token, username string, err error) {
 	return deviceFlowLogin(ctx, serverURL, client, deviceCfg)
 }
 
+// ssoLogin runs the device flow without falling back to username/password,
+// for users who should only ever authenticate through the OIDC provider.
+func ssoLogin(serverURL string) (token, username string, err error) {
+	ctx := context.Background()
+	client := cliclient.NewWithoutAuth(serverURL)
+
+	deviceCfg, err := client.GetDeviceConfig(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("checking SSO support: %w", err)
+	}
+	if !deviceCfg.Enabled {
+		return "", "", fmt.Errorf("server %s does not have SSO (OIDC device flow) configured", serverURL)
+	}
+
+	return deviceFlowLogin(ctx, serverURL, client, deviceCfg)
+}
+
 // deviceFlowLogin performs OAuth2 Device Authorization Grant (RFC 8628) via Keycloak.
 func deviceFlowLogin(ctx context.Context, serverURL string, client *cliclient.Client, cfg *cliclient.DeviceConfigResponse) (token, username string, err error) {
 	// Discover the device authorization endpoint from OIDC well-known config
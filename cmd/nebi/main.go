@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -22,7 +24,11 @@ var rootCmd = &cobra.Command{
 Environment variables:
   NEBI_AUTH_TOKEN    API token for authentication (bypasses "nebi login")
   NEBI_REMOTE_URL    Remote server URL (paired with NEBI_AUTH_TOKEN)
-  NEBI_DATA_DIR      Override the local data directory (default: ~/.local/share/nebi)`,
+  NEBI_DATA_DIR      Override the local data directory (default: ~/.local/share/nebi)
+  NEBI_DIFF_EXIT_ON_CHANGE
+                     "false" makes 'nebi diff --stat-only-exit' exit 0 on differences
+  NEBI_ADVISORY_URL  OSV-compatible advisory API for 'nebi diff --highlight-security' (default: https://api.osv.dev)
+  PAGER              Pager for long output on a terminal (default: less -FRX; "cat" disables)`,
 	Example: `  # Track a workspace and push it to a server
   nebi init
   nebi login https://nebi.company.com
@@ -52,12 +58,16 @@ func init() {
 	diffCmd.GroupID = "sync"
 	publishCmd.GroupID = "sync"
 	importCmd.GroupID = "sync"
+	solveCmd.GroupID = "sync"
 
 	loginCmd.GroupID = "connection"
 	logoutCmd.GroupID = "connection"
 	registryCmd.GroupID = "connection"
 
 	serveCmd.GroupID = "admin"
+	adminCmd.GroupID = "admin"
+
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "Do not pipe long output through $PAGER")
 
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(statusCmd)
@@ -71,15 +81,37 @@ func init() {
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(publishCmd)
 	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(solveCmd)
 	rootCmd.AddCommand(registryCmd)
 	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(adminCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(completionCmd)
 	rootCmd.AddCommand(infoCmd)
 }
 
+// exitCodeError makes the process exit with a specific code. Commands that
+// report their result through the exit code return it instead of printing.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
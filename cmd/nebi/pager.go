@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defaultPager is used when $PAGER is unset. -F quits when the output fits
+// on one screen, -R passes color escapes through and -X leaves the output
+// on the terminal after quitting.
+const defaultPager = "less -FRX"
+
+// noPager disables paging for every command (--no-pager).
+var noPager bool
+
+// pagedOutput buffers a command's output and, on Close, shows it through
+// the user's pager when stdout is a terminal and the output is taller than
+// it. Otherwise, and always with --no-pager, the output goes straight to
+// stdout. Commands with machine-readable output (--json) should write to
+// stdout directly instead.
+type pagedOutput struct {
+	buf bytes.Buffer
+}
+
+func newPagedOutput() *pagedOutput {
+	return &pagedOutput{}
+}
+
+func (p *pagedOutput) Write(b []byte) (int, error) {
+	return p.buf.Write(b)
+}
+
+// Close writes the buffered output, through the pager if it should be paged.
+func (p *pagedOutput) Close() error {
+	fd := int(os.Stdout.Fd())
+	if noPager || !term.IsTerminal(fd) {
+		_, err := os.Stdout.Write(p.buf.Bytes())
+		return err
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil || !exceedsHeight(p.buf.Bytes(), height) {
+		_, err := os.Stdout.Write(p.buf.Bytes())
+		return err
+	}
+
+	pager := pagerCommand(os.LookupEnv("PAGER"))
+	if pager == "" {
+		_, err := os.Stdout.Write(p.buf.Bytes())
+		return err
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", pager)
+	} else {
+		cmd = exec.Command("sh", "-c", pager)
+	}
+	cmd.Stdin = bytes.NewReader(p.buf.Bytes())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Like git, give less its paging flags when the user has not set any.
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	// A pager that cannot be started must not swallow the output; sh
+	// reports a missing command as exit status 127. Any other pager exit
+	// (e.g. quitting less early) is not an error of the command.
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() == 127) {
+		_, err := os.Stdout.Write(p.buf.Bytes())
+		return err
+	}
+	return nil
+}
+
+// pagerCommand returns the pager to run given $PAGER and whether it is
+// set. An unset $PAGER means defaultPager; an empty one or "cat" disables
+// paging.
+func pagerCommand(env string, set bool) string {
+	if !set {
+		return defaultPager
+	}
+	env = strings.TrimSpace(env)
+	if env == "cat" {
+		return ""
+	}
+	return env
+}
+
+// exceedsHeight reports whether out has more lines than a terminal of the
+// given height can show at once.
+func exceedsHeight(out []byte, height int) bool {
+	return height > 0 && bytes.Count(out, []byte("\n")) >= height
+}
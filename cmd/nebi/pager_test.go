@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		env  string
+		set  bool
+		want string
+	}{
+		{"", false, defaultPager},
+		{"", true, ""},
+		{"cat", true, ""},
+		{" more ", true, "more"},
+		{"less -S", true, "less -S"},
+	}
+	for _, tt := range tests {
+		if got := pagerCommand(tt.env, tt.set); got != tt.want {
+			t.Errorf("pagerCommand(%q, %v) = %q, want %q", tt.env, tt.set, got, tt.want)
+		}
+	}
+}
+
+func TestExceedsHeight(t *testing.T) {
+	out := []byte("a\nb\nc\n")
+	if exceedsHeight(out, 4) {
+		t.Error("3 lines fit a 4-line terminal")
+	}
+	if !exceedsHeight(out, 3) {
+		t.Error("3 lines leave no room for the prompt on a 3-line terminal")
+	}
+	if exceedsHeight(out, 0) {
+		t.Error("an unknown height never pages")
+	}
+}
@@ -3,17 +3,26 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
 	"github.com/nebari-dev/nebi/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var pullOutput string
 var pullForce bool
+var pullHook string
+var pullSaveHook bool
+var pullNoHook bool
+var pullManifestOnly bool
 
 var pullCmd = &cobra.Command{
 	Use:   "pull [<workspace>[:<tag>]]",
@@ -30,10 +39,31 @@ in the pulled pixi.toml, not from the server workspace name.
 
 Use --force to skip the overwrite confirmation prompt.
 
+If the output directory already holds part of the requested version (for
+example an interrupted pull left pixi.toml but no pixi.lock), only the
+missing or out-of-date files are re-fetched. Files edited since the last
+pull are never replaced without confirmation, and a directory that
+already matches the requested version is left as it is unless --force is
+given.
+
+Use --manifest-only to write just pixi.toml, e.g. when the lock is solved
+locally per machine. Any existing pixi.lock is left untouched and keeps
+its recorded origin digest, so it may no longer match the manifest; run
+'pixi lock' to re-solve it.
+
+Use --hook <cmd> to run a shell command in the output directory after a
+successful pull, e.g. "pixi install". The command sees NEBI_WORKSPACE,
+NEBI_TAG, NEBI_VERSION and NEBI_WORKSPACE_PATH in its environment. A
+failing hook fails the pull with the hook's exit code. --save-hook
+remembers the hook as the default for the output directory (an empty
+--hook clears it); --no-hook skips the default for one pull.
+
 Examples:
   nebi pull myworkspace:v1.0
   nebi pull                                # re-pull from origin
-  nebi pull myworkspace -o ./my-project`,
+  nebi pull myworkspace -o ./my-project
+  nebi pull myworkspace:v2 --manifest-only   # pixi.toml only
+  nebi pull --hook "pixi install" --save-hook`,
 	Args:              cobra.RangeArgs(0, 1),
 	RunE:              runPull,
 	ValidArgsFunction: completeServerWorkspaceRef,
@@ -42,9 +72,17 @@ Examples:
 func init() {
 	pullCmd.Flags().StringVarP(&pullOutput, "output", "o", ".", "Output directory")
 	pullCmd.Flags().BoolVar(&pullForce, "force", false, "Overwrite existing files without prompting")
+	pullCmd.Flags().StringVar(&pullHook, "hook", "", "Shell command to run in the output directory after a successful pull")
+	pullCmd.Flags().BoolVar(&pullSaveHook, "save-hook", false, "Remember --hook as the default post-pull hook for the output directory")
+	pullCmd.Flags().BoolVar(&pullNoHook, "no-hook", false, "Don't run the saved post-pull hook")
+	pullCmd.Flags().BoolVar(&pullManifestOnly, "manifest-only", false, "Write only pixi.toml, leaving any existing pixi.lock untouched")
 }
 
 func runPull(cmd *cobra.Command, args []string) error {
+	if pullNoHook && (pullHook != "" || pullSaveHook) {
+		return fmt.Errorf("--no-hook cannot be combined with --hook or --save-hook")
+	}
+
 	var wsName, tag string
 	if len(args) == 1 {
 		wsName, tag = parseWsRef(args[0])
@@ -123,14 +161,17 @@ func runPull(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	pixiToml, err := client.GetVersionPixiToml(ctx, ws.ID, versionNumber)
+	pixiToml, err := client.PullVersionPixiToml(ctx, ws.ID, versionNumber)
 	if err != nil {
 		return fmt.Errorf("failed to get pixi.toml: %w", err)
 	}
 
-	pixiLock, err := client.GetVersionPixiLock(ctx, ws.ID, versionNumber)
-	if err != nil {
-		return fmt.Errorf("failed to get pixi.lock: %w", err)
+	var pixiLock string
+	if !pullManifestOnly {
+		pixiLock, err = client.GetVersionPixiLock(ctx, ws.ID, versionNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get pixi.lock: %w", err)
+		}
 	}
 
 	// Check for upstream changes
@@ -145,14 +186,41 @@ func runPull(cmd *cobra.Command, args []string) error {
 	}
 
 	outputDir := pullOutput
-	if !pullForce {
-		absDir, _ := filepath.Abs(outputDir)
-		existing := filepath.Join(absDir, "pixi.toml")
-		if _, statErr := os.Stat(existing); statErr == nil {
-			if !confirmOverwrite(absDir) {
-				fmt.Fprintln(os.Stderr, "Aborted.")
-				return nil
-			}
+	absDir, _ := filepath.Abs(outputDir)
+
+	refStr := wsName
+	if tag != "" {
+		refStr = wsName + ":" + tag
+	}
+
+	// Compare what is already on disk with the requested version and with
+	// the digests recorded by the last pull, so an interrupted pull is
+	// repaired instead of re-prompting for a full overwrite while local
+	// edits still need confirmation.
+	plan := planPull(absDir, pixiToml, pixiLock, pulledOrigin(absDir))
+	writeToml, writeLock := true, pixiLock != ""
+	switch {
+	case pullManifestOnly:
+		if !pullForce && plan.toml.exists() && plan.toml != fileMatches && !confirmOverwrite(absDir) {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			return nil
+		}
+	case plan.upToDate() && !pullForce:
+		fmt.Fprintf(os.Stderr, "Already up to date: %s (version %d) in %s\n", refStr, versionNumber, absDir)
+		if _, err := recordPull(absDir, ws.ID, wsName, tag, int(versionNumber), pixiToml, pixiLock, false); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record pull: %v\n", err)
+		}
+		return nil
+	case plan.interrupted():
+		writeToml, writeLock = plan.toml != fileMatches, plan.lock != fileMatches
+		fmt.Fprintf(os.Stderr, "Detected an incomplete checkout (%s); re-fetching\n", plan.describe())
+	case !pullForce && (plan.toml.exists() || plan.lock == fileModified):
+		if plan.lock == fileModified {
+			fmt.Fprintf(os.Stderr, "pixi.lock in %s has local changes\n", absDir)
+		}
+		if !confirmOverwrite(absDir) {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			return nil
 		}
 	}
 
@@ -160,11 +228,13 @@ func runPull(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	if err := os.WriteFile(filepath.Join(outputDir, "pixi.toml"), []byte(pixiToml), 0644); err != nil {
-		return fmt.Errorf("failed to write pixi.toml: %w", err)
+	if writeToml {
+		if err := os.WriteFile(filepath.Join(outputDir, "pixi.toml"), []byte(pixiToml), 0644); err != nil {
+			return fmt.Errorf("failed to write pixi.toml: %w", err)
+		}
 	}
 
-	if pixiLock != "" {
+	if writeLock {
 		if err := os.WriteFile(filepath.Join(outputDir, "pixi.lock"), []byte(pixiLock), 0644); err != nil {
 			return fmt.Errorf("failed to write pixi.lock: %w", err)
 		}
@@ -172,25 +242,269 @@ func runPull(cmd *cobra.Command, args []string) error {
 
 	absOutput, _ := filepath.Abs(outputDir)
 
-	// Auto-track the workspace (name will be read from pulled pixi.toml)
-	if err := ensureInit(outputDir); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to auto-track workspace: %v\n", err)
+	fmt.Fprintf(os.Stderr, "Pulled %s (version %d, id=%s) -> %s\n", refStr, versionNumber, ws.ID, absOutput)
+
+	// With --manifest-only the local lock (if any) stays as it was, and the
+	// snapshot recorded below holds it alongside the new manifest.
+	if pullManifestOnly {
+		local, err := os.ReadFile(filepath.Join(outputDir, "pixi.lock"))
+		if err == nil {
+			pixiLock = string(local)
+			fmt.Fprintln(os.Stderr, "Warning: pixi.lock was left unchanged and may not match the pulled pixi.toml; run 'pixi lock' to re-solve it")
+		} else {
+			fmt.Fprintln(os.Stderr, "No pixi.lock in the output directory; run 'pixi lock' to solve one")
+		}
 	}
 
-	refStr := wsName
-	if tag != "" {
-		refStr = wsName + ":" + tag
+	// Track the directory and record its origin in one step. If this fails
+	// the files on disk are still correct; pulling again repairs the index.
+	tracked, err := recordPull(absOutput, ws.ID, wsName, tag, int(versionNumber), pixiToml, pixiLock, pullManifestOnly)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record pull: %v\n", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "Pulled %s (version %d, id=%s) -> %s\n", refStr, versionNumber, ws.ID, absOutput)
+	hook := pullHook
+	if pullSaveHook {
+		if tracked == nil {
+			return fmt.Errorf("cannot save hook: pull was not recorded")
+		}
+		if err := savePullHook(tracked, pullHook); err != nil {
+			return err
+		}
+	} else if hook == "" && !pullNoHook && tracked != nil {
+		hook = tracked.PullHook
+	}
+	if hook == "" || pullNoHook {
+		return nil
+	}
+
+	return runPullHook(hook, absOutput, []string{
+		"NEBI_WORKSPACE=" + wsName,
+		"NEBI_TAG=" + tag,
+		"NEBI_VERSION=" + strconv.Itoa(int(versionNumber)),
+		"NEBI_WORKSPACE_PATH=" + absOutput,
+	})
+}
+
+// savePullHook stores hook as the default post-pull hook of ws. An empty
+// hook clears it.
+func savePullHook(ws *store.LocalWorkspace, hook string) error {
+	s, err := store.New()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
 
-	if saveErr := saveOrigin(ws.ID, wsName, tag, "pull", pixiToml, pixiLock); saveErr != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to save origin: %v\n", saveErr)
+	ws.PullHook = hook
+	if err := s.SaveWorkspace(ws); err != nil {
+		return fmt.Errorf("failed to save hook: %w", err)
 	}
+	if hook == "" {
+		fmt.Fprintf(os.Stderr, "Cleared post-pull hook for %s\n", ws.Path)
+	} else {
+		fmt.Fprintf(os.Stderr, "Saved post-pull hook for %s\n", ws.Path)
+	}
+	return nil
+}
+
+// runPullHook runs hook through the system shell in dir with env added to
+// the current environment. A non-zero exit is returned as an exitCodeError
+// carrying the hook's exit code.
+func runPullHook(hook, dir string, env []string) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", hook)
+	} else {
+		c = exec.Command("sh", "-c", hook)
+	}
+	c.Dir = dir
+	c.Env = append(os.Environ(), env...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
 
+	fmt.Fprintf(os.Stderr, "Running hook: %s\n", hook)
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &exitCodeError{
+				code: exitErr.ExitCode(),
+				err:  fmt.Errorf("hook exited with code %d", exitErr.ExitCode()),
+			}
+		}
+		return fmt.Errorf("failed to start hook: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, "Hook completed successfully")
 	return nil
 }
 
+// recordPull tracks dir (if needed) and saves the pulled origin on it with a
+// single transactional store write. The workspace name for a newly tracked
+// directory is read from the pulled pixi.toml. With manifestOnly, the
+// recorded origin digest of pixi.lock is kept as it was. Returns the stored
+// workspace.
+func recordPull(dir, remoteID, name, tag string, version int, tomlContent, lockContent string, manifestOnly bool) (*store.LocalWorkspace, error) {
+	wsName, err := pixi.ExtractWorkspaceName(tomlContent)
+	if err != nil {
+		return nil, err
+	}
+	tomlHash, err := store.TomlContentHash(tomlContent)
+	if err != nil {
+		return nil, fmt.Errorf("hashing pixi.toml: %w", err)
+	}
+
+	s, err := store.New()
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	existing, err := s.FindWorkspaceByPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Pulled %s:%s (version %d)", name, tag, version)
+	if tag == "" {
+		description = fmt.Sprintf("Pulled %s (version %d)", name, version)
+	}
+	lockHash := store.ContentHash(lockContent)
+	if manifestOnly {
+		description += ", pixi.toml only"
+		lockHash = ""
+		if existing != nil {
+			lockHash = existing.OriginLockHash
+		}
+	}
+
+	saved, err := s.RecordPull(&store.LocalWorkspace{
+		Name:           wsName,
+		Path:           dir,
+		OriginID:       remoteID,
+		OriginName:     name,
+		OriginTag:      tag,
+		OriginAction:   "pull",
+		OriginVersion:  version,
+		OriginTomlHash: tomlHash,
+		OriginLockHash: lockHash,
+	}, tomlContent, lockContent, description)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		fmt.Fprintf(os.Stderr, "Tracking workspace '%s' at %s\n", wsName, dir)
+	}
+	return saved, nil
+}
+
+// fileState describes a local spec file relative to the version being pulled.
+type fileState int
+
+const (
+	fileMissing  fileState = iota
+	fileMatches            // same content as the version being pulled
+	fileStale              // unchanged since the last recorded pull
+	fileModified           // matches neither, e.g. edited locally
+)
+
+func (f fileState) exists() bool {
+	return f != fileMissing
+}
+
+// pullPlan records the state of pixi.toml and pixi.lock in the output
+// directory relative to the version being pulled.
+type pullPlan struct {
+	toml fileState
+	lock fileState
+}
+
+// pulledOrigin returns the tracked workspace at dir, whose origin digests
+// record what the last pull wrote there, or nil if dir is not tracked.
+func pulledOrigin(dir string) *store.LocalWorkspace {
+	s, err := store.New()
+	if err != nil {
+		return nil
+	}
+	defer s.Close()
+
+	ws, err := s.FindWorkspaceByPath(dir)
+	if err != nil {
+		return nil
+	}
+	return ws
+}
+
+// planPull compares the files in dir against the pulled content and
+// against the origin digests of the last pull (origin may be nil), using
+// the same digests that origin tracking uses. A file matching neither has
+// been changed locally. A version with no lock file treats an absent local
+// pixi.lock as matching.
+func planPull(dir, pixiToml, pixiLock string, origin *store.LocalWorkspace) pullPlan {
+	var plan pullPlan
+
+	if local, err := os.ReadFile(filepath.Join(dir, "pixi.toml")); err == nil {
+		plan.toml = fileModified
+		if localHash, err := store.TomlContentHash(string(local)); err == nil {
+			remoteHash, remoteErr := store.TomlContentHash(pixiToml)
+			switch {
+			case remoteErr == nil && localHash == remoteHash:
+				plan.toml = fileMatches
+			case origin != nil && localHash == origin.OriginTomlHash:
+				plan.toml = fileStale
+			}
+		}
+	}
+
+	if local, err := os.ReadFile(filepath.Join(dir, "pixi.lock")); err == nil {
+		plan.lock = fileModified
+		localHash := store.ContentHash(string(local))
+		switch {
+		case localHash == store.ContentHash(pixiLock):
+			plan.lock = fileMatches
+		case origin != nil && localHash == origin.OriginLockHash:
+			plan.lock = fileStale
+		}
+	} else if pixiLock == "" {
+		plan.lock = fileMatches
+	}
+
+	return plan
+}
+
+func (p pullPlan) upToDate() bool {
+	return p.toml == fileMatches && p.lock == fileMatches
+}
+
+// interrupted reports whether one file already matches the pulled version
+// while the other is missing or still as the last pull left it, i.e. a
+// checkout that stopped part way through. Re-fetching the rest loses no
+// local edits.
+func (p pullPlan) interrupted() bool {
+	if p.upToDate() || p.toml == fileModified || p.lock == fileModified {
+		return false
+	}
+	return p.toml == fileMatches || p.lock == fileMatches
+}
+
+func (p pullPlan) describe() string {
+	var parts []string
+	for _, f := range []struct {
+		name  string
+		state fileState
+	}{{"pixi.toml", p.toml}, {"pixi.lock", p.lock}} {
+		switch f.state {
+		case fileMissing:
+			parts = append(parts, f.name+" missing")
+		case fileStale:
+			parts = append(parts, f.name+" from the previous pull")
+		case fileModified:
+			parts = append(parts, f.name+" modified")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 func confirmOverwrite(dir string) bool {
 	fmt.Fprintf(os.Stderr, "pixi.toml already exists in %s. Overwrite? [y/N] ", dir)
 	reader := bufio.NewReader(os.Stdin)
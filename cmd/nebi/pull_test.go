@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/store"
+)
+
+func TestPlanPull(t *testing.T) {
+	const toml = "[workspace]\nname = \"demo\"\n"
+	const lock = "version: 6\n"
+	const oldToml = "[workspace]\nname = \"demo\"\nversion = \"0.1\"\n"
+	const oldLock = "version: 5\n"
+
+	oldTomlHash, err := store.TomlContentHash(oldToml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin := &store.LocalWorkspace{OriginTomlHash: oldTomlHash, OriginLockHash: store.ContentHash(oldLock)}
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		lock        string
+		origin      *store.LocalWorkspace
+		want        pullPlan
+		interrupted bool
+	}{
+		{
+			name: "empty dir",
+			lock: lock,
+			want: pullPlan{toml: fileMissing, lock: fileMissing},
+		},
+		{
+			name:        "lock missing",
+			files:       map[string]string{"pixi.toml": toml},
+			lock:        lock,
+			want:        pullPlan{toml: fileMatches, lock: fileMissing},
+			interrupted: true,
+		},
+		{
+			name:        "lock from previous pull",
+			files:       map[string]string{"pixi.toml": toml, "pixi.lock": oldLock},
+			lock:        lock,
+			origin:      origin,
+			want:        pullPlan{toml: fileMatches, lock: fileStale},
+			interrupted: true,
+		},
+		{
+			name:   "lock edited locally",
+			files:  map[string]string{"pixi.toml": toml, "pixi.lock": "version: 4\n"},
+			lock:   lock,
+			origin: origin,
+			want:   pullPlan{toml: fileMatches, lock: fileModified},
+		},
+		{
+			name:  "lock differs without origin",
+			files: map[string]string{"pixi.toml": toml, "pixi.lock": oldLock},
+			lock:  lock,
+			want:  pullPlan{toml: fileMatches, lock: fileModified},
+		},
+		{
+			name:   "manifest edited locally",
+			files:  map[string]string{"pixi.toml": "[workspace]\nname = \"edited\"\n", "pixi.lock": lock},
+			lock:   lock,
+			origin: origin,
+			want:   pullPlan{toml: fileModified, lock: fileMatches},
+		},
+		{
+			name:   "previous pull",
+			files:  map[string]string{"pixi.toml": oldToml, "pixi.lock": oldLock},
+			lock:   lock,
+			origin: origin,
+			want:   pullPlan{toml: fileStale, lock: fileStale},
+		},
+		{
+			name:  "complete",
+			files: map[string]string{"pixi.toml": toml, "pixi.lock": lock},
+			lock:  lock,
+			want:  pullPlan{toml: fileMatches, lock: fileMatches},
+		},
+		{
+			name:  "version without lock",
+			files: map[string]string{"pixi.toml": toml},
+			want:  pullPlan{toml: fileMatches, lock: fileMatches},
+		},
+		{
+			name:  "unrelated files",
+			files: map[string]string{"pixi.toml": "[workspace]\nname = \"other\"\n", "pixi.lock": oldLock},
+			lock:  lock,
+			want:  pullPlan{toml: fileModified, lock: fileModified},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := planPull(dir, toml, tt.lock, tt.origin)
+			if got != tt.want {
+				t.Errorf("planPull = %+v, want %+v", got, tt.want)
+			}
+			if got.interrupted() != tt.interrupted {
+				t.Errorf("interrupted() = %v, want %v", got.interrupted(), tt.interrupted)
+			}
+		})
+	}
+}
@@ -101,6 +101,11 @@ func runPush(cmd *cobra.Command, args []string) error {
 			PixiToml:       &pixiTomlStr,
 		})
 		if createErr != nil {
+			// With workspaces.allow_push_autocreate off, the server only
+			// lets administrators create workspaces.
+			if apiErr, ok := createErr.(*cliclient.APIError); ok && apiErr.StatusCode == 404 {
+				return fmt.Errorf("cannot push: %s", apiErr.Message())
+			}
 			return fmt.Errorf("failed to create workspace %q: %w", wsName, createErr)
 		}
 		// Wait for workspace to be ready (server runs pixi install)
@@ -126,6 +131,11 @@ func runPush(cmd *cobra.Command, args []string) error {
 	fmt.Fprintf(os.Stderr, "Pushing %s...\n", pushLabel)
 	resp, err := client.PushVersion(ctx, ws.ID, req)
 	if err != nil {
+		// Tag conflicts and the server's workspaces.max_tags limit come
+		// back as 409 with a message that says what to do.
+		if apiErr, ok := err.(*cliclient.APIError); ok && apiErr.StatusCode == 409 {
+			return fmt.Errorf("cannot push %s: %s", pushLabel, apiErr.Message())
+		}
 		return fmt.Errorf("failed to push %s: %w", pushLabel, err)
 	}
 
@@ -140,6 +150,9 @@ func runPush(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Pushed %s (version %d, tags: %s)\n",
 			wsName, resp.VersionNumber, strings.Join(resp.Tags, ", "))
 	}
+	if len(resp.EvictedTags) > 0 && !pushJSON {
+		fmt.Fprintf(os.Stderr, "Removed oldest tag(s) %s to stay within the server's tag limit\n", strings.Join(resp.EvictedTags, ", "))
+	}
 
 	// Auto-track the workspace so status and origin tracking work
 	if err := ensureInit("."); err != nil {
@@ -151,7 +164,7 @@ func runPush(cmd *cobra.Command, args []string) error {
 	if originTag == "" {
 		originTag = resp.ContentHash
 	}
-	if saveErr := saveOrigin(ws.ID, wsName, originTag, "push", string(pixiToml), string(pixiLock)); saveErr != nil {
+	if saveErr := saveOrigin(ws.ID, wsName, originTag, "push", int(resp.VersionNumber), string(pixiToml), string(pixiLock)); saveErr != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to save origin: %v\n", saveErr)
 	}
 
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
+	"github.com/spf13/cobra"
+)
+
+var solvePlatforms []string
+
+var solveCmd = &cobra.Command{
+	Use:   "solve <workspace>",
+	Short: "Re-solve a server workspace's pixi.lock",
+	Long: `Run a server-side solve (pixi lock) of a workspace's current pixi.toml and
+stream the job's logs.
+
+Without --platform the solve targets the workspace's default platforms: the
+ones set on the workspace, or the platforms declared in its pixi.toml. With
+--platform the solve targets exactly the given platforms for this solve
+only; the workspace's pixi.toml and default platforms are left unchanged.
+
+Examples:
+  nebi solve data-science
+  nebi solve data-science --platform linux-64 --platform osx-arm64`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runSolve,
+	ValidArgsFunction: completeServerWorkspaceNames,
+}
+
+func init() {
+	solveCmd.Flags().StringArrayVar(&solvePlatforms, "platform", nil, "Platform to solve for instead of the workspace defaults (repeatable)")
+}
+
+func runSolve(cmd *cobra.Command, args []string) error {
+	wsName := args[0]
+	if err := pixi.ValidatePlatforms(solvePlatforms); err != nil {
+		return err
+	}
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	ws, err := findWsByName(client, ctx, wsName)
+	if err != nil {
+		return err
+	}
+
+	target := strings.Join(solvePlatforms, ", ")
+	if len(solvePlatforms) == 0 {
+		defaults, err := client.GetDefaultPlatforms(ctx, ws.ID)
+		if err != nil {
+			return fmt.Errorf("getting default platforms: %w", err)
+		}
+		target = fmt.Sprintf("%s (from %s)", strings.Join(defaults.Platforms, ", "), defaults.Source)
+	}
+
+	job, err := client.SolveWorkspace(ctx, ws.ID, solvePlatforms)
+	if err != nil {
+		return fmt.Errorf("starting solve: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Started solve job %s for workspace %q: %s\n", job.ID, wsName, target)
+
+	if err := followJob(ctx, client, job, "solve"); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Workspace %q solved successfully\n", wsName)
+	return nil
+}
@@ -13,12 +13,16 @@ import (
 )
 
 var statusJSON bool
+var statusOffline bool
 
 func init() {
 	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Output as JSON")
+	statusCmd.Flags().BoolVar(&statusOffline, "offline", false, "Don't contact the server; report local changes only")
 }
 
 type statusResult struct {
+	SchemaVersion int `json:"schema_version"`
+
 	Workspace    string `json:"workspace"`
 	Path         string `json:"path"`
 	Server       string `json:"server,omitempty"`
@@ -28,6 +32,8 @@ type statusResult struct {
 	TomlModified bool   `json:"toml_modified"`
 	LockModified bool   `json:"lock_modified"`
 	ServerSync   string `json:"server_sync,omitempty"`
+	Ahead        *int   `json:"ahead,omitempty"`
+	Behind       *int   `json:"behind,omitempty"`
 }
 
 var statusCmd = &cobra.Command{
@@ -39,10 +45,14 @@ Displays the workspace name, path, and origin info for the
 last push/pull operation.
 
 If the server is reachable, checks whether the local files or server version
-have changed since the last sync.
+have changed since the last sync, and prints a git-like "behind N / ahead M"
+line. Behind counts the server versions newer than the one last pushed or
+pulled; ahead is 1 when the local files have unpushed changes (nebi does
+not record local commits to count). Use --offline to skip the server.
 
 Examples:
-  nebi status`,
+  nebi status
+  nebi status --offline`,
 	Args: cobra.NoArgs,
 	RunE: runStatus,
 }
@@ -107,21 +117,35 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	fmt.Fprintln(os.Stdout)
 
-	if ws.OriginTomlHash != "" && ws.OriginTomlHash != localTomlHash {
+	tomlModified := ws.OriginTomlHash != "" && ws.OriginTomlHash != localTomlHash
+	lockModified := ws.OriginLockHash != "" && ws.OriginLockHash != localLockHash
+	if tomlModified {
 		fmt.Fprintln(os.Stdout, "pixi.toml modified locally")
 	}
-	if ws.OriginLockHash != "" && ws.OriginLockHash != localLockHash {
+	if lockModified {
 		fmt.Fprintln(os.Stdout, "pixi.lock modified locally")
 	}
+	ahead := 0
+	if tomlModified || lockModified {
+		ahead = 1
+	}
 
 	fmt.Fprintln(os.Stdout, "\nOrigin:")
 	fmt.Fprintf(os.Stdout, "  %s:%s (%s)\n", ws.OriginName, ws.OriginTag, ws.OriginAction)
 
+	if statusOffline {
+		fmt.Fprintf(os.Stdout, "  ahead %d (offline; server not checked)\n", ahead)
+		return nil
+	}
+
 	if serverURL != "" {
 		serverStatus := checkServerOrigin(s, serverURL, ws)
 		if serverStatus != "" {
 			fmt.Fprintf(os.Stdout, "  %s\n", serverStatus)
 		}
+		if behind, ok := checkServerBehind(s, serverURL, ws); ok {
+			fmt.Fprintf(os.Stdout, "  behind %d / ahead %d\n", behind, ahead)
+		}
 	}
 
 	return nil
@@ -129,12 +153,13 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 func runStatusJSON(s *store.Store, ws *store.LocalWorkspace, serverURL, cwd string) error {
 	result := statusResult{
-		Workspace:    ws.Name,
-		Path:         ws.Path,
-		Server:       serverURL,
-		OriginName:   ws.OriginName,
-		OriginTag:    ws.OriginTag,
-		OriginAction: ws.OriginAction,
+		SchemaVersion: jsonSchemaVersion,
+		Workspace:     ws.Name,
+		Path:          ws.Path,
+		Server:        serverURL,
+		OriginName:    ws.OriginName,
+		OriginTag:     ws.OriginTag,
+		OriginAction:  ws.OriginAction,
 	}
 
 	if ws.OriginName == "" {
@@ -153,13 +178,57 @@ func runStatusJSON(s *store.Store, ws *store.LocalWorkspace, serverURL, cwd stri
 	result.TomlModified = ws.OriginTomlHash != "" && ws.OriginTomlHash != localTomlHash
 	result.LockModified = ws.OriginLockHash != "" && ws.OriginLockHash != localLockHash
 
-	if serverURL != "" {
+	ahead := 0
+	if result.TomlModified || result.LockModified {
+		ahead = 1
+	}
+	result.Ahead = &ahead
+
+	if serverURL != "" && !statusOffline {
 		result.ServerSync = checkServerOriginStatus(s, serverURL, ws)
+		if behind, ok := checkServerBehind(s, serverURL, ws); ok {
+			result.Behind = &behind
+		}
 	}
 
 	return writeJSON(result)
 }
 
+// checkServerBehind returns how many server versions of the origin
+// workspace are newer than the version recorded by the last push or pull.
+// ok is false when the server cannot be asked or the origin was recorded by
+// a nebi that did not store version numbers.
+func checkServerBehind(s *store.Store, serverURL string, ws *store.LocalWorkspace) (behind int, ok bool) {
+	if ws.OriginVersion == 0 {
+		return 0, false
+	}
+	creds, err := s.LoadCredentials()
+	if err != nil || creds.Token == "" {
+		return 0, false
+	}
+
+	client := cliclient.New(serverURL, creds.Token)
+	ctx := context.Background()
+
+	wsID := ws.OriginID
+	if wsID == "" {
+		serverWs, err := findWsByName(client, ctx, ws.OriginName)
+		if err != nil {
+			return 0, false
+		}
+		wsID = serverWs.ID
+	}
+
+	newest, _, err := client.GetWorkspaceVersionsPage(ctx, wsID, 1, 0)
+	if err != nil || len(newest) == 0 {
+		return 0, false
+	}
+	if n := int(newest[0].VersionNumber) - ws.OriginVersion; n > 0 {
+		return n, true
+	}
+	return 0, true
+}
+
 func checkServerOriginStatus(s *store.Store, serverURL string, ws *store.LocalWorkspace) string {
 	creds, err := s.LoadCredentials()
 	if err != nil || creds.Token == "" {
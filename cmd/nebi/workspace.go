@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/nebari-dev/nebi/internal/cliclient"
 	"github.com/nebari-dev/nebi/internal/store"
@@ -83,7 +85,10 @@ Examples:
 	ValidArgsFunction: completeServerWorkspaceNames,
 }
 
-var wsRemoveRemote bool
+var (
+	wsRemoveRemote bool
+	wsRemoveYes    bool
+)
 
 var workspaceRemoveCmd = &cobra.Command{
 	Use:     "remove [name|path]",
@@ -96,19 +101,42 @@ By default removes from the local index:
   - Only the tracking entry is removed; project files are untouched.
   - A bare name looks up a workspace by name; use a path (with a slash) for a path-based lookup.
 
-With --remote, deletes the workspace from the configured server.
+With --remote, deletes the workspace from the configured server. This also
+deletes all of its versions, tags and publications, so nebi first shows what
+will be removed and asks for confirmation. Pass --yes to skip the prompt.
 
 Examples:
   nebi workspace remove                     # remove workspace in current directory
   nebi workspace remove .                   # same as above
   nebi workspace remove data-science        # remove workspace by name
   nebi workspace remove ./my-project        # remove workspace by path
-  nebi workspace remove myenv --remote      # delete workspace from server`,
+  nebi workspace remove myenv --remote      # delete workspace from server
+  nebi workspace remove myenv --remote --yes  # ... without confirmation`,
 	Args:              cobra.MaximumNArgs(1),
 	RunE:              runWorkspaceRemove,
 	ValidArgsFunction: completeWorkspaceRemove,
 }
 
+var wsForkHistory bool
+
+var workspaceForkCmd = &cobra.Command{
+	Use:   "fork <source> <new-name>",
+	Short: "Copy a server workspace into a new one you own",
+	Long: `Create a new server workspace owned by you from the latest version of an
+existing one. The source workspace is not changed, so the fork can be used
+to experiment from a shared baseline.
+
+By default only the latest content is copied. With --history, the source's
+earlier versions and their tags are copied too.
+
+Examples:
+  nebi workspace fork data-science my-data-science
+  nebi workspace fork data-science my-data-science --history`,
+	Args:              cobra.ExactArgs(2),
+	RunE:              runWorkspaceFork,
+	ValidArgsFunction: completeServerWorkspaceNames,
+}
+
 var workspacePruneCmd = &cobra.Command{
 	Use:   "prune",
 	Short: "Remove workspaces whose paths no longer exist",
@@ -132,8 +160,11 @@ func init() {
 	workspaceTagsCmd.Flags().BoolVar(&wsTagsJSON, "json", false, "Output as JSON")
 	workspaceCmd.AddCommand(workspaceTagsCmd)
 	workspaceRemoveCmd.Flags().BoolVarP(&wsRemoveRemote, "remote", "r", false, "Remove workspace from the server instead of locally")
+	workspaceRemoveCmd.Flags().BoolVarP(&wsRemoveYes, "yes", "y", false, "Skip the confirmation prompt for --remote")
 	workspaceCmd.AddCommand(workspaceRemoveCmd)
 	workspaceCmd.AddCommand(workspacePruneCmd)
+	workspaceForkCmd.Flags().BoolVar(&wsForkHistory, "history", false, "Also copy the source's earlier versions and tags")
+	workspaceCmd.AddCommand(workspaceForkCmd)
 }
 
 func runWorkspaceList(cmd *cobra.Command, args []string) error {
@@ -178,6 +209,17 @@ func runWorkspaceEnvJob(wsName, action string) error {
 
 	fmt.Fprintf(os.Stderr, "Started %s job %s for workspace %q\n", action, job.ID, wsName)
 
+	if err := followJob(ctx, client, job, action); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Workspace %q %sed successfully\n", wsName, action)
+	return nil
+}
+
+// followJob streams a job's logs to stdout until it finishes and returns
+// an error if it failed.
+func followJob(ctx context.Context, client *cliclient.Client, job *cliclient.Job, action string) error {
 	if err := client.StreamJobLogs(ctx, job.ID, os.Stdout); err != nil {
 		return fmt.Errorf("streaming %s logs: %w", action, err)
 	}
@@ -194,8 +236,6 @@ func runWorkspaceEnvJob(wsName, action string) error {
 		}
 		return fmt.Errorf("%s failed", action)
 	}
-
-	fmt.Fprintf(os.Stderr, "Workspace %q %sed successfully\n", wsName, action)
 	return nil
 }
 
@@ -312,7 +352,20 @@ func runWorkspaceTags(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", t.Tag, t.VersionNumber, created, updated)
 	}
-	return w.Flush()
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Older servers do not report a tag limit.
+	if detail, err := client.GetWorkspace(ctx, ws.ID); err == nil && detail.TagLimit != nil && detail.TagLimit.Max > 0 {
+		l := detail.TagLimit
+		fmt.Fprintf(os.Stderr, "\n%d of %d tags used (not counting latest and content tags)", l.Count, l.Max)
+		if l.Policy == "evict_oldest" {
+			fmt.Fprint(os.Stderr, "; the oldest is removed when a push adds one more")
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+	return nil
 }
 
 func runWorkspaceListServer() error {
@@ -397,6 +450,21 @@ func runWorkspaceRemoveServer(name string) error {
 		return err
 	}
 
+	if !wsRemoveYes {
+		impact, err := client.GetWorkspaceImpact(ctx, ws.ID)
+		if err != nil {
+			return fmt.Errorf("fetching deletion impact: %w", err)
+		}
+		fmt.Fprint(os.Stderr, formatDeletionImpact(name, impact))
+		fmt.Fprintf(os.Stderr, "Delete workspace %q from the server? [y/N] ", name)
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			return nil
+		}
+	}
+
 	if err := client.DeleteWorkspace(ctx, ws.ID); err != nil {
 		return fmt.Errorf("deleting workspace: %w", err)
 	}
@@ -405,6 +473,54 @@ func runWorkspaceRemoveServer(name string) error {
 	return nil
 }
 
+func runWorkspaceFork(cmd *cobra.Command, args []string) error {
+	source, name := args[0], args[1]
+	if err := validateWorkspaceName(name); err != nil {
+		return err
+	}
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	src, err := findWsByName(client, ctx, source)
+	if err != nil {
+		return err
+	}
+	if _, err := findWsByName(client, ctx, name); err == nil {
+		return fmt.Errorf("workspace %q already exists on the server", name)
+	} else if !errors.Is(err, ErrWsNotFound) {
+		return err
+	}
+
+	created, err := client.CloneWorkspace(ctx, src.ID, name, wsForkHistory)
+	if err != nil {
+		return fmt.Errorf("forking workspace: %w", err)
+	}
+
+	// Wait for workspace to be ready (server runs pixi install)
+	if _, err := waitForWsReady(client, ctx, created.ID, 60*time.Second); err != nil {
+		return fmt.Errorf("workspace %q failed to become ready: %w", name, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Forked %q into %q\n", source, name)
+	return nil
+}
+
+// formatDeletionImpact summarizes what deleting a server workspace removes.
+func formatDeletionImpact(name string, impact *cliclient.WorkspaceImpact) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Deleting %q will permanently remove:\n", name)
+	fmt.Fprintf(&sb, "  %d version(s)\n", impact.VersionCount)
+	fmt.Fprintf(&sb, "  %d tag(s)\n", impact.TagCount)
+	fmt.Fprintf(&sb, "  %d publication record(s)\n", impact.PublicationCount)
+	fmt.Fprintf(&sb, "  access for %d collaborator(s)\n", impact.CollaboratorCount)
+	return sb.String()
+}
+
 func runWorkspaceRemoveLocal(arg string) error {
 	s, err := store.New()
 	if err != nil {
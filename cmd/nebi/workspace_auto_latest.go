@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var wsAutoLatestClear bool
+
+var workspaceSetAutoLatestCmd = &cobra.Command{
+	Use:   "set-auto-latest <workspace-name> [tag]",
+	Short: "Set a tag that every push moves to the new version",
+	Long: `Set a tag that every push to a server workspace moves to the pushed
+version, like "latest". The tag is a rolling pointer: pushes reassign it
+without --force, and it does not count against the server's tag limit.
+
+Examples:
+  nebi workspace set-auto-latest myworkspace stable
+  nebi workspace set-auto-latest myworkspace --clear`,
+	Args:              cobra.RangeArgs(1, 2),
+	RunE:              runWorkspaceSetAutoLatest,
+	ValidArgsFunction: completeServerWorkspaceNames,
+}
+
+func init() {
+	workspaceSetAutoLatestCmd.Flags().BoolVar(&wsAutoLatestClear, "clear", false, "Stop moving a tag on push")
+	workspaceCmd.AddCommand(workspaceSetAutoLatestCmd)
+}
+
+func runWorkspaceSetAutoLatest(cmd *cobra.Command, args []string) error {
+	wsName := args[0]
+	var tag string
+	switch {
+	case wsAutoLatestClear && len(args) > 1:
+		return fmt.Errorf("--clear takes no tag")
+	case !wsAutoLatestClear && len(args) < 2:
+		return fmt.Errorf("a tag is required (or --clear to remove the setting)")
+	case len(args) > 1:
+		tag = args[1]
+	}
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	ws, err := findWsByName(client, ctx, wsName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.SetAutoLatestTag(ctx, ws.ID, tag); err != nil {
+		return fmt.Errorf("setting auto-latest tag: %w", err)
+	}
+
+	if tag == "" {
+		fmt.Fprintf(os.Stderr, "Pushes to %q no longer move an auto-latest tag\n", wsName)
+	} else {
+		fmt.Fprintf(os.Stderr, "Pushes to %q will move tag %q to the new version\n", wsName, tag)
+	}
+	return nil
+}
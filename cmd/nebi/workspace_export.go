@@ -0,0 +1,402 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/nebari-dev/nebi/internal/cliclient"
+	"github.com/nebari-dev/nebi/internal/contenthash"
+	"github.com/spf13/cobra"
+)
+
+// exportFormatVersion is the format_version of export archives. Bump it
+// when the archive layout changes incompatibly.
+const exportFormatVersion = 1
+
+// exportManifestName is the archive entry describing the export.
+const exportManifestName = "nebi-export.json"
+
+var (
+	wsExportAllVersions bool
+	wsExportOutput      string
+	wsRestoreName       string
+)
+
+var workspaceExportCmd = &cobra.Command{
+	Use:   "export <workspace-name>",
+	Short: "Export a server workspace to an archive",
+	Long: `Export a server workspace's pixi.toml and pixi.lock into a .tar.gz archive
+that 'nebi workspace restore' can recreate on another server.
+
+By default only the latest version is exported. With --all-versions every
+version is exported, together with the tags pointing at each, as a full
+backup of the workspace's history. The server-managed "latest" and content
+hash tags are not exported; the target server recreates them.
+
+Examples:
+  nebi workspace export data-science
+  nebi workspace export data-science --all-versions -o data-science.tar.gz`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runWorkspaceExport,
+	ValidArgsFunction: completeServerWorkspaceNames,
+}
+
+var workspaceRestoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Recreate a workspace from an export archive",
+	Long: `Recreate a workspace on the configured server from an archive written by
+'nebi workspace export'. Every file's digest is verified before anything is
+sent to the server.
+
+Versions are pushed oldest first and each tag is applied to the version it
+pointed at when exported, so the restored tags match the archive even where
+tags were reassigned on the source. Version numbers may differ from the
+source's: versions with identical content are stored once. The workspace
+must not already exist on the server; use --name to restore it under a
+different name.
+
+Examples:
+  nebi workspace restore data-science.tar.gz
+  nebi workspace restore data-science.tar.gz --name data-science-restored`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWorkspaceRestore,
+}
+
+func init() {
+	workspaceExportCmd.Flags().BoolVar(&wsExportAllVersions, "all-versions", false, "Export every version and its tags, not just the latest")
+	workspaceExportCmd.Flags().StringVarP(&wsExportOutput, "output", "o", "", "Archive path (default: <workspace>.tar.gz)")
+	workspaceCmd.AddCommand(workspaceExportCmd)
+	workspaceRestoreCmd.Flags().StringVar(&wsRestoreName, "name", "", "Name for the restored workspace (default: the exported name)")
+	workspaceCmd.AddCommand(workspaceRestoreCmd)
+}
+
+// exportManifest is the nebi-export.json entry of an export archive.
+type exportManifest struct {
+	FormatVersion  int             `json:"format_version"`
+	Workspace      string          `json:"workspace"`
+	PackageManager string          `json:"package_manager"`
+	ExportedAt     string          `json:"exported_at"`
+	Versions       []exportVersion `json:"versions"` // Oldest first
+}
+
+// exportVersion describes one exported version. Files maps archive entry
+// names to their SHA-256 digests.
+type exportVersion struct {
+	Number      int               `json:"number"`
+	CreatedAt   string            `json:"created_at"`
+	ContentHash string            `json:"content_hash"`
+	Tags        []string          `json:"tags"`
+	Files       map[string]string `json:"files"`
+}
+
+// exportedVersion is a version's manifest entry plus its file contents.
+type exportedVersion struct {
+	exportVersion
+	pixiToml string
+	pixiLock string
+}
+
+func runWorkspaceExport(cmd *cobra.Command, args []string) error {
+	wsName := args[0]
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	ws, err := findWsByName(client, ctx, wsName)
+	if err != nil {
+		return err
+	}
+
+	limit := 1
+	if wsExportAllVersions {
+		limit = 0
+	}
+	versions, err := listRemoteVersions(client, ctx, ws.ID, limit)
+	if err != nil {
+		return fmt.Errorf("listing versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("workspace %q has no versions to export", wsName)
+	}
+
+	tags, err := client.GetWorkspaceTags(ctx, ws.ID)
+	if err != nil {
+		return fmt.Errorf("listing tags: %w", err)
+	}
+	tagsByVersion := make(map[int][]string)
+	for _, t := range tags {
+		if t.Tag == "latest" || contenthash.IsHashTag(t.Tag) {
+			continue
+		}
+		tagsByVersion[t.VersionNumber] = append(tagsByVersion[t.VersionNumber], t.Tag)
+	}
+
+	// The server lists newest first; archives list oldest first, the
+	// order restore pushes them in.
+	exported := make([]exportedVersion, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		pixiToml, err := client.GetVersionPixiToml(ctx, ws.ID, v.VersionNumber)
+		if err != nil {
+			return fmt.Errorf("fetching pixi.toml of version %d: %w", v.VersionNumber, err)
+		}
+		pixiLock, err := client.GetVersionPixiLock(ctx, ws.ID, v.VersionNumber)
+		if err != nil {
+			return fmt.Errorf("fetching pixi.lock of version %d: %w", v.VersionNumber, err)
+		}
+		versionTags := tagsByVersion[int(v.VersionNumber)]
+		sort.Strings(versionTags)
+		exported = append(exported, exportedVersion{
+			exportVersion: exportVersion{
+				Number:    int(v.VersionNumber),
+				CreatedAt: v.CreatedAt,
+				Tags:      versionTags,
+			},
+			pixiToml: pixiToml,
+			pixiLock: pixiLock,
+		})
+	}
+
+	output := wsExportOutput
+	if output == "" {
+		output = wsName + ".tar.gz"
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := writeExportArchive(f, ws.Name, ws.PackageManager, exported); err != nil {
+		f.Close()
+		os.Remove(output)
+		return fmt.Errorf("writing %s: %w", output, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d version(s) of %q to %s\n", len(exported), wsName, output)
+	return nil
+}
+
+// writeExportArchive writes versions (oldest first) and their manifest as
+// a gzipped tar to w, filling in each version's digests.
+func writeExportArchive(w io.Writer, wsName, packageManager string, versions []exportedVersion) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest := exportManifest{
+		FormatVersion:  exportFormatVersion,
+		Workspace:      wsName,
+		PackageManager: packageManager,
+		ExportedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, v := range versions {
+		v.ContentHash = contenthash.Hash(v.pixiToml, v.pixiLock)
+		v.Files = make(map[string]string)
+		for _, file := range []struct{ name, content string }{
+			{"pixi.toml", v.pixiToml},
+			{"pixi.lock", v.pixiLock},
+		} {
+			name := path.Join("versions", fmt.Sprint(v.Number), file.name)
+			if err := writeTarFile(tw, name, []byte(file.content)); err != nil {
+				return err
+			}
+			v.Files[name] = sha256Hex([]byte(file.content))
+		}
+		manifest.Versions = append(manifest.Versions, v.exportVersion)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, exportManifestName, data); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readExportArchive reads an export archive and verifies every version's
+// file digests and content hash against its manifest.
+func readExportArchive(r io.Reader) (*exportManifest, []exportedVersion, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a gzipped archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = data
+	}
+
+	data, ok := files[exportManifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("archive has no %s; was it written by 'nebi workspace export'?", exportManifestName)
+	}
+	var manifest exportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", exportManifestName, err)
+	}
+	if manifest.FormatVersion != exportFormatVersion {
+		return nil, nil, fmt.Errorf("unsupported export format version %d (this nebi reads version %d)", manifest.FormatVersion, exportFormatVersion)
+	}
+	if len(manifest.Versions) == 0 {
+		return nil, nil, fmt.Errorf("archive contains no versions")
+	}
+
+	versions := make([]exportedVersion, 0, len(manifest.Versions))
+	for _, v := range manifest.Versions {
+		for name, digest := range v.Files {
+			content, ok := files[name]
+			if !ok {
+				return nil, nil, fmt.Errorf("version %d: %s is missing from the archive", v.Number, name)
+			}
+			if sha256Hex(content) != digest {
+				return nil, nil, fmt.Errorf("version %d: digest mismatch for %s", v.Number, name)
+			}
+		}
+		dir := path.Join("versions", fmt.Sprint(v.Number))
+		ev := exportedVersion{
+			exportVersion: v,
+			pixiToml:      string(files[path.Join(dir, "pixi.toml")]),
+			pixiLock:      string(files[path.Join(dir, "pixi.lock")]),
+		}
+		if _, ok := v.Files[path.Join(dir, "pixi.toml")]; !ok {
+			return nil, nil, fmt.Errorf("version %d has no pixi.toml", v.Number)
+		}
+		if contenthash.Hash(ev.pixiToml, ev.pixiLock) != v.ContentHash {
+			return nil, nil, fmt.Errorf("version %d: content hash mismatch", v.Number)
+		}
+		versions = append(versions, ev)
+	}
+	return &manifest, versions, nil
+}
+
+func runWorkspaceRestore(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	manifest, versions, err := readExportArchive(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	wsName := manifest.Workspace
+	if wsRestoreName != "" {
+		wsName = wsRestoreName
+	}
+	if err := validateWorkspaceName(wsName); err != nil {
+		return err
+	}
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if _, err := findWsByName(client, ctx, wsName); err == nil {
+		return fmt.Errorf("workspace %q already exists on the server; use --name to restore under another name", wsName)
+	} else if !errors.Is(err, ErrWsNotFound) {
+		return err
+	}
+
+	// The oldest version seeds the workspace; every tag is then pushed
+	// (forced, oldest version first) so each ends on its exported version.
+	first := versions[0]
+	initialTag := ""
+	if len(first.Tags) > 0 {
+		initialTag = first.Tags[0]
+	}
+	pkgMgr := manifest.PackageManager
+	if pkgMgr == "" {
+		pkgMgr = "pixi"
+	}
+	fmt.Fprintf(os.Stderr, "Creating workspace %q...\n", wsName)
+	newWs, err := client.CreateWorkspace(ctx, cliclient.CreateWorkspaceRequest{
+		Name:           wsName,
+		PackageManager: &pkgMgr,
+		PixiToml:       &first.pixiToml,
+		PixiLock:       &first.pixiLock,
+		InitialTag:     initialTag,
+	})
+	if err != nil {
+		return fmt.Errorf("creating workspace %q: %w", wsName, err)
+	}
+	ws, err := waitForWsReady(client, ctx, newWs.ID, 60*time.Second)
+	if err != nil {
+		return fmt.Errorf("workspace %q failed to become ready: %w", wsName, err)
+	}
+
+	for _, v := range versions {
+		tags := v.Tags
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		var resp *cliclient.PushResponse
+		for _, tag := range tags {
+			resp, err = client.PushVersion(ctx, ws.ID, cliclient.PushRequest{
+				Tag:      tag,
+				PixiToml: v.pixiToml,
+				PixiLock: v.pixiLock,
+				Force:    true,
+			})
+			if err != nil {
+				return fmt.Errorf("restoring version %d: %w", v.Number, err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "  version %d -> %d\n", v.Number, resp.VersionNumber)
+	}
+
+	fmt.Fprintf(os.Stderr, "Restored %d version(s) of %q as %q\n", len(versions), manifest.Workspace, wsName)
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testExportVersions() []exportedVersion {
+	return []exportedVersion{
+		{exportVersion: exportVersion{Number: 1, Tags: []string{"v1.0"}}, pixiToml: "[workspace]\nname = \"a\"\n", pixiLock: "version: 6\n"},
+		{exportVersion: exportVersion{Number: 3}, pixiToml: "[workspace]\nname = \"b\"\n", pixiLock: ""},
+	}
+}
+
+func TestExportArchiveRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeExportArchive(&buf, "data", "pixi", testExportVersions()); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	manifest, versions, err := readExportArchive(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if manifest.Workspace != "data" || manifest.FormatVersion != exportFormatVersion {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	for i, want := range testExportVersions() {
+		got := versions[i]
+		if got.Number != want.Number || got.pixiToml != want.pixiToml || got.pixiLock != want.pixiLock || !reflect.DeepEqual(got.Tags, want.Tags) {
+			t.Errorf("version %d: got %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestExportArchiveDigestMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeExportArchive(&buf, "data", "pixi", testExportVersions()); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// Rewrite the archive with one file's content changed.
+	gz, _ := gzip.NewReader(&buf)
+	tr := tar.NewReader(gz)
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		data, _ := io.ReadAll(tr)
+		if hdr.Name == "versions/1/pixi.lock" {
+			data = []byte("version: 5\n")
+		}
+		writeTarFile(tw, hdr.Name, data)
+	}
+	tw.Close()
+	gw.Close()
+
+	_, _, err := readExportArchive(&out)
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch for versions/1/pixi.lock") {
+		t.Errorf("expected digest mismatch, got: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var wsStatsJSON bool
+
+var workspaceStatsCmd = &cobra.Command{
+	Use:   "stats <workspace-name>",
+	Short: "Show usage metrics for a workspace on the server",
+	Long: `Show aggregate metrics for a remote workspace: number of versions and
+tags, total pushes and pulls, last activity, and current size.
+
+Examples:
+  nebi workspace stats myworkspace
+  nebi workspace stats myworkspace --json`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runWorkspaceStats,
+	ValidArgsFunction: completeServerWorkspaceNames,
+}
+
+func init() {
+	workspaceStatsCmd.Flags().BoolVar(&wsStatsJSON, "json", false, "Output as JSON")
+	workspaceCmd.AddCommand(workspaceStatsCmd)
+}
+
+func runWorkspaceStats(cmd *cobra.Command, args []string) error {
+	wsName := args[0]
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	ws, err := findWsByName(client, ctx, wsName)
+	if err != nil {
+		return err
+	}
+
+	stats, err := client.GetWorkspaceStats(ctx, ws.ID)
+	if err != nil {
+		return fmt.Errorf("getting workspace stats: %w", err)
+	}
+
+	if wsStatsJSON {
+		return writeJSON(stats)
+	}
+
+	lastActivity := "never"
+	if stats.LastActivity != nil {
+		lastActivity = formatTimestamp(*stats.LastActivity)
+	}
+	size := stats.SizeFormatted
+	if size == "" {
+		size = "-"
+	}
+
+	printField("Workspace", wsName)
+	printField("Versions", fmt.Sprintf("%d", stats.VersionCount))
+	printField("Tags", fmt.Sprintf("%d", stats.TagCount))
+	printField("Pushes", fmt.Sprintf("%d", stats.PushCount))
+	printField("Pulls", fmt.Sprintf("%d", stats.PullCount))
+	printField("Last activity", lastActivity)
+	printField("Size", size)
+	return nil
+}
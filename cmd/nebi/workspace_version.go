@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"text/tabwriter"
 
+	"github.com/nebari-dev/nebi/internal/cliclient"
 	"github.com/nebari-dev/nebi/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -16,6 +17,7 @@ var (
 	wsVersionRemote    bool
 	wsVersionJSON      bool
 	wsVersionCreateMsg string
+	wsVersionLimit     int
 )
 
 var workspaceVersionCmd = &cobra.Command{
@@ -35,7 +37,8 @@ If no workspace name is given, the current directory's tracked workspace is used
 Examples:
   nebi workspace version list                  # current directory, local
   nebi workspace version list myws             # by name, local
-  nebi workspace version list myws --remote    # by name, server`,
+  nebi workspace version list myws --remote    # by name, server
+  nebi workspace version list myws -r --limit 10  # newest 10 on the server`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runWorkspaceVersionList,
 }
@@ -96,6 +99,7 @@ Examples:
 func init() {
 	workspaceVersionListCmd.Flags().BoolVarP(&wsVersionRemote, "remote", "r", false, "Operate on the server instead of the local store")
 	workspaceVersionListCmd.Flags().BoolVar(&wsVersionJSON, "json", false, "Output as JSON")
+	workspaceVersionListCmd.Flags().IntVar(&wsVersionLimit, "limit", 0, "Show at most this many of the newest versions (0 = all)")
 
 	workspaceVersionShowCmd.Flags().BoolVarP(&wsVersionRemote, "remote", "r", false, "Operate on the server instead of the local store")
 	workspaceVersionShowCmd.Flags().BoolVar(&wsVersionJSON, "json", false, "Output as JSON")
@@ -184,6 +188,9 @@ func runWorkspaceVersionListLocal(name string) error {
 	if err != nil {
 		return err
 	}
+	if wsVersionLimit > 0 && len(versions) > wsVersionLimit {
+		versions = versions[:wsVersionLimit]
+	}
 
 	if wsVersionJSON {
 		return writeJSON(versions)
@@ -194,7 +201,8 @@ func runWorkspaceVersionListLocal(name string) error {
 		return nil
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	out := newPagedOutput()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(w, "VERSION\tCREATED\tHASH\tDESCRIPTION")
 	for _, v := range versions {
 		hash := v.ContentHash
@@ -208,7 +216,10 @@ func runWorkspaceVersionListLocal(name string) error {
 			v.Description,
 		)
 	}
-	return w.Flush()
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return out.Close()
 }
 
 func runWorkspaceVersionListRemote(name string) error {
@@ -228,7 +239,7 @@ func runWorkspaceVersionListRemote(name string) error {
 		return err
 	}
 
-	versions, err := client.GetWorkspaceVersions(ctx, ws.ID)
+	versions, err := listRemoteVersions(client, ctx, ws.ID, wsVersionLimit)
 	if err != nil {
 		return fmt.Errorf("listing versions: %w", err)
 	}
@@ -242,12 +253,43 @@ func runWorkspaceVersionListRemote(name string) error {
 		return nil
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	out := newPagedOutput()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(w, "VERSION\tCREATED")
 	for _, v := range versions {
 		fmt.Fprintf(w, "%d\t%s\n", v.VersionNumber, formatTimestamp(v.CreatedAt))
 	}
-	return w.Flush()
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// listRemoteVersions fetches the newest limit versions from the server,
+// paging past the server's per-request cap as needed. limit 0 fetches the
+// full history.
+func listRemoteVersions(client *cliclient.Client, ctx context.Context, wsID string, limit int) ([]cliclient.WorkspaceVersion, error) {
+	if limit <= 0 {
+		return client.GetWorkspaceVersions(ctx, wsID)
+	}
+
+	var versions []cliclient.WorkspaceVersion
+	before := 0
+	for len(versions) < limit {
+		page, next, err := client.GetWorkspaceVersionsPage(ctx, wsID, limit-len(versions), before)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, page...)
+		if next == 0 {
+			break
+		}
+		before = next
+	}
+	if len(versions) > limit {
+		versions = versions[:limit]
+	}
+	return versions, nil
 }
 
 func runWorkspaceVersionShow(cmd *cobra.Command, args []string) error {
@@ -0,0 +1,55 @@
+import { useEffect, useState } from 'react';
+import { useQueryClient } from '@tanstack/react-query';
+import { getApiBaseUrl } from '@/lib/basePath';
+import { useAuthStore } from '@/store/authStore';
+
+const API_BASE_URL = import.meta.env.VITE_API_URL || getApiBaseUrl();
+
+const EVENT_TYPES = ['created', 'updated', 'deleted', 'status'] as const;
+
+// Subscribes to the workspace events stream and invalidates cached workspace
+// queries when a visible workspace changes. Returns whether the stream is
+// connected so callers can fall back to polling when it is not.
+export const useWorkspaceEvents = () => {
+  const queryClient = useQueryClient();
+  const token = useAuthStore((state) => state.token);
+  const [connected, setConnected] = useState(false);
+
+  useEffect(() => {
+    if (!token || typeof EventSource === 'undefined') {
+      setConnected(false);
+      return;
+    }
+
+    const url = `${API_BASE_URL}/workspaces/events?token=${encodeURIComponent(token)}`;
+    const eventSource = new EventSource(url);
+
+    eventSource.onopen = () => setConnected(true);
+
+    const handleEvent = (event: MessageEvent) => {
+      try {
+        const data = JSON.parse(event.data) as { workspace_id?: string };
+        queryClient.invalidateQueries({ queryKey: ['workspaces'], exact: true });
+        if (data.workspace_id) {
+          queryClient.invalidateQueries({ queryKey: ['workspaces', data.workspace_id] });
+        }
+      } catch {
+        queryClient.invalidateQueries({ queryKey: ['workspaces'] });
+      }
+    };
+    EVENT_TYPES.forEach((type) => eventSource.addEventListener(type, handleEvent));
+
+    eventSource.onerror = () => {
+      // EventSource reconnects on its own; poll until it does.
+      setConnected(false);
+    };
+
+    return () => {
+      EVENT_TYPES.forEach((type) => eventSource.removeEventListener(type, handleEvent));
+      eventSource.close();
+      setConnected(false);
+    };
+  }, [token, queryClient]);
+
+  return { connected };
+};
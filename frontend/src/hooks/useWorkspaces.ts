@@ -1,12 +1,15 @@
 import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
 import { workspacesApi } from '@/api/workspaces';
 import type { CreateWorkspaceRequest } from '@/types';
+import { useWorkspaceEvents } from './useWorkspaceEvents';
 
 export const useWorkspaces = () => {
+  const { connected } = useWorkspaceEvents();
   return useQuery({
     queryKey: ['workspaces'],
     queryFn: workspacesApi.list,
-    refetchInterval: 2000, // Poll every 2 seconds for status updates
+    // Live events keep the list fresh; poll only while the stream is down.
+    refetchInterval: connected ? false : 2000,
   });
 };
 
@@ -0,0 +1,117 @@
+// Package advisory looks up known security advisories for package versions
+// in an OSV-compatible database (https://osv.dev).
+package advisory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the public OSV API.
+const DefaultURL = "https://api.osv.dev"
+
+// EcosystemPyPI is the OSV ecosystem used for pixi packages. OSV has no
+// conda ecosystem; most conda-forge Python packages share their PyPI name.
+const EcosystemPyPI = "PyPI"
+
+// Package identifies one package version to look up.
+type Package struct {
+	Ecosystem string
+	Name      string
+	Version   string
+}
+
+// Client queries an OSV-compatible advisory API.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the OSV API at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// BaseURL returns the advisory API the client talks to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+type batchQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version string `json:"version"`
+}
+
+type batchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	} `json:"results"`
+}
+
+// QueryBatch returns the IDs of the advisories affecting each package, in
+// the order given. A package with no known advisories has a nil entry.
+func (c *Client) QueryBatch(ctx context.Context, pkgs []Package) ([][]string, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	queries := make([]batchQuery, len(pkgs))
+	for i, p := range pkgs {
+		queries[i].Package.Name = p.Name
+		queries[i].Package.Ecosystem = p.Ecosystem
+		queries[i].Version = p.Version
+	}
+	body, err := json.Marshal(map[string]interface{}{"queries": queries})
+	if err != nil {
+		return nil, fmt.Errorf("marshal query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/querybatch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("advisory API returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var out batchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode advisory response: %w", err)
+	}
+	if len(out.Results) != len(pkgs) {
+		return nil, fmt.Errorf("advisory API returned %d results for %d queries", len(out.Results), len(pkgs))
+	}
+
+	ids := make([][]string, len(pkgs))
+	for i, r := range out.Results {
+		for _, v := range r.Vulns {
+			ids[i] = append(ids[i], v.ID)
+		}
+	}
+	return ids, nil
+}
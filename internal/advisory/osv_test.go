@@ -0,0 +1,53 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestQueryBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/querybatch" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req struct {
+			Queries []batchQuery `json:"queries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(req.Queries) != 2 || req.Queries[0].Package.Ecosystem != EcosystemPyPI || req.Queries[0].Version != "1.0" {
+			t.Errorf("unexpected queries: %+v", req.Queries)
+		}
+		w.Write([]byte(`{"results":[{"vulns":[{"id":"GHSA-1"},{"id":"PYSEC-2"}]},{}]}`))
+	}))
+	defer srv.Close()
+
+	ids, err := NewClient(srv.URL+"/").QueryBatch(context.Background(), []Package{
+		{Ecosystem: EcosystemPyPI, Name: "requests", Version: "1.0"},
+		{Ecosystem: EcosystemPyPI, Name: "requests", Version: "2.0"},
+	})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	want := [][]string{{"GHSA-1", "PYSEC-2"}, nil}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+}
+
+func TestQueryBatch_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL).QueryBatch(context.Background(), []Package{{Ecosystem: EcosystemPyPI, Name: "x", Version: "1"}})
+	if err == nil {
+		t.Fatal("expected an error for a non-200 response")
+	}
+}
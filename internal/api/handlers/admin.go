@@ -131,6 +131,32 @@ func (h *AdminHandler) ListUserGroups(c *gin.Context) {
 	c.JSON(http.StatusOK, groups)
 }
 
+// ListUserWorkspaces godoc
+// @Summary List the workspaces a user can access, with their role on each (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User UUID"
+// @Success 200 {array} service.UserWorkspace
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/workspaces [get]
+func (h *AdminHandler) ListUserWorkspaces(c *gin.Context) {
+	uid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+	workspaces, err := h.svc.ListUserWorkspaces(uid)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, workspaces)
+}
+
 // DeleteUser godoc
 // @Summary Delete a user (admin only)
 // @Tags admin
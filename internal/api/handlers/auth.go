@@ -3,10 +3,16 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/audit"
 	"github.com/nebari-dev/nebi/internal/auth"
+	"gorm.io/gorm"
 )
 
 // Login godoc
@@ -19,8 +25,9 @@ import (
 // @Success 200 {object} auth.LoginResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string "too many failed attempts; see Retry-After"
 // @Router /auth/login [post]
-func Login(authenticator auth.Authenticator) gin.HandlerFunc {
+func Login(authenticator auth.Authenticator, limiter *auth.LoginLimiter, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req auth.LoginRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -28,9 +35,24 @@ func Login(authenticator auth.Authenticator) gin.HandlerFunc {
 			return
 		}
 
+		userKey := "user:" + strings.ToLower(req.Username)
+		ipKey := "ip:" + c.ClientIP()
+		if wait, blocked := limiter.Blocked(userKey, ipKey); blocked {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed login attempts; try again later"})
+			return
+		}
+
 		resp, err := authenticator.Login(req.Username, req.Password)
 		if err != nil {
 			if errors.Is(err, auth.ErrInvalidCredentials) {
+				if locked := limiter.Fail(userKey, ipKey); locked > 0 {
+					audit.LogAction(db, uuid.Nil, audit.ActionLoginLockout, "user:"+req.Username, map[string]interface{}{
+						"username":        req.Username,
+						"ip":              c.ClientIP(),
+						"lockout_seconds": int(locked.Seconds()),
+					})
+				}
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 				return
 			}
@@ -38,6 +60,9 @@ func Login(authenticator auth.Authenticator) gin.HandlerFunc {
 			return
 		}
 
+		// Only the username's history is cleared: one valid account must not
+		// reset the counter for an IP that is guessing other accounts.
+		limiter.Reset(userKey)
 		c.JSON(http.StatusOK, resp)
 	}
 }
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/auth"
+	"github.com/nebari-dev/nebi/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// passwordAuthenticator accepts a single fixed username and password.
+type passwordAuthenticator struct {
+	auth.Authenticator
+}
+
+func (passwordAuthenticator) Login(username, password string) (*auth.LoginResponse, error) {
+	if username == "alice" && password == "secret" {
+		return &auth.LoginResponse{Token: "token"}, nil
+	}
+	return nil, auth.ErrInvalidCredentials
+}
+
+func TestLoginLockout(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	limiter := auth.NewLoginLimiter(auth.NewMemoryLockoutStore(time.Hour), auth.LockoutPolicy{
+		MaxAttempts: 2,
+		Window:      time.Minute,
+		Lockout:     time.Minute,
+		MaxLockout:  time.Hour,
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/auth/login", Login(passwordAuthenticator{}, limiter, db))
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(auth.LoginRequest{Username: "alice", Password: password})
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := login("secret"); w.Code != http.StatusOK {
+		t.Fatalf("expected success, got %d", w.Code)
+	}
+	for i := 0; i < 2; i++ {
+		if w := login("wrong"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("failure %d: expected 401, got %d", i+1, w.Code)
+		}
+	}
+
+	w := login("secret")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 while locked out, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected Retry-After: 60, got %q", w.Header().Get("Retry-After"))
+	}
+
+	var count int64
+	db.Model(&models.AuditLog{}).Where("action = ?", audit.ActionLoginLockout).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 lockout audit entry, got %d", count)
+	}
+}
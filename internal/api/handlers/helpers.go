@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,6 +14,11 @@ import (
 
 // handleServiceError maps service-layer errors to HTTP status codes.
 func handleServiceError(c *gin.Context, err error) {
+	var notFoundErr *service.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: notFoundErr.Message})
+		return
+	}
 	if errors.Is(err, service.ErrNotFound) {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Not found"})
 		return
@@ -36,6 +42,29 @@ func handleServiceError(c *gin.Context, err error) {
 	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
 }
 
+// nextBeforeHeader carries the cursor for the next page of versions.
+const nextBeforeHeader = "X-Next-Before"
+
+// parseVersionPageParams reads the ?limit and ?before query parameters for
+// version listings. It writes a 400 response and returns false if either
+// is not a non-negative integer.
+func parseVersionPageParams(c *gin.Context) (service.ListVersionsOptions, bool) {
+	var opts service.ListVersionsOptions
+	for name, dst := range map[string]*int{"limit": &opts.Limit, "before": &opts.Before} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a non-negative integer"})
+			return opts, false
+		}
+		*dst = n
+	}
+	return opts, true
+}
+
 // getUserID extracts the user ID from the Gin context.
 func getUserID(c *gin.Context) uuid.UUID {
 	user, exists := c.Get("user")
@@ -190,12 +190,19 @@ func (h *RemoteHandler) ListVersions(c *gin.Context) {
 		h.notConnected(c, err)
 		return
 	}
+	opts, ok := parseVersionPageParams(c)
+	if !ok {
+		return
+	}
 	id := c.Param("id")
-	versions, err := client.GetWorkspaceVersions(c.Request.Context(), id)
+	versions, next, err := client.GetWorkspaceVersionsPage(c.Request.Context(), id, opts.Limit, opts.Before)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, ErrorResponse{Error: fmt.Sprintf("Remote error: %v", err)})
 		return
 	}
+	if next > 0 {
+		c.Header(nextBeforeHeader, strconv.Itoa(next))
+	}
 	c.JSON(http.StatusOK, versions)
 }
 
@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -35,8 +40,72 @@ func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
 	c.JSON(http.StatusOK, workspaces)
 }
 
+// workspaceEventKeepalive is how often a comment line is written to idle
+// event streams so proxies don't close them.
+const workspaceEventKeepalive = 30 * time.Second
+
+// StreamWorkspaceEvents godoc
+// @Summary Stream workspace lifecycle events via Server-Sent Events
+// @Description Emits an event whenever a workspace visible to the caller is created, updated, deleted, or changes status.
+// @Tags workspaces
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param token query string false "Auth token (alternative to Bearer header for EventSource compatibility)"
+// @Success 200 {string} string "event stream"
+// @Failure 401 {object} ErrorResponse
+// @Router /workspaces/events [get]
+func (h *WorkspaceHandler) StreamWorkspaceEvents(c *gin.Context) {
+	userID := getUserID(c)
+
+	events, cancel := h.svc.SubscribeEvents()
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+
+	if events == nil {
+		fmt.Fprintf(c.Writer, "event: error\ndata: Workspace events not available\n\n")
+		c.Writer.Flush()
+		return
+	}
+
+	keepalive := time.NewTicker(workspaceEventKeepalive)
+	defer keepalive.Stop()
+
+	clientGone := c.Request.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			return
+		case <-keepalive.C:
+			fmt.Fprintf(c.Writer, ": keepalive\n\n")
+			c.Writer.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if !h.svc.CanSeeWorkspace(userID, ev.WorkspaceID) {
+				continue
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", ev.Type, data)
+			c.Writer.Flush()
+		}
+	}
+}
+
 // CreateWorkspace godoc
 // @Summary Create a new workspace
+// @Description Queues creation of a workspace. When pixi_lock (or initial_tag) is
+// @Description given alongside pixi_toml, the initial version is tagged (default "v0")
+// @Description and "latest" once the workspace is ready, so it can be pulled immediately.
 // @Tags workspaces
 // @Security BearerAuth
 // @Accept json
@@ -45,6 +114,7 @@ func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
 // @Success 201 {object} models.Workspace
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse "Non-admin create rejected by workspaces.allow_push_autocreate"
 // @Failure 500 {object} ErrorResponse
 // @Router /workspaces [post]
 func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
@@ -54,10 +124,17 @@ func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
 		return
 	}
 
+	if err := h.svc.CheckCreateAllowed(req.Name, getUserID(c)); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
 	ws, err := h.svc.Create(c.Request.Context(), service.CreateRequest{
 		Name:           req.Name,
 		PackageManager: req.PackageManager,
 		PixiToml:       req.PixiToml,
+		PixiLock:       req.PixiLock,
+		InitialTag:     req.InitialTag,
 		Source:         req.Source,
 		Path:           req.Path,
 	}, getUserID(c))
@@ -69,13 +146,51 @@ func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
 	c.JSON(http.StatusCreated, ws)
 }
 
+// CloneWorkspace godoc
+// @Summary Clone a workspace
+// @Description Creates a new workspace owned by the caller from the newest version of
+// @Description the source workspace. With history=true, the source's earlier versions
+// @Description and their tags are copied too, subject to workspaces.max_tags. The source
+// @Description is not modified.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Source workspace ID"
+// @Param history query bool false "Copy the full version history"
+// @Param request body CloneWorkspaceRequest true "New workspace name"
+// @Success 201 {object} models.Workspace
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /workspaces/{id}/clone [post]
+func (h *WorkspaceHandler) CloneWorkspace(c *gin.Context) {
+	var req CloneWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ws, err := h.svc.Clone(c.Request.Context(), c.Param("id"), service.CloneRequest{
+		Name:    req.Name,
+		History: c.Query("history") == "true",
+	}, getUserID(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ws)
+}
+
 // GetWorkspace godoc
 // @Summary Get a workspace by ID
 // @Tags workspaces
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Workspace ID"
-// @Success 200 {object} models.Workspace
+// @Success 200 {object} service.WorkspaceResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -156,10 +271,13 @@ func (h *WorkspaceHandler) SavePixiToml(c *gin.Context) {
 
 // SolveWorkspace godoc
 // @Summary Solve the environment (refresh pixi.lock) from current pixi.toml
+// @Description Solves for the platforms in the request body when given, otherwise for the workspace's default platforms
 // @Tags workspaces
 // @Security BearerAuth
+// @Accept json
 // @Produce json
 // @Param id path string true "Workspace ID"
+// @Param request body SolveWorkspaceRequest false "Platform override"
 // @Success 202 {object} models.Job
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -167,7 +285,14 @@ func (h *WorkspaceHandler) SavePixiToml(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse
 // @Router /workspaces/{id}/solve [post]
 func (h *WorkspaceHandler) SolveWorkspace(c *gin.Context) {
-	job, err := h.svc.SolveWorkspace(c.Request.Context(), c.Param("id"), getUserID(c))
+	// The body is optional; an empty one solves for the default platforms.
+	var req SolveWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	job, err := h.svc.SolveWorkspace(c.Request.Context(), c.Param("id"), req.Platforms, getUserID(c))
 	if err != nil {
 		handleServiceError(c, err)
 		return
@@ -258,24 +383,37 @@ func (h *WorkspaceHandler) PushVersion(c *gin.Context) {
 		ContentHash:   result.ContentHash,
 		Deduplicated:  result.Deduplicated,
 		Tag:           result.Tag,
+		EvictedTags:   result.EvictedTags,
 	})
 }
 
 // ListVersions godoc
-// @Summary List all versions for an workspace
+// @Summary List versions for a workspace, newest first
+// @Description Results are capped by the server (workspaces.max_versions_listed). When older versions remain, the X-Next-Before header holds the cursor to pass as ?before= for the next page.
 // @Tags workspaces
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Workspace ID"
+// @Param limit query int false "Maximum versions to return (capped by the server)"
+// @Param before query int false "Only return versions numbered below this"
 // @Success 200 {array} models.WorkspaceVersion
+// @Header 200 {integer} X-Next-Before "Cursor for the next page, if any"
+// @Failure 400 {object} ErrorResponse
 // @Router /workspaces/{id}/versions [get]
 func (h *WorkspaceHandler) ListVersions(c *gin.Context) {
-	versions, err := h.svc.ListVersions(c.Param("id"))
+	opts, ok := parseVersionPageParams(c)
+	if !ok {
+		return
+	}
+	page, err := h.svc.ListVersions(c.Param("id"), opts)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, versions)
+	if page.NextBefore > 0 {
+		c.Header(nextBeforeHeader, strconv.Itoa(page.NextBefore))
+	}
+	c.JSON(http.StatusOK, page.Versions)
 }
 
 // GetVersion godoc
@@ -324,6 +462,7 @@ func (h *WorkspaceHandler) DownloadLockFile(c *gin.Context) {
 // @Produce text/plain
 // @Param id path string true "Workspace ID"
 // @Param version path int true "Version number"
+// @Param pull query bool false "Record the download as a pull in the audit log"
 // @Success 200 {string} string "pixi.toml content"
 // @Router /workspaces/{id}/versions/{version}/pixi-toml [get]
 func (h *WorkspaceHandler) DownloadManifestFile(c *gin.Context) {
@@ -333,11 +472,53 @@ func (h *WorkspaceHandler) DownloadManifestFile(c *gin.Context) {
 		handleServiceError(c, err)
 		return
 	}
+	if c.Query("pull") == "true" {
+		if err := h.svc.RecordPull(c.Param("id"), versionNum, getUserID(c)); err != nil {
+			handleServiceError(c, err)
+			return
+		}
+	}
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=pixi-toml-v%s.toml", versionNum))
 	c.Header("Content-Type", "text/plain")
 	c.String(http.StatusOK, content)
 }
 
+// GetWorkspaceStats godoc
+// @Summary Get aggregate usage metrics for a workspace
+// @Tags workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} service.WorkspaceStats
+// @Failure 404 {object} ErrorResponse
+// @Router /workspaces/{id}/stats [get]
+func (h *WorkspaceHandler) GetWorkspaceStats(c *gin.Context) {
+	stats, err := h.svc.GetStats(c.Param("id"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetWorkspaceImpact godoc
+// @Summary Count what deleting a workspace would remove
+// @Tags workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} service.WorkspaceImpact
+// @Failure 404 {object} ErrorResponse
+// @Router /workspaces/{id}/impact [get]
+func (h *WorkspaceHandler) GetWorkspaceImpact(c *gin.Context) {
+	impact, err := h.svc.GetDeletionImpact(c.Param("id"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, impact)
+}
+
 // ListTags godoc
 // @Summary List tags for an workspace
 // @Tags workspaces
@@ -627,16 +808,117 @@ func (h *WorkspaceHandler) GetPublishDefaults(c *gin.Context) {
 	c.JSON(http.StatusOK, defaults)
 }
 
+// GetDefaultPlatforms godoc
+// @Summary Get the platforms a solve targets by default
+// @Description Returns the workspace's default platforms, or the platforms declared in pixi.toml when none are set
+// @Tags workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} service.PlatformsResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /workspaces/{id}/default-platforms [get]
+func (h *WorkspaceHandler) GetDefaultPlatforms(c *gin.Context) {
+	result, err := h.svc.GetDefaultPlatforms(c.Param("id"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// SetDefaultPlatforms godoc
+// @Summary Set the platforms a solve targets by default
+// @Description An empty list clears the setting so solves use the platforms declared in pixi.toml
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Param request body SetDefaultPlatformsRequest true "Default platforms"
+// @Success 200 {object} service.PlatformsResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /workspaces/{id}/default-platforms [put]
+func (h *WorkspaceHandler) SetDefaultPlatforms(c *gin.Context) {
+	var req SetDefaultPlatformsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.svc.SetDefaultPlatforms(c.Param("id"), req.Platforms, getUserID(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAutoLatestTag godoc
+// @Summary Get the workspace's auto-latest tag
+// @Description Returns the tag every push moves to the pushed version; empty when not set
+// @Tags workspaces
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} service.AutoLatestResult
+// @Failure 404 {object} ErrorResponse
+// @Router /workspaces/{id}/auto-latest-tag [get]
+func (h *WorkspaceHandler) GetAutoLatestTag(c *gin.Context) {
+	result, err := h.svc.GetAutoLatestTag(c.Param("id"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// SetAutoLatestTag godoc
+// @Summary Set the workspace's auto-latest tag
+// @Description Every push moves this tag to the pushed version, like "latest", without needing force. An empty tag clears the setting
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Workspace ID"
+// @Param request body SetAutoLatestTagRequest true "Auto-latest tag"
+// @Success 200 {object} service.AutoLatestResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /workspaces/{id}/auto-latest-tag [put]
+func (h *WorkspaceHandler) SetAutoLatestTag(c *gin.Context) {
+	var req SetAutoLatestTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.svc.SetAutoLatestTag(c.Param("id"), req.Tag, getUserID(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 // --- Request/Response types ---
 
 type CreateWorkspaceRequest struct {
 	Name           string `json:"name"`
 	PackageManager string `json:"package_manager"`
 	PixiToml       string `json:"pixi_toml"`
+	PixiLock       string `json:"pixi_lock"`   // optional; with pixi_toml, creates a tagged initial version
+	InitialTag     string `json:"initial_tag"` // tag for that version; defaults to workspaces.initial_tag
 	Source         string `json:"source"`
 	Path           string `json:"path"`
 }
 
+type CloneWorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
 type PixiTomlResponse struct {
 	Content string `json:"content"`
 }
@@ -645,6 +927,18 @@ type InstallPackagesRequest struct {
 	Packages []string `json:"packages" binding:"required"`
 }
 
+type SolveWorkspaceRequest struct {
+	Platforms []string `json:"platforms"` // overrides the default platforms for this solve
+}
+
+type SetDefaultPlatformsRequest struct {
+	Platforms []string `json:"platforms"`
+}
+
+type SetAutoLatestTagRequest struct {
+	Tag string `json:"tag"` // empty clears the setting
+}
+
 type SavePixiTomlRequest struct {
 	Content string `json:"content" binding:"required"`
 }
@@ -662,6 +956,7 @@ type PushVersionResponse struct {
 	ContentHash   string   `json:"content_hash"`
 	Deduplicated  bool     `json:"deduplicated"`
 	Tag           string   `json:"tag"`
+	EvictedTags   []string `json:"evicted_tags,omitempty"` // oldest tags removed to stay within workspaces.max_tags
 }
 
 type WorkspaceTagResponse struct {
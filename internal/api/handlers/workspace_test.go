@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/config"
+	"github.com/nebari-dev/nebi/internal/executor"
+	"github.com/nebari-dev/nebi/internal/models"
+	"github.com/nebari-dev/nebi/internal/queue"
+	"github.com/nebari-dev/nebi/internal/rbac"
+	"github.com/nebari-dev/nebi/internal/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestCreateWorkspace_PushAutoCreateDisabled sends a plain create request,
+// without any push-specific field, and checks that the server policy still
+// rejects it for a non-admin.
+func TestCreateWorkspace_PushAutoCreateDisabled(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.User{}, &models.Role{}, &models.Workspace{}, &models.Job{},
+		&models.Permission{}, &models.AuditLog{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := rbac.InitEnforcer(db, slog.Default()); err != nil {
+		t.Fatalf("rbac: %v", err)
+	}
+
+	exec, err := executor.NewLocalExecutor(&config.Config{
+		Storage: config.StorageConfig{WorkspacesDir: t.TempDir()},
+	})
+	if err != nil {
+		t.Fatalf("new executor: %v", err)
+	}
+	q := queue.NewMemoryQueue(10)
+	t.Cleanup(func() { q.Close() })
+
+	svc := service.New(db, q, exec, false, nil, rbac.NewDefaultProvider())
+	svc.ApplyWorkspacesConfig(config.WorkspacesConfig{AllowPushAutoCreate: false})
+	h := NewWorkspaceHandler(svc)
+
+	user := models.User{Username: "alice", Email: "alice@test"}
+	db.Create(&user)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user", &user)
+		c.Next()
+	})
+	r.POST("/api/v1/workspaces", h.CreateWorkspace)
+
+	body, _ := json.Marshal(map[string]string{
+		"name":      "pushed-ws",
+		"pixi_toml": "[workspace]\nname = \"pushed-ws\"\n",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/workspaces", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var workspaces int64
+	db.Model(&models.Workspace{}).Count(&workspaces)
+	if workspaces != 0 {
+		t.Errorf("expected no workspace to be created, got %d", workspaces)
+	}
+	var denied int64
+	db.Model(&models.AuditLog{}).Where("action = ?", audit.ActionAutoCreateDenied).Count(&denied)
+	if denied != 1 {
+		t.Errorf("expected 1 denial audit entry, got %d", denied)
+	}
+}
@@ -21,14 +21,16 @@ import (
 	"github.com/nebari-dev/nebi/internal/queue"
 	"github.com/nebari-dev/nebi/internal/rbac"
 	"github.com/nebari-dev/nebi/internal/service"
+	"github.com/nebari-dev/nebi/internal/versioncache"
 	"github.com/nebari-dev/nebi/internal/web"
+	"github.com/nebari-dev/nebi/internal/wsevents"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"gorm.io/gorm"
 )
 
 // NewRouter creates and configures the Gin router
-func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Executor, logBroker *logstream.LogBroker, valkeyClient interface{}, logger *slog.Logger) *gin.Engine {
+func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Executor, logBroker *logstream.LogBroker, events *wsevents.Broker, versionCache *versioncache.Cache, valkeyClient interface{}, logger *slog.Logger) *gin.Engine {
 	// Initialize RBAC enforcer and provider.
 	// In local mode the admin and workspace RBAC checks are unconditionally
 	// skipped (see RequireAdmin / RequireWorkspaceAccess middleware), so
@@ -142,6 +144,19 @@ func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Exe
 	// Codes are short-lived (30s) and single-use.
 	authCodeStore := auth.NewAuthCodeStore()
 
+	// Failed password logins are tracked in memory, so the lockout applies
+	// per server replica.
+	lockout := cfg.Auth.LoginLockout
+	loginLimiter := auth.NewLoginLimiter(
+		auth.NewMemoryLockoutStore(time.Duration(lockout.WindowSeconds+lockout.MaxLockoutSeconds)*time.Second),
+		auth.LockoutPolicy{
+			MaxAttempts: lockout.MaxAttempts,
+			Window:      time.Duration(lockout.WindowSeconds) * time.Second,
+			Lockout:     time.Duration(lockout.LockoutSeconds) * time.Second,
+			MaxLockout:  time.Duration(lockout.MaxLockoutSeconds) * time.Second,
+		},
+	)
+
 	// Session redirect: exchanges an OIDC proxy IdToken cookie for a
 	// single-use authorization code (RFC 6749 §4.1 pattern) and redirects
 	// to /login?code=<code>. The frontend exchanges the code for a JWT via
@@ -155,7 +170,7 @@ func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Exe
 	{
 		public.GET("/health", handlers.HealthCheck)
 		public.GET("/version", handlers.GetVersion)
-		public.POST("/auth/login", handlers.Login(authenticator))
+		public.POST("/auth/login", handlers.Login(authenticator, loginLimiter, db))
 
 		// Session check: exchanges proxy IdToken cookie for a Nebi JWT (no auth middleware)
 		public.GET("/auth/session", handlers.SessionCheck(sessionBasicAuth, cfg.Auth.ProxyAdminGroups))
@@ -184,6 +199,15 @@ func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Exe
 
 	// Initialize services and handlers
 	svc := service.New(db, q, exec, localMode, encKey, rbacProvider)
+	if events == nil {
+		events = wsevents.NewBroker()
+	}
+	svc.SetEventBroker(events)
+	svc.ApplyWorkspacesConfig(cfg.Workspaces)
+	if versionCache == nil {
+		versionCache = versioncache.New(cfg.Workspaces.VersionCacheBytes)
+	}
+	svc.SetVersionCache(versionCache)
 	adminSvc := service.NewAdminService(db, rbacProvider)
 	groupSvc := service.NewGroupService(db, rbacProvider)
 	registrySvc := service.NewRegistryService(db, encKey)
@@ -204,6 +228,7 @@ func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Exe
 		// Workspace endpoints
 		protected.GET("/workspaces", wsHandler.ListWorkspaces)
 		protected.POST("/workspaces", wsHandler.CreateWorkspace)
+		protected.GET("/workspaces/events", wsHandler.StreamWorkspaceEvents)
 
 		// Per-workspace operations with RBAC permission checks
 		ws := protected.Group("/workspaces/:id")
@@ -213,6 +238,9 @@ func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Exe
 			ws.GET("/packages", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.ListPackages)
 			ws.GET("/pixi-toml", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.GetPixiToml)
 			ws.GET("/collaborators", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.ListCollaborators)
+			ws.GET("/stats", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.GetWorkspaceStats)
+			ws.GET("/impact", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.GetWorkspaceImpact)
+			ws.POST("/clone", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.CloneWorkspace)
 
 			// Version operations (read permission)
 			ws.GET("/versions", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.ListVersions)
@@ -245,6 +273,10 @@ func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Exe
 			ws.GET("/publications", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.ListPublications)
 			ws.PATCH("/publications/:pubId", middleware.RequireWorkspaceAccess("write", localMode, rbacProvider), wsHandler.UpdatePublication)
 			ws.GET("/publish-defaults", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.GetPublishDefaults)
+			ws.GET("/default-platforms", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.GetDefaultPlatforms)
+			ws.PUT("/default-platforms", middleware.RequireWorkspaceAccess("write", localMode, rbacProvider), wsHandler.SetDefaultPlatforms)
+			ws.GET("/auto-latest-tag", middleware.RequireWorkspaceAccess("read", localMode, rbacProvider), wsHandler.GetAutoLatestTag)
+			ws.PUT("/auto-latest-tag", middleware.RequireWorkspaceAccess("write", localMode, rbacProvider), wsHandler.SetAutoLatestTag)
 		}
 
 		// Job endpoints
@@ -276,6 +308,7 @@ func NewRouter(cfg *config.Config, db *gorm.DB, q queue.Queue, exec executor.Exe
 			admin.POST("/users", adminHandler.CreateUser)
 			admin.GET("/users/:id", adminHandler.GetUser)
 			admin.GET("/users/:id/groups", adminHandler.ListUserGroups)
+			admin.GET("/users/:id/workspaces", adminHandler.ListUserWorkspaces)
 			admin.POST("/users/:id/toggle-admin", adminHandler.ToggleAdmin)
 			admin.DELETE("/users/:id", adminHandler.DeleteUser)
 
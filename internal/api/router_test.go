@@ -41,7 +41,7 @@ func buildTestRouter(t *testing.T, basePath string) http.Handler {
 	}
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	return NewRouter(cfg, database, queue.NewMemoryQueue(16), exec, nil, nil, logger)
+	return NewRouter(cfg, database, queue.NewMemoryQueue(16), exec, nil, nil, nil, nil, logger)
 }
 
 func TestCORSMiddlewareNoInvalidCredentialedWildcard(t *testing.T) {
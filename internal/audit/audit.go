@@ -9,7 +9,8 @@ import (
 	"gorm.io/gorm"
 )
 
-// LogAction records an audit log entry
+// LogAction records an audit log entry in the database and forwards it to
+// any configured sinks.
 func LogAction(db *gorm.DB, userID uuid.UUID, action, resource string, details interface{}) error {
 	detailsJSON, err := json.Marshal(details)
 	if err != nil {
@@ -24,7 +25,18 @@ func LogAction(db *gorm.DB, userID uuid.UUID, action, resource string, details i
 		Timestamp:   time.Now(),
 	}
 
-	return db.Create(&log).Error
+	if err := db.Create(&log).Error; err != nil {
+		return err
+	}
+
+	dispatch(Entry{
+		UserID:    log.UserID,
+		Action:    log.Action,
+		Resource:  log.Resource,
+		Details:   json.RawMessage(log.DetailsJSON),
+		Timestamp: log.Timestamp,
+	})
+	return nil
 }
 
 // Audit actions constants
@@ -47,17 +59,24 @@ const (
 	ActionRevokeGroupAdmin  = "revoke_group_admin"
 	ActionCreateWorkspace   = "create_workspace"
 	ActionDeleteWorkspace   = "delete_workspace"
+	ActionCloneWorkspace    = "clone_workspace"
 	ActionInstallPackage    = "install_package"
 	ActionRemovePackage     = "remove_package"
 	ActionSolveWorkspace    = "solve_workspace"
+	ActionSetPlatforms      = "set_default_platforms"
+	ActionSetAutoLatest     = "set_auto_latest_tag"
 	ActionInstallEnv        = "install_environment"
 	ActionUninstallEnv      = "uninstall_environment"
 	ActionPublishWorkspace  = "publish_workspace"
 	ActionImportWorkspace   = "import_workspace"
 	ActionPush              = "push"
+	ActionPull              = "pull"
+	ActionAutoCreateDenied  = "push_autocreate_denied"
 	ActionReassignTag       = "reassign_tag"
+	ActionEvictTag          = "evict_tag"
 	ActionLogin             = "login"
 	ActionLoginFailed       = "login_failed"
+	ActionLoginLockout      = "login_lockout"
 )
 
 // Resource types
@@ -0,0 +1,227 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/config"
+)
+
+// Entry is the payload delivered to audit sinks. It mirrors the stored
+// models.AuditLog row, with details decoded as JSON.
+type Entry struct {
+	UserID    uuid.UUID       `json:"user_id"`
+	Action    string          `json:"action"`
+	Resource  string          `json:"resource"`
+	Details   json.RawMessage `json:"details"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Sink receives a copy of every audit entry after it has been stored in the
+// database. The database remains the queryable audit trail; sinks forward
+// entries to external systems such as log files, syslog, or a SIEM.
+type Sink interface {
+	Name() string
+	Write(entry Entry) error
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []Sink
+)
+
+// SetSinks replaces the sinks that receive audit entries.
+func SetSinks(s ...Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = s
+}
+
+// dispatch forwards an entry to every configured sink. Sink failures are
+// logged and never fail the audited operation.
+func dispatch(entry Entry) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, s := range sinks {
+		if err := s.Write(entry); err != nil {
+			slog.Warn("Audit sink write failed", "sink", s.Name(), "action", entry.Action, "error", err)
+		}
+	}
+}
+
+// NewSinks builds the sinks enabled in cfg. A sink that cannot be set up is
+// logged and skipped so that audit forwarding problems never stop the
+// server from starting.
+func NewSinks(cfg config.AuditConfig) []Sink {
+	var result []Sink
+
+	if cfg.File.Path != "" {
+		s, err := NewFileSink(cfg.File.Path, int64(cfg.File.MaxSizeMB)*1024*1024, cfg.File.MaxBackups)
+		if err != nil {
+			slog.Error("Failed to set up audit file sink", "path", cfg.File.Path, "error", err)
+		} else {
+			result = append(result, s)
+		}
+	}
+
+	if cfg.Syslog.Enabled {
+		s, err := NewSyslogSink(cfg.Syslog.Network, cfg.Syslog.Address, cfg.Syslog.Tag)
+		if err != nil {
+			slog.Error("Failed to set up audit syslog sink", "address", cfg.Syslog.Address, "error", err)
+		} else {
+			result = append(result, s)
+		}
+	}
+
+	if cfg.HTTP.URL != "" {
+		timeout := time.Duration(cfg.HTTP.TimeoutSeconds) * time.Second
+		result = append(result, NewHTTPSink(cfg.HTTP.URL, cfg.HTTP.AuthHeader, timeout))
+	}
+
+	return result
+}
+
+// FileSink appends entries as JSON lines to a file, rotating it once it
+// grows past maxSize. Rotated files are named <path>.1 (newest) through
+// <path>.<maxBackups>.
+type FileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileSink opens (or creates) the audit file at path. A maxSize of 0
+// disables rotation.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+	s := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// Name implements Sink.
+func (s *FileSink) Name() string { return "file" }
+
+// Write implements Sink.
+func (s *FileSink) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return fmt.Errorf("rotating %s: %w", s.path, err)
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// rotate shifts <path>.N to <path>.N+1, dropping the oldest, moves the
+// current file to <path>.1, and reopens an empty file.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if s.maxBackups > 0 {
+		_ = os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxBackups))
+		for i := s.maxBackups - 1; i >= 1; i-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+		}
+		if err := os.Rename(s.path, s.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// HTTPSink POSTs each entry as JSON to an external log collector.
+type HTTPSink struct {
+	url        string
+	authHeader string
+	client     *http.Client
+}
+
+// NewHTTPSink creates a sink that POSTs to url. authHeader, if set, is sent
+// as the Authorization header. A zero timeout defaults to 5 seconds.
+func NewHTTPSink(url, authHeader string, timeout time.Duration) *HTTPSink {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &HTTPSink{url: url, authHeader: authHeader, client: &http.Client{Timeout: timeout}}
+}
+
+// Name implements Sink.
+func (s *HTTPSink) Name() string { return "http" }
+
+// Write implements Sink.
+func (s *HTTPSink) Write(entry Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.authHeader != "" {
+		req.Header.Set("Authorization", s.authHeader)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned %d", resp.StatusCode)
+	}
+	return nil
+}
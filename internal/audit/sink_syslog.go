@@ -0,0 +1,40 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"encoding/json"
+	"log/syslog"
+)
+
+// SyslogSink writes entries as JSON messages to syslog at the notice level
+// under the auth facility.
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to syslog. An empty network and address use the
+// local syslog daemon; otherwise network is "udp" or "tcp". An empty tag
+// defaults to "nebi".
+func NewSyslogSink(network, address, tag string) (*SyslogSink, error) {
+	if tag == "" {
+		tag = "nebi"
+	}
+	w, err := syslog.Dial(network, address, syslog.LOG_NOTICE|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{writer: w}, nil
+}
+
+// Name implements Sink.
+func (s *SyslogSink) Name() string { return "syslog" }
+
+// Write implements Sink.
+func (s *SyslogSink) Write(entry Entry) error {
+	msg, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.writer.Notice(string(msg))
+}
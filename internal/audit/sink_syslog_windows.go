@@ -0,0 +1,19 @@
+package audit
+
+import "errors"
+
+// SyslogSink is unavailable on Windows.
+type SyslogSink struct{}
+
+// NewSyslogSink always fails on Windows, which has no syslog.
+func NewSyslogSink(network, address, tag string) (*SyslogSink, error) {
+	return nil, errors.New("syslog is not supported on windows")
+}
+
+// Name implements Sink.
+func (s *SyslogSink) Name() string { return "syslog" }
+
+// Write implements Sink.
+func (s *SyslogSink) Write(entry Entry) error {
+	return errors.New("syslog is not supported on windows")
+}
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestHTTPSink_Payload(t *testing.T) {
+	var (
+		gotBody   []byte
+		gotAuth   string
+		gotCT     string
+		gotMethod string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotAuth = r.Header.Get("Authorization")
+		gotCT = r.Header.Get("Content-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	SetSinks(NewHTTPSink(srv.URL, "Bearer secret", time.Second))
+	t.Cleanup(func() { SetSinks() })
+
+	db := testDB(t)
+	userID := uuid.New()
+	wsID := uuid.New()
+	if err := Log(db, userID, ActionPush, ResourceWorkspace, wsID, map[string]interface{}{"tag": "v1"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+
+	if gotMethod != http.MethodPost {
+		t.Errorf("method = %s, want POST", gotMethod)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer secret")
+	}
+	if gotCT != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", gotCT)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v (%s)", err, gotBody)
+	}
+	for _, key := range []string{"user_id", "action", "resource", "details", "timestamp"} {
+		if _, ok := payload[key]; !ok {
+			t.Errorf("payload missing %q: %s", key, gotBody)
+		}
+	}
+	if payload["user_id"] != userID.String() || payload["action"] != ActionPush || payload["resource"] != ResourceWorkspace {
+		t.Errorf("unexpected payload: %s", gotBody)
+	}
+	details, _ := payload["details"].(map[string]interface{})
+	if details["tag"] != "v1" || details["resource_id"] != wsID.String() {
+		t.Errorf("details = %v, want tag and resource_id", details)
+	}
+}
+
+func TestSinkFailureIsNonFatal(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	SetSinks(NewHTTPSink(srv.URL, "", time.Second))
+	t.Cleanup(func() { SetSinks() })
+
+	db := testDB(t)
+	if err := LogAction(db, uuid.New(), ActionLogin, "user", nil); err != nil {
+		t.Fatalf("LogAction should not fail when a sink fails: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.AuditLog{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected entry stored in DB, got %d rows", count)
+	}
+}
+
+func TestFileSink_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s, err := NewFileSink(path, 200, 2)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	defer s.Close()
+
+	entry := Entry{UserID: uuid.New(), Action: ActionPull, Resource: "ws:x", Details: json.RawMessage(`{}`), Timestamp: time.Now()}
+	for i := 0; i < 6; i++ {
+		if err := s.Write(entry); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read current: %v", err)
+	}
+	if len(current) > 200 {
+		t.Errorf("current file is %d bytes, expected rotation at 200", len(current))
+	}
+	if !strings.Contains(string(current), `"action":"pull"`) {
+		t.Errorf("expected JSON lines, got %s", current)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected rotated file: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 backups, stat .3 err = %v", err)
+	}
+}
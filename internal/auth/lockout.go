@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// LockoutState is the failed-login history tracked for one key.
+type LockoutState struct {
+	Failures    int       // Failures in the current window
+	WindowStart time.Time // When the current window began
+	Lockouts    int       // Consecutive lockouts; drives the exponential backoff
+	LockedUntil time.Time // Zero when not locked out
+	LastSeen    time.Time // Last failure, used to expire idle state
+}
+
+// LockoutStore persists LockoutState by key. MemoryLockoutStore serves a
+// single node; a shared implementation (e.g. backed by Valkey) can be
+// swapped in for multi-replica deployments.
+type LockoutStore interface {
+	Get(key string) (LockoutState, bool)
+	Set(key string, state LockoutState)
+	Delete(key string)
+}
+
+// LockoutPolicy configures LoginLimiter.
+type LockoutPolicy struct {
+	MaxAttempts int           // Failures allowed within Window before locking out; 0 disables lockout
+	Window      time.Duration // Period over which failures are counted
+	Lockout     time.Duration // First lockout duration; doubles on each consecutive lockout
+	MaxLockout  time.Duration // Upper bound for the lockout duration
+}
+
+// LoginLimiter blocks login attempts for keys (usernames, client IPs) that
+// have failed too often. Each lockout in a row doubles the next one, up to
+// MaxLockout. Lockout history is forgotten once a key has been idle for
+// Window plus MaxLockout.
+type LoginLimiter struct {
+	store  LockoutStore
+	policy LockoutPolicy
+	now    func() time.Time
+}
+
+// NewLoginLimiter creates a limiter backed by store.
+func NewLoginLimiter(store LockoutStore, policy LockoutPolicy) *LoginLimiter {
+	if policy.MaxLockout < policy.Lockout {
+		policy.MaxLockout = policy.Lockout
+	}
+	return &LoginLimiter{store: store, policy: policy, now: time.Now}
+}
+
+// Enabled reports whether the limiter enforces lockouts.
+func (l *LoginLimiter) Enabled() bool {
+	return l != nil && l.policy.MaxAttempts > 0
+}
+
+// Blocked reports whether any of keys is locked out, and for how much longer.
+func (l *LoginLimiter) Blocked(keys ...string) (time.Duration, bool) {
+	if !l.Enabled() {
+		return 0, false
+	}
+	now := l.now()
+	var wait time.Duration
+	for _, key := range keys {
+		state, ok := l.store.Get(key)
+		if !ok {
+			continue
+		}
+		if d := state.LockedUntil.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, wait > 0
+}
+
+// Fail records a failed attempt for each key. It returns the longest lockout
+// started by this failure, or 0 if no key became locked out.
+func (l *LoginLimiter) Fail(keys ...string) time.Duration {
+	if !l.Enabled() {
+		return 0
+	}
+	now := l.now()
+	var locked time.Duration
+	for _, key := range keys {
+		state, ok := l.store.Get(key)
+		if ok && now.Sub(state.LastSeen) > l.policy.Window+l.policy.MaxLockout {
+			state = LockoutState{}
+		}
+		if state.WindowStart.IsZero() || now.Sub(state.WindowStart) > l.policy.Window {
+			state.WindowStart = now
+			state.Failures = 0
+		}
+		state.Failures++
+		state.LastSeen = now
+
+		if state.Failures >= l.policy.MaxAttempts {
+			d := l.policy.Lockout << state.Lockouts
+			if d > l.policy.MaxLockout || d <= 0 {
+				d = l.policy.MaxLockout
+			}
+			state.Lockouts++
+			state.LockedUntil = now.Add(d)
+			state.Failures = 0
+			state.WindowStart = time.Time{}
+			if d > locked {
+				locked = d
+			}
+		}
+		l.store.Set(key, state)
+	}
+	return locked
+}
+
+// Reset clears the history for each key, e.g. after a successful login.
+func (l *LoginLimiter) Reset(keys ...string) {
+	if !l.Enabled() {
+		return
+	}
+	for _, key := range keys {
+		l.store.Delete(key)
+	}
+}
+
+// MemoryLockoutStore is a thread-safe in-memory LockoutStore.
+type MemoryLockoutStore struct {
+	mu      sync.Mutex
+	entries map[string]LockoutState
+	ttl     time.Duration
+}
+
+// NewMemoryLockoutStore creates an in-memory store. Entries idle for longer
+// than ttl are dropped when new state is written.
+func NewMemoryLockoutStore(ttl time.Duration) *MemoryLockoutStore {
+	return &MemoryLockoutStore{entries: make(map[string]LockoutState), ttl: ttl}
+}
+
+// Get returns the state for key.
+func (s *MemoryLockoutStore) Get(key string) (LockoutState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.entries[key]
+	return state, ok
+}
+
+// Set stores the state for key and cleans up entries idle for longer than
+// the TTL, measured from the new state's LastSeen.
+func (s *MemoryLockoutStore) Set(key string, state LockoutState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := state.LastSeen
+	for k, e := range s.entries {
+		if now.Sub(e.LastSeen) > s.ttl && now.After(e.LockedUntil) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = state
+}
+
+// Delete removes the state for key.
+func (s *MemoryLockoutStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
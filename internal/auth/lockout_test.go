@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLoginLimiter(NewMemoryLockoutStore(time.Hour), LockoutPolicy{
+		MaxAttempts: 3,
+		Window:      time.Minute,
+		Lockout:     10 * time.Second,
+		MaxLockout:  30 * time.Second,
+	})
+	l.now = func() time.Time { return now }
+
+	keys := []string{"user:alice", "ip:10.0.0.1"}
+	for i := 0; i < 2; i++ {
+		if d := l.Fail(keys...); d != 0 {
+			t.Fatalf("failure %d should not lock out, got %s", i+1, d)
+		}
+	}
+	if _, blocked := l.Blocked(keys...); blocked {
+		t.Fatal("should not be blocked before reaching the limit")
+	}
+
+	if d := l.Fail(keys...); d != 10*time.Second {
+		t.Fatalf("expected 10s lockout, got %s", d)
+	}
+	if wait, blocked := l.Blocked("user:alice"); !blocked || wait != 10*time.Second {
+		t.Fatalf("expected alice blocked for 10s, got %s %v", wait, blocked)
+	}
+	if _, blocked := l.Blocked("user:bob", "ip:10.0.0.1"); !blocked {
+		t.Fatal("expected the IP to be blocked for other usernames")
+	}
+
+	// The next lockout doubles, and is capped at MaxLockout.
+	now = now.Add(11 * time.Second)
+	l.Fail(keys...)
+	l.Fail(keys...)
+	if d := l.Fail(keys...); d != 20*time.Second {
+		t.Fatalf("expected 20s backoff, got %s", d)
+	}
+	now = now.Add(21 * time.Second)
+	l.Fail(keys...)
+	l.Fail(keys...)
+	if d := l.Fail(keys...); d != 30*time.Second {
+		t.Fatalf("expected lockout capped at 30s, got %s", d)
+	}
+
+	// A successful login resets the username's history.
+	now = now.Add(31 * time.Second)
+	l.Reset("user:alice")
+	l.Fail("user:alice")
+	l.Fail("user:alice")
+	if d := l.Fail("user:alice"); d != 10*time.Second {
+		t.Fatalf("expected backoff to restart after reset, got %s", d)
+	}
+
+	// Failures spread over more than the window do not accumulate.
+	now = now.Add(11 * time.Second)
+	l.Fail("user:carol")
+	now = now.Add(2 * time.Minute)
+	l.Fail("user:carol")
+	if d := l.Fail("user:carol"); d != 0 {
+		t.Fatalf("expected failures outside the window to reset, got %s", d)
+	}
+}
+
+func TestLoginLimiterDisabled(t *testing.T) {
+	l := NewLoginLimiter(NewMemoryLockoutStore(time.Hour), LockoutPolicy{})
+	for i := 0; i < 10; i++ {
+		l.Fail("user:alice")
+	}
+	if _, blocked := l.Blocked("user:alice"); blocked {
+		t.Fatal("disabled limiter must never block")
+	}
+}
@@ -15,6 +15,17 @@ func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
 	return users, nil
 }
 
+// ListUserWorkspaces returns every workspace the given user can access,
+// with their role on each (admin only).
+func (c *Client) ListUserWorkspaces(ctx context.Context, userID string) ([]UserWorkspace, error) {
+	var workspaces []UserWorkspace
+	_, err := c.Get(ctx, fmt.Sprintf("/admin/users/%s/workspaces", userID), &workspaces)
+	if err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
 // ListAuditLogs returns audit logs with optional filters (admin only).
 func (c *Client) ListAuditLogs(ctx context.Context, userID, action string) ([]AuditLog, error) {
 	path := "/admin/audit-logs"
@@ -40,6 +40,11 @@ func NewWithoutAuth(baseURL string) *Client {
 	}
 }
 
+// BaseURL returns the API base URL the client talks to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
 // request performs an HTTP request and decodes the JSON response.
 func (c *Client) request(ctx context.Context, method, path string, body, result interface{}) (*http.Response, error) {
 	var bodyReader io.Reader
@@ -150,6 +155,18 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
 }
 
+// Message returns the server's error message from a JSON {"error": ...}
+// body, or the raw body when it is not JSON.
+func (e *APIError) Message() string {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(e.Body), &body); err == nil && body.Error != "" {
+		return body.Error
+	}
+	return e.Body
+}
+
 // IsNotFound returns true if the error is a 404 Not Found error.
 func IsNotFound(err error) bool {
 	if apiErr, ok := err.(*APIError); ok {
@@ -33,15 +33,26 @@ type Workspace struct {
 	PackageManager string    `json:"package_manager"`
 	SizeBytes      int64     `json:"size_bytes,omitempty"`
 	Owner          *User     `json:"owner,omitempty"`
+	TagLimit       *TagLimit `json:"tag_limit,omitempty"` // single-workspace responses only
+	AutoLatestTag  string    `json:"auto_latest_tag,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
+// TagLimit reports a workspace's tags against the server's tag limit.
+type TagLimit struct {
+	Count  int64  `json:"count"`
+	Max    int    `json:"max"` // 0 means unlimited
+	Policy string `json:"policy"`
+}
+
 // CreateWorkspaceRequest represents a request to create a workspace.
 type CreateWorkspaceRequest struct {
 	Name           string  `json:"name"`
 	PackageManager *string `json:"package_manager,omitempty"`
 	PixiToml       *string `json:"pixi_toml,omitempty"`
+	PixiLock       *string `json:"pixi_lock,omitempty"`   // with PixiToml, creates a tagged initial version
+	InitialTag     string  `json:"initial_tag,omitempty"` // tag for that version; defaults to the server's initial tag
 }
 
 // Package represents a package in a workspace.
@@ -131,6 +142,22 @@ type UpdateRegistryRequest struct {
 	Namespace *string `json:"namespace,omitempty"`
 }
 
+// SolveRequest represents a request to solve a workspace's environment.
+type SolveRequest struct {
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+// DefaultPlatforms represents the platforms a solve targets by default.
+type DefaultPlatforms struct {
+	Platforms []string `json:"platforms"`
+	Source    string   `json:"source"` // "workspace" or "manifest"
+}
+
+// AutoLatestTag represents a workspace's auto-latest tag setting.
+type AutoLatestTag struct {
+	Tag string `json:"tag"` // empty when not set
+}
+
 // RollbackRequest represents a request to rollback a workspace to a previous version.
 type RollbackRequest struct {
 	VersionNumber int `json:"version_number"`
@@ -151,6 +178,7 @@ type PushResponse struct {
 	ContentHash   string   `json:"content_hash"`
 	Deduplicated  bool     `json:"deduplicated"`
 	Tag           string   `json:"tag"`
+	EvictedTags   []string `json:"evicted_tags,omitempty"` // oldest tags removed to stay within the server's tag limit
 }
 
 // WorkspaceTag represents a server-side tag pointing to a version.
@@ -161,6 +189,29 @@ type WorkspaceTag struct {
 	UpdatedAt     string `json:"updated_at"`
 }
 
+// WorkspaceStats holds aggregate usage metrics for a workspace.
+type WorkspaceStats struct {
+	WorkspaceID   string  `json:"workspace_id"`
+	Name          string  `json:"name"`
+	VersionCount  int64   `json:"version_count"`
+	TagCount      int64   `json:"tag_count"`
+	PushCount     int64   `json:"push_count"`
+	PullCount     int64   `json:"pull_count"`
+	LastActivity  *string `json:"last_activity,omitempty"`
+	SizeBytes     int64   `json:"size_bytes"`
+	SizeFormatted string  `json:"size_formatted,omitempty"`
+}
+
+// WorkspaceImpact counts what deleting a workspace would remove.
+type WorkspaceImpact struct {
+	WorkspaceID       string `json:"workspace_id"`
+	Name              string `json:"name"`
+	VersionCount      int64  `json:"version_count"`
+	TagCount          int64  `json:"tag_count"`
+	PublicationCount  int64  `json:"publication_count"`
+	CollaboratorCount int64  `json:"collaborator_count"`
+}
+
 // Job represents a background job on the server.
 type Job struct {
 	ID          string                 `json:"id"`
@@ -203,3 +254,9 @@ type DashboardStats struct {
 	TotalDiskUsageBytes     int64  `json:"total_disk_usage_bytes"`
 	TotalDiskUsageFormatted string `json:"total_disk_usage_formatted"`
 }
+
+// UserWorkspace is a workspace together with the role a user holds on it.
+type UserWorkspace struct {
+	Workspace
+	Role string `json:"role"` // owner, editor or viewer
+}
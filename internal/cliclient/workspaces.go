@@ -3,6 +3,8 @@ package cliclient
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
 )
 
 // ListWorkspaces returns all workspaces.
@@ -35,6 +37,21 @@ func (c *Client) CreateWorkspace(ctx context.Context, req CreateWorkspaceRequest
 	return &ws, nil
 }
 
+// CloneWorkspace creates a new workspace named name from the newest version
+// of the workspace with the given ID, optionally with its full history.
+func (c *Client) CloneWorkspace(ctx context.Context, id, name string, history bool) (*Workspace, error) {
+	path := fmt.Sprintf("/workspaces/%s/clone", id)
+	if history {
+		path += "?history=true"
+	}
+	var ws Workspace
+	_, err := c.Post(ctx, path, map[string]string{"name": name}, &ws)
+	if err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
 // DeleteWorkspace deletes a workspace by ID.
 func (c *Client) DeleteWorkspace(ctx context.Context, id string) error {
 	_, err := c.Delete(ctx, fmt.Sprintf("/workspaces/%s", id))
@@ -71,14 +88,47 @@ func (c *Client) GetPublishDefaults(ctx context.Context, wsID string) (*PublishD
 	return &defaults, nil
 }
 
-// GetWorkspaceVersions returns versions for a workspace.
+// GetWorkspaceVersions returns all versions for a workspace, newest first,
+// following the server's paging cursor until the history is exhausted.
 func (c *Client) GetWorkspaceVersions(ctx context.Context, wsID string) ([]WorkspaceVersion, error) {
+	var all []WorkspaceVersion
+	before := 0
+	for {
+		versions, next, err := c.GetWorkspaceVersionsPage(ctx, wsID, 0, before)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, versions...)
+		if next == 0 || next == before {
+			return all, nil
+		}
+		before = next
+	}
+}
+
+// GetWorkspaceVersionsPage returns one page of versions, newest first.
+// limit 0 uses the server's cap; before 0 starts from the newest version.
+// The returned cursor is 0 when there are no older versions.
+func (c *Client) GetWorkspaceVersionsPage(ctx context.Context, wsID string, limit, before int) ([]WorkspaceVersion, int, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if before > 0 {
+		query.Set("before", strconv.Itoa(before))
+	}
+	path := fmt.Sprintf("/workspaces/%s/versions", wsID)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
 	var versions []WorkspaceVersion
-	_, err := c.Get(ctx, fmt.Sprintf("/workspaces/%s/versions", wsID), &versions)
+	resp, err := c.Get(ctx, path, &versions)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return versions, nil
+	next, _ := strconv.Atoi(resp.Header.Get("X-Next-Before"))
+	return versions, next, nil
 }
 
 // GetVersionPixiToml returns the pixi.toml for a specific version.
@@ -90,6 +140,16 @@ func (c *Client) GetVersionPixiToml(ctx context.Context, wsID string, version in
 	return content, nil
 }
 
+// PullVersionPixiToml returns the pixi.toml for a specific version and
+// records the download as a pull in the server's audit log.
+func (c *Client) PullVersionPixiToml(ctx context.Context, wsID string, version int32) (string, error) {
+	content, _, err := c.GetText(ctx, fmt.Sprintf("/workspaces/%s/versions/%d/pixi-toml?pull=true", wsID, version))
+	if err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
 // GetVersionPixiLock returns the pixi.lock for a specific version.
 func (c *Client) GetVersionPixiLock(ctx context.Context, wsID string, version int32) (string, error) {
 	content, _, err := c.GetText(ctx, fmt.Sprintf("/workspaces/%s/versions/%d/pixi-lock", wsID, version))
@@ -109,6 +169,26 @@ func (c *Client) GetWorkspaceTags(ctx context.Context, wsID string) ([]Workspace
 	return tags, nil
 }
 
+// GetWorkspaceStats returns aggregate usage metrics for a workspace.
+func (c *Client) GetWorkspaceStats(ctx context.Context, wsID string) (*WorkspaceStats, error) {
+	var stats WorkspaceStats
+	_, err := c.Get(ctx, fmt.Sprintf("/workspaces/%s/stats", wsID), &stats)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetWorkspaceImpact returns counts of what deleting a workspace would remove.
+func (c *Client) GetWorkspaceImpact(ctx context.Context, wsID string) (*WorkspaceImpact, error) {
+	var impact WorkspaceImpact
+	_, err := c.Get(ctx, fmt.Sprintf("/workspaces/%s/impact", wsID), &impact)
+	if err != nil {
+		return nil, err
+	}
+	return &impact, nil
+}
+
 // PushVersion pushes a new version to the server with a tag.
 func (c *Client) PushVersion(ctx context.Context, wsID string, req PushRequest) (*PushResponse, error) {
 	var resp PushResponse
@@ -150,6 +230,39 @@ func (c *Client) UninstallWorkspace(ctx context.Context, wsID string) (*Job, err
 	return &job, nil
 }
 
+// SolveWorkspace queues a solve (pixi lock) of the workspace's pixi.toml.
+// With no platforms the server uses the workspace's default platforms.
+func (c *Client) SolveWorkspace(ctx context.Context, wsID string, platforms []string) (*Job, error) {
+	var job Job
+	_, err := c.Post(ctx, fmt.Sprintf("/workspaces/%s/solve", wsID), SolveRequest{Platforms: platforms}, &job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetDefaultPlatforms returns the platforms a solve of the workspace
+// targets when none are given.
+func (c *Client) GetDefaultPlatforms(ctx context.Context, wsID string) (*DefaultPlatforms, error) {
+	var result DefaultPlatforms
+	_, err := c.Get(ctx, fmt.Sprintf("/workspaces/%s/default-platforms", wsID), &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetAutoLatestTag sets the tag every push to the workspace moves to the
+// pushed version. An empty tag clears the setting.
+func (c *Client) SetAutoLatestTag(ctx context.Context, wsID, tag string) (*AutoLatestTag, error) {
+	var result AutoLatestTag
+	_, err := c.Put(ctx, fmt.Sprintf("/workspaces/%s/auto-latest-tag", wsID), AutoLatestTag{Tag: tag}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // RollbackWorkspace queues a server-side rollback to a previous version.
 // Returns the queued Job (rollback runs asynchronously on the server).
 func (c *Client) RollbackWorkspace(ctx context.Context, wsID string, versionNumber int) (*Job, error) {
@@ -17,6 +17,8 @@ type Config struct {
 	Log            LogConfig            `mapstructure:"log"`
 	PackageManager PackageManagerConfig `mapstructure:"package_manager"`
 	Storage        StorageConfig        `mapstructure:"storage"`
+	Workspaces     WorkspacesConfig     `mapstructure:"workspaces"`
+	Audit          AuditConfig          `mapstructure:"audit"`
 }
 
 // IsLocalMode returns true when the server is running in local/desktop mode.
@@ -54,6 +56,17 @@ type AuthConfig struct {
 	ProxyAdminGroups   string `mapstructure:"proxy_admin_groups"`    // Comma-separated Keycloak/OIDC groups that grant admin (e.g., "admin,nebi-admin")
 	ProxyDefaultRole   string `mapstructure:"proxy_default_role"`    // Default role for proxy-authenticated users (default: "editor")
 	DeviceFlowClientID string `mapstructure:"device_flow_client_id"` // OIDC device flow public client ID (for RFC 8628 CLI login)
+
+	LoginLockout LoginLockoutConfig `mapstructure:"login_lockout"`
+}
+
+// LoginLockoutConfig holds brute-force protection for the password login
+// endpoint. Failures are counted per username and per client IP.
+type LoginLockoutConfig struct {
+	MaxAttempts       int `mapstructure:"max_attempts"`        // Failures within the window before locking out; 0 disables (default: 5)
+	WindowSeconds     int `mapstructure:"window_seconds"`      // Period over which failures are counted (default: 900)
+	LockoutSeconds    int `mapstructure:"lockout_seconds"`     // First lockout; doubles on each consecutive lockout (default: 60)
+	MaxLockoutSeconds int `mapstructure:"max_lockout_seconds"` // Upper bound for the lockout (default: 3600)
 }
 
 // QueueConfig holds job queue configuration
@@ -77,7 +90,49 @@ type PackageManagerConfig struct {
 
 // StorageConfig holds storage configuration
 type StorageConfig struct {
-	WorkspacesDir string `mapstructure:"workspaces_dir"` // Directory where workspaces are stored
+	WorkspacesDir            string `mapstructure:"workspaces_dir"`             // Directory where workspaces are stored
+	CompressVersions         bool   `mapstructure:"compress_versions"`          // Gzip version manifests and lock files at rest (default: false)
+	CompressExistingVersions bool   `mapstructure:"compress_existing_versions"` // Compress already-stored versions in the background on startup (default: false)
+}
+
+// WorkspacesConfig holds server-side workspace policy
+type WorkspacesConfig struct {
+	AllowPushAutoCreate bool   `mapstructure:"allow_push_autocreate"` // Allow `nebi push` to create missing workspaces; when false only admins can create them (default: true)
+	MaxVersionsListed   int    `mapstructure:"max_versions_listed"`   // Maximum versions returned per page by the versions endpoint (default: 100)
+	InitialTag          string `mapstructure:"initial_tag"`           // Tag given to the initial version of a workspace created with content (default: v0)
+	VersionCacheBytes   int64  `mapstructure:"version_cache_bytes"`   // In-memory cache budget for version file downloads in bytes (default: 0, disabled)
+	MaxTags             int    `mapstructure:"max_tags"`              // Maximum user tags per workspace (default: 0, unlimited)
+	TagLimitPolicy      string `mapstructure:"tag_limit_policy"`      // "reject" or "evict_oldest" when a push exceeds max_tags (default: reject)
+}
+
+// AuditConfig holds audit log sink configuration. Audit entries are always
+// stored in the database; enabled sinks receive a copy of each entry.
+type AuditConfig struct {
+	File   AuditFileConfig   `mapstructure:"file"`
+	Syslog AuditSyslogConfig `mapstructure:"syslog"`
+	HTTP   AuditHTTPConfig   `mapstructure:"http"`
+}
+
+// AuditFileConfig configures the rotating JSON-lines file sink
+type AuditFileConfig struct {
+	Path       string `mapstructure:"path"`        // File to append to (empty disables the sink)
+	MaxSizeMB  int    `mapstructure:"max_size_mb"` // Rotate once the file exceeds this size (default: 100)
+	MaxBackups int    `mapstructure:"max_backups"` // Rotated files to keep (default: 5)
+}
+
+// AuditSyslogConfig configures the syslog sink
+type AuditSyslogConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Network string `mapstructure:"network"` // "udp" or "tcp"; empty for the local syslog daemon
+	Address string `mapstructure:"address"` // e.g. "syslog.internal:514"; empty for the local syslog daemon
+	Tag     string `mapstructure:"tag"`     // Syslog tag (default: "nebi")
+}
+
+// AuditHTTPConfig configures the HTTP sink, which POSTs each entry as JSON
+type AuditHTTPConfig struct {
+	URL            string `mapstructure:"url"`             // Collector endpoint (empty disables the sink)
+	AuthHeader     string `mapstructure:"auth_header"`     // Optional Authorization header value
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // Per-request timeout (default: 5)
 }
 
 // Load reads configuration from file and environment variables
@@ -105,12 +160,31 @@ func Load() (*Config, error) {
 	v.SetDefault("auth.proxy_admin_groups", "admin")
 	v.SetDefault("auth.proxy_default_role", "editor")
 	v.SetDefault("auth.device_flow_client_id", "")
+	v.SetDefault("auth.login_lockout.max_attempts", 5)
+	v.SetDefault("auth.login_lockout.window_seconds", 900)
+	v.SetDefault("auth.login_lockout.lockout_seconds", 60)
+	v.SetDefault("auth.login_lockout.max_lockout_seconds", 3600)
 	v.SetDefault("queue.type", "memory")
 	v.SetDefault("queue.valkey_addr", "localhost:6379")
 	v.SetDefault("log.format", "text")
 	v.SetDefault("log.level", "info")
 	v.SetDefault("package_manager.default_type", "pixi")
 	v.SetDefault("storage.workspaces_dir", "./data/workspaces")
+	v.SetDefault("storage.compress_versions", false)
+	v.SetDefault("storage.compress_existing_versions", false)
+	v.SetDefault("workspaces.allow_push_autocreate", true)
+	v.SetDefault("workspaces.max_versions_listed", 100)
+	v.SetDefault("workspaces.initial_tag", "v0")
+	v.SetDefault("workspaces.version_cache_bytes", 0)
+	v.SetDefault("workspaces.max_tags", 0)
+	v.SetDefault("workspaces.tag_limit_policy", "reject")
+	v.SetDefault("audit.file.path", "")
+	v.SetDefault("audit.file.max_size_mb", 100)
+	v.SetDefault("audit.file.max_backups", 5)
+	v.SetDefault("audit.syslog.enabled", false)
+	v.SetDefault("audit.syslog.tag", "nebi")
+	v.SetDefault("audit.http.url", "")
+	v.SetDefault("audit.http.timeout_seconds", 5)
 
 	// Read from config file if exists
 	v.SetConfigName("config")
@@ -137,6 +211,8 @@ func Load() (*Config, error) {
 	_ = v.BindEnv("package_manager.pixi_path", "NEBI_PACKAGE_MANAGER_PIXI_PATH")
 	_ = v.BindEnv("package_manager.uv_path", "NEBI_PACKAGE_MANAGER_UV_PATH")
 	_ = v.BindEnv("storage.workspaces_dir", "NEBI_STORAGE_WORKSPACES_DIR")
+	_ = v.BindEnv("storage.compress_versions", "NEBI_STORAGE_COMPRESS_VERSIONS")
+	_ = v.BindEnv("storage.compress_existing_versions", "NEBI_STORAGE_COMPRESS_EXISTING_VERSIONS")
 	_ = v.BindEnv("server.host", "NEBI_SERVER_HOST")
 	_ = v.BindEnv("server.port", "NEBI_SERVER_PORT")
 	_ = v.BindEnv("server.mode", "NEBI_SERVER_MODE")
@@ -150,10 +226,26 @@ func Load() (*Config, error) {
 	_ = v.BindEnv("auth.oidc_client_id", "NEBI_AUTH_OIDC_CLIENT_ID")
 	_ = v.BindEnv("auth.oidc_client_secret", "NEBI_AUTH_OIDC_CLIENT_SECRET")
 	_ = v.BindEnv("auth.oidc_redirect_url", "NEBI_AUTH_OIDC_REDIRECT_URL")
+	_ = v.BindEnv("auth.login_lockout.max_attempts", "NEBI_AUTH_LOGIN_LOCKOUT_MAX_ATTEMPTS")
+	_ = v.BindEnv("auth.login_lockout.window_seconds", "NEBI_AUTH_LOGIN_LOCKOUT_WINDOW_SECONDS")
+	_ = v.BindEnv("auth.login_lockout.lockout_seconds", "NEBI_AUTH_LOGIN_LOCKOUT_LOCKOUT_SECONDS")
+	_ = v.BindEnv("auth.login_lockout.max_lockout_seconds", "NEBI_AUTH_LOGIN_LOCKOUT_MAX_LOCKOUT_SECONDS")
 	_ = v.BindEnv("queue.type", "NEBI_QUEUE_TYPE")
 	_ = v.BindEnv("queue.valkey_addr", "NEBI_QUEUE_VALKEY_ADDR")
 	_ = v.BindEnv("log.format", "NEBI_LOG_FORMAT")
 	_ = v.BindEnv("log.level", "NEBI_LOG_LEVEL")
+	_ = v.BindEnv("workspaces.allow_push_autocreate", "NEBI_WORKSPACES_ALLOW_PUSH_AUTOCREATE")
+	_ = v.BindEnv("workspaces.max_versions_listed", "NEBI_WORKSPACES_MAX_VERSIONS_LISTED")
+	_ = v.BindEnv("workspaces.initial_tag", "NEBI_WORKSPACES_INITIAL_TAG")
+	_ = v.BindEnv("workspaces.version_cache_bytes", "NEBI_WORKSPACES_VERSION_CACHE_BYTES")
+	_ = v.BindEnv("workspaces.max_tags", "NEBI_WORKSPACES_MAX_TAGS")
+	_ = v.BindEnv("workspaces.tag_limit_policy", "NEBI_WORKSPACES_TAG_LIMIT_POLICY")
+	_ = v.BindEnv("audit.file.path", "NEBI_AUDIT_FILE_PATH")
+	_ = v.BindEnv("audit.syslog.enabled", "NEBI_AUDIT_SYSLOG_ENABLED")
+	_ = v.BindEnv("audit.syslog.network", "NEBI_AUDIT_SYSLOG_NETWORK")
+	_ = v.BindEnv("audit.syslog.address", "NEBI_AUDIT_SYSLOG_ADDRESS")
+	_ = v.BindEnv("audit.http.url", "NEBI_AUDIT_HTTP_URL")
+	_ = v.BindEnv("audit.http.auth_header", "NEBI_AUDIT_HTTP_AUTH_HEADER")
 
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
@@ -173,6 +265,12 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid mode %q: must be \"local\" or \"team\"", cfg.Mode)
 	}
 
+	switch cfg.Workspaces.TagLimitPolicy {
+	case "", "reject", "evict_oldest":
+	default:
+		return nil, fmt.Errorf("invalid workspaces.tag_limit_policy %q: must be \"reject\" or \"evict_oldest\"", cfg.Workspaces.TagLimitPolicy)
+	}
+
 	// Team mode exposes JWT-authenticated network endpoints, so its signing
 	// secret must not be empty, the shipped default, or too short to resist
 	// brute force. Local mode never reaches this auth path (it uses
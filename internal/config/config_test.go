@@ -71,3 +71,43 @@ func TestLoad_LocalMode_AllowsDefaultJWTSecret(t *testing.T) {
 		t.Fatalf("unexpected error in local mode: %v", err)
 	}
 }
+
+func TestLoad_AllowPushAutoCreate(t *testing.T) {
+	isolate(t)
+	t.Setenv("NEBI_MODE", "local")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Workspaces.AllowPushAutoCreate {
+		t.Error("expected workspaces.allow_push_autocreate to default to true")
+	}
+
+	t.Setenv("NEBI_WORKSPACES_ALLOW_PUSH_AUTOCREATE", "false")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Workspaces.AllowPushAutoCreate {
+		t.Error("expected NEBI_WORKSPACES_ALLOW_PUSH_AUTOCREATE=false to disable push auto-create")
+	}
+}
+
+func TestLoad_TagLimitPolicy(t *testing.T) {
+	isolate(t)
+	t.Setenv("NEBI_MODE", "local")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Workspaces.MaxTags != 0 || cfg.Workspaces.TagLimitPolicy != "reject" {
+		t.Errorf("expected unlimited tags with reject policy by default, got %d/%q", cfg.Workspaces.MaxTags, cfg.Workspaces.TagLimitPolicy)
+	}
+
+	t.Setenv("NEBI_WORKSPACES_TAG_LIMIT_POLICY", "drop_newest")
+	if _, err := Load(); err == nil {
+		t.Error("expected an unknown tag_limit_policy to be rejected")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"sort"
+	"strings"
 )
 
 // Hash computes a deterministic hash of pixi.toml + pixi.lock content.
@@ -17,6 +18,22 @@ func Hash(pixiToml, pixiLock string) string {
 	return fmt.Sprintf("sha-%x", h.Sum(nil)[:6])
 }
 
+// IsHashTag reports whether tag has the shape produced by Hash ("sha-"
+// followed by 12 lowercase hex characters). Such tags are immutable: they
+// always name the same content, unlike user tags or "latest".
+func IsHashTag(tag string) bool {
+	hex, ok := strings.CutPrefix(tag, "sha-")
+	if !ok || len(hex) != 12 {
+		return false
+	}
+	for _, r := range hex {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 // AssetRef names one asset layer for bundle hashing: bundle-relative
 // path (forward slashes, matches OCI AnnotationTitle) plus its OCI
 // content digest (e.g. "sha256:deadbeef…"). Used by HashBundle.
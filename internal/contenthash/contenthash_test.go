@@ -41,3 +41,23 @@ func TestHash_MatchesExpected(t *testing.T) {
 		t.Fatal("not deterministic")
 	}
 }
+
+func TestIsHashTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{Hash("a", "b"), true},
+		{"sha-0123456789ab", true},
+		{"sha-0123456789", false},
+		{"sha-0123456789abcd", false},
+		{"sha-0123456789AB", false},
+		{"latest", false},
+		{"v1.2", false},
+	}
+	for _, tt := range tests {
+		if got := IsHashTag(tt.tag); got != tt.want {
+			t.Errorf("IsHashTag(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
@@ -131,6 +131,53 @@ func Migrate(db *gorm.DB) error {
 	return nil
 }
 
+// CompressVersionContent gzip-compresses the manifest and lock of every
+// stored version that is still plain text, batchSize rows at a time, and
+// returns the number of versions compressed. It is safe to run while the
+// server is handling requests and to interrupt; the next run resumes with
+// the remaining plain rows.
+func CompressVersionContent(db *gorm.DB, batchSize int) (int, error) {
+	compressed := 0
+	for {
+		var batch []models.WorkspaceVersion
+		err := db.Unscoped().
+			Select("id", "lock_file_content", "manifest_content", "content_encoding").
+			Where("content_encoding = ?", "").
+			Order("id").
+			Limit(batchSize).
+			Find(&batch).Error
+		if err != nil {
+			return compressed, err
+		}
+		if len(batch) == 0 {
+			return compressed, nil
+		}
+
+		for _, v := range batch {
+			lock, err := models.EncodeVersionContent(v.LockFileContent)
+			if err != nil {
+				return compressed, err
+			}
+			manifest, err := models.EncodeVersionContent(v.ManifestContent)
+			if err != nil {
+				return compressed, err
+			}
+			// UpdateColumns skips hooks, so the encoded values are written as-is.
+			err = db.Unscoped().Model(&models.WorkspaceVersion{}).
+				Where("id = ? AND content_encoding = ?", v.ID, "").
+				UpdateColumns(map[string]interface{}{
+					"lock_file_content": lock,
+					"manifest_content":  manifest,
+					"content_encoding":  models.ContentEncodingGzip,
+				}).Error
+			if err != nil {
+				return compressed, err
+			}
+			compressed++
+		}
+	}
+}
+
 // seedDefaultRoles creates default roles (admin, owner, editor, viewer)
 func seedDefaultRoles(db *gorm.DB) error {
 	defaultRoles := []models.Role{
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCompressVersionContent(t *testing.T) {
+	database, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := database.AutoMigrate(&models.WorkspaceVersion{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	wsID := uuid.New()
+	for i := 0; i < 3; i++ {
+		v := models.WorkspaceVersion{WorkspaceID: wsID, ManifestContent: "[workspace]\n", LockFileContent: "version: 6\n", CreatedBy: uuid.New()}
+		if err := database.Create(&v).Error; err != nil {
+			t.Fatalf("create version: %v", err)
+		}
+	}
+
+	n, err := CompressVersionContent(database, 2)
+	if err != nil {
+		t.Fatalf("CompressVersionContent: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("compressed %d versions, want 3", n)
+	}
+
+	var plain int64
+	database.Model(&models.WorkspaceVersion{}).Where("content_encoding = ?", "").Count(&plain)
+	if plain != 0 {
+		t.Errorf("%d versions left uncompressed", plain)
+	}
+
+	var versions []models.WorkspaceVersion
+	database.Find(&versions)
+	for _, v := range versions {
+		if v.LockFileContent != "version: 6\n" || v.ManifestContent != "[workspace]\n" {
+			t.Errorf("version %d not decoded on read: %q / %q", v.VersionNumber, v.ManifestContent, v.LockFileContent)
+		}
+	}
+
+	// A second run has nothing left to do.
+	if n, err := CompressVersionContent(database, 2); err != nil || n != 0 {
+		t.Errorf("second run = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,101 @@
+package diff
+
+import (
+	"fmt"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// environmentDependencySections are the manifest tables merged by
+// ResolveEnvironment.
+var environmentDependencySections = []string{"dependencies", "pypi-dependencies"}
+
+// ResolveEnvironment computes the dependency set that a pixi environment
+// actually uses by merging the default feature (unless the environment sets
+// no-default-feature) with the environment's features, the same way pixi
+// does: features listed first take precedence, and the default feature has
+// the lowest priority. Target-specific tables are merged per platform.
+//
+// The result is a pixi.toml containing only the merged [dependencies],
+// [pypi-dependencies], and [target.<platform>.*] tables, so it can be passed
+// to CompareToml to diff effective dependencies rather than raw tables.
+func ResolveEnvironment(manifest []byte, env string) ([]byte, error) {
+	var m map[string]interface{}
+	if err := toml.Unmarshal(manifest, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse pixi.toml: %w", err)
+	}
+
+	if env != "default" {
+		envs, _ := m["environments"].(map[string]interface{})
+		if _, ok := envs[env]; !ok {
+			return nil, fmt.Errorf("environment %q is not defined in pixi.toml", env)
+		}
+	}
+
+	// Apply tables from lowest to highest priority so later writes win.
+	var tables []map[string]interface{}
+	if includesDefaultFeature(m, env) {
+		tables = append(tables, m)
+	}
+	features, _ := m["feature"].(map[string]interface{})
+	names := environmentFeatures(m, env)
+	for i := len(names) - 1; i >= 0; i-- {
+		t, ok := features[names[i]].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("environment %q uses undefined feature %q", env, names[i])
+		}
+		tables = append(tables, t)
+	}
+
+	resolved := make(map[string]interface{})
+	targets := make(map[string]interface{})
+	for _, t := range tables {
+		mergeDependencyTables(resolved, t)
+		tt, _ := t["target"].(map[string]interface{})
+		for platform, v := range tt {
+			pt, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			dst, _ := targets[platform].(map[string]interface{})
+			if dst == nil {
+				dst = make(map[string]interface{})
+				targets[platform] = dst
+			}
+			mergeDependencyTables(dst, pt)
+		}
+	}
+	for platform, v := range targets {
+		if len(v.(map[string]interface{})) == 0 {
+			delete(targets, platform)
+		}
+	}
+	if len(targets) > 0 {
+		resolved["target"] = targets
+	}
+
+	out, err := toml.Marshal(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resolved environment: %w", err)
+	}
+	return out, nil
+}
+
+// mergeDependencyTables copies the dependency tables of src into dst,
+// overwriting entries that already exist.
+func mergeDependencyTables(dst, src map[string]interface{}) {
+	for _, section := range environmentDependencySections {
+		table, ok := src[section].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		merged, _ := dst[section].(map[string]interface{})
+		if merged == nil {
+			merged = make(map[string]interface{})
+			dst[section] = merged
+		}
+		for name, spec := range table {
+			merged[name] = spec
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+const featureManifest = `
+[workspace]
+name = "ml"
+channels = ["conda-forge"]
+platforms = ["linux-64"]
+
+[dependencies]
+python = "3.11.*"
+numpy = "*"
+
+[feature.cuda.dependencies]
+cudatoolkit = "11.8.*"
+numpy = ">=1.26"
+
+[feature.cuda.target.linux-64.dependencies]
+nccl = "*"
+
+[feature.test.dependencies]
+pytest = "*"
+
+[environments]
+gpu = ["cuda"]
+test = { features = ["test"], no-default-feature = true }
+`
+
+func TestResolveEnvironment(t *testing.T) {
+	gpu, err := ResolveEnvironment([]byte(featureManifest), "gpu")
+	if err != nil {
+		t.Fatalf("ResolveEnvironment(gpu): %v", err)
+	}
+	for _, want := range []string{"python = '3.11.*'", "numpy = '>=1.26'", "cudatoolkit", "nccl"} {
+		if !strings.Contains(string(gpu), want) {
+			t.Errorf("gpu environment missing %q:\n%s", want, gpu)
+		}
+	}
+	if strings.Contains(string(gpu), "pytest") {
+		t.Errorf("gpu environment should not include the test feature:\n%s", gpu)
+	}
+
+	test, err := ResolveEnvironment([]byte(featureManifest), "test")
+	if err != nil {
+		t.Fatalf("ResolveEnvironment(test): %v", err)
+	}
+	if !strings.Contains(string(test), "pytest") || strings.Contains(string(test), "python") {
+		t.Errorf("test environment should only contain the test feature:\n%s", test)
+	}
+
+	if _, err := ResolveEnvironment([]byte(featureManifest), "missing"); err == nil {
+		t.Error("expected error for undefined environment")
+	}
+}
+
+func TestResolveEnvironment_RearrangedFeatures(t *testing.T) {
+	// Moving cudatoolkit from the feature into a second feature used by the
+	// same environment changes the raw tables but not the effective set.
+	rearranged := strings.Replace(featureManifest, "[feature.test.dependencies]", "[feature.extra.dependencies]\ncudatoolkit = \"11.8.*\"\n\n[feature.test.dependencies]", 1)
+	rearranged = strings.Replace(rearranged, "cudatoolkit = \"11.8.*\"\nnumpy", "numpy", 1)
+	rearranged = strings.Replace(rearranged, `gpu = ["cuda"]`, `gpu = ["cuda", "extra"]`, 1)
+
+	oldEnv, err := ResolveEnvironment([]byte(featureManifest), "gpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newEnv, err := ResolveEnvironment([]byte(rearranged), "gpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := CompareToml(oldEnv, newEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.HasChanges() {
+		t.Errorf("expected no effective changes, got %+v", d.Changes)
+	}
+
+	raw, err := CompareToml([]byte(featureManifest), []byte(rearranged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !raw.HasChanges() {
+		t.Error("expected raw manifests to differ")
+	}
+}
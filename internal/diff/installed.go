@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nebari-dev/nebi/internal/pkgmgr"
+	"gopkg.in/yaml.v3"
+)
+
+// CompareInstalled compares the packages a v6 pixi.lock pins for one
+// environment and platform with the packages actually installed. In the
+// returned summary, Added lists packages installed but not locked, Removed
+// lists packages locked but not installed, and Updated lists packages
+// installed at a different version (OldVersion is the locked one). Names
+// are matched case-insensitively, and PyPI names with PEP 503 folding.
+func CompareInstalled(lock []byte, installed []pkgmgr.Package, env, platform string) (*LockSummary, error) {
+	locked, err := lockedPackages(lock, env, platform)
+	if err != nil {
+		return nil, err
+	}
+
+	actual := make(map[string]string, len(installed))
+	for _, p := range installed {
+		kind := p.Kind
+		if kind == "" {
+			kind = "conda"
+		}
+		actual[installedKey(p.Name, kind)] = p.Version
+	}
+
+	summary := diffPackages(locked, actual)
+	summary.Added = trimKinds(summary.Added)
+	summary.Removed = trimKinds(summary.Removed)
+	for i := range summary.Updated {
+		summary.Updated[i].Name = trimKind(summary.Updated[i].Name)
+	}
+	return summary, nil
+}
+
+// lockedPackages maps "kind:name" to version for the packages that env
+// pins on platform.
+func lockedPackages(lock []byte, env, platform string) (map[string]string, error) {
+	e, ok := parseV6Environments(lock)[env]
+	if !ok {
+		return nil, fmt.Errorf("environment %q not found in pixi.lock", env)
+	}
+	refs, ok := e.Packages[platform]
+	if !ok {
+		return nil, fmt.Errorf("environment %q has no packages for platform %s in pixi.lock", env, platform)
+	}
+	urls := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		if u := v6PackageURL(ref); u != "" {
+			urls[u] = true
+		}
+	}
+
+	var lf struct {
+		Packages []map[string]interface{} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(lock, &lf); err != nil {
+		return nil, fmt.Errorf("failed to parse pixi.lock: %w", err)
+	}
+
+	packages := make(map[string]string, len(urls))
+	for _, entry := range lf.Packages {
+		if !urls[v6PackageURL(entry)] {
+			continue
+		}
+		name, version := extractV6Package(entry)
+		if name == "" {
+			continue
+		}
+		kind := "conda"
+		if _, ok := entry["pypi"]; ok {
+			kind = "pypi"
+		}
+		packages[installedKey(name, kind)] = version
+	}
+	return packages, nil
+}
+
+// installedKey identifies a package independently of how its name is
+// spelled. The kind prefix is stripped again before reporting, except that
+// PyPI packages keep a " (pypi)" marker.
+func installedKey(name, kind string) string {
+	return kind + ":" + normalizePackageName(name, kind)
+}
+
+func trimKind(key string) string {
+	kind, name, _ := strings.Cut(key, ":")
+	if kind == "pypi" {
+		if n, version, ok := strings.Cut(name, " "); ok {
+			return n + " (pypi) " + version
+		}
+		return name + " (pypi)"
+	}
+	return name
+}
+
+func trimKinds(keys []string) []string {
+	for i, k := range keys {
+		keys[i] = trimKind(k)
+	}
+	return keys
+}
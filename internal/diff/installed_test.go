@@ -0,0 +1,52 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/pkgmgr"
+)
+
+func TestCompareInstalled(t *testing.T) {
+	installed := []pkgmgr.Package{
+		{Name: "numpy", Version: "1.26.0", Kind: "conda"},
+		{Name: "requests", Version: "2.31.0", Kind: "pypi"},
+		{Name: "Rich_Text", Version: "1.0", Kind: "pypi"},
+	}
+
+	summary, err := CompareInstalled([]byte(staleCheckLock), installed, "default", "linux-64")
+	if err != nil {
+		t.Fatalf("CompareInstalled() error: %v", err)
+	}
+
+	if want := []string{"rich-text (pypi) 1.0"}; !reflect.DeepEqual(summary.Added, want) {
+		t.Errorf("Added = %v, want %v", summary.Added, want)
+	}
+	if len(summary.Removed) != 0 {
+		t.Errorf("Removed = %v, want none", summary.Removed)
+	}
+	want := []PackageUpdate{{Name: "numpy", OldVersion: "1.24.0", NewVersion: "1.26.0"}}
+	if !reflect.DeepEqual(summary.Updated, want) {
+		t.Errorf("Updated = %v, want %v", summary.Updated, want)
+	}
+}
+
+func TestCompareInstalled_NotInstalled(t *testing.T) {
+	summary, err := CompareInstalled([]byte(staleCheckLock), nil, "default", "win-64")
+	if err != nil {
+		t.Fatalf("CompareInstalled() error: %v", err)
+	}
+	want := []string{"numpy 1.24.0", "requests (pypi) 2.31.0"}
+	if !reflect.DeepEqual(summary.Removed, want) {
+		t.Errorf("Removed = %v, want %v", summary.Removed, want)
+	}
+}
+
+func TestCompareInstalled_UnknownEnvironmentOrPlatform(t *testing.T) {
+	if _, err := CompareInstalled([]byte(staleCheckLock), nil, "gpu", "linux-64"); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+	if _, err := CompareInstalled([]byte(staleCheckLock), nil, "default", "osx-arm64"); err == nil {
+		t.Error("expected error for unlocked platform")
+	}
+}
@@ -17,6 +17,11 @@ type LockSummary struct {
 	Added           []string        `json:"added,omitempty"`
 	Removed         []string        `json:"removed,omitempty"`
 	Updated         []PackageUpdate `json:"updated,omitempty"`
+
+	// Platforms breaks the changes down per platform (e.g. "linux-64") for
+	// v6 lock files when LockOptions.GroupByPlatform is set. Only platforms
+	// with changes are included.
+	Platforms map[string]*LockSummary `json:"platforms,omitempty"`
 }
 
 // PackageUpdate represents a package version change.
@@ -26,25 +31,141 @@ type PackageUpdate struct {
 	NewVersion string `json:"new"`
 }
 
+// LockOptions scopes a lock file comparison. The zero value compares the
+// whole file.
+type LockOptions struct {
+	// Environment limits the comparison to the packages referenced by
+	// environments.<name> in a v6 lock file.
+	Environment string
+
+	// GroupByPlatform fills LockSummary.Platforms. It re-reads both files
+	// per platform, so it is left off unless the breakdown is shown.
+	GroupByPlatform bool
+}
+
 // CompareLock compares two pixi.lock file contents and produces a LockSummary.
 // It parses the YAML structure and identifies added, removed, and updated packages.
 func CompareLock(oldContent, newContent []byte) (*LockSummary, error) {
-	oldPkgs, err := parseLockPackages(oldContent)
+	return CompareLockWithOptions(oldContent, newContent, LockOptions{})
+}
+
+// CompareLockWithOptions is like CompareLock but scopes the comparison as
+// described by opts. It returns an error when a requested environment is
+// present in neither lock file.
+func CompareLockWithOptions(oldContent, newContent []byte, opts LockOptions) (*LockSummary, error) {
+	if opts.Environment != "" &&
+		!lockHasEnvironment(oldContent, opts.Environment) &&
+		!lockHasEnvironment(newContent, opts.Environment) {
+		return nil, fmt.Errorf("environment %q not found in lock file", opts.Environment)
+	}
+
+	oldPkgs, err := parseLockPackages(oldContent, opts)
 	if err != nil {
 		// Fall back to simple byte comparison if parsing fails
 		return simpleLockSummary(oldContent, newContent), nil
 	}
 
-	newPkgs, err := parseLockPackages(newContent)
+	newPkgs, err := parseLockPackages(newContent, opts)
 	if err != nil {
 		return simpleLockSummary(oldContent, newContent), nil
 	}
 
-	return diffPackages(oldPkgs, newPkgs), nil
+	summary := diffPackages(oldPkgs, newPkgs)
+	if opts.GroupByPlatform {
+		summary.Platforms = diffPlatforms(
+			platformPackages(oldContent, opts.Environment),
+			platformPackages(newContent, opts.Environment),
+		)
+	}
+	return summary, nil
+}
+
+// platformPackages maps each platform of a v6 lock file to the packages
+// installed for it, across all environments or only env when set. It
+// returns nil for lock files without v6 environments.
+func platformPackages(content []byte, env string) map[string]map[string]string {
+	urls := make(map[string]map[string]bool)
+	for name, e := range parseV6Environments(content) {
+		if env != "" && name != env {
+			continue
+		}
+		for platform, refs := range e.Packages {
+			if urls[platform] == nil {
+				urls[platform] = make(map[string]bool)
+			}
+			for _, ref := range refs {
+				if u := v6PackageURL(ref); u != "" {
+					urls[platform][u] = true
+				}
+			}
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+
+	var lf struct {
+		Packages []map[string]interface{} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(content, &lf); err != nil {
+		return nil
+	}
+
+	result := make(map[string]map[string]string, len(urls))
+	for platform, set := range urls {
+		packages := make(map[string]string)
+		for _, entry := range lf.Packages {
+			if !set[v6PackageURL(entry)] {
+				continue
+			}
+			name, version := extractV6Package(entry)
+			if name != "" {
+				if _, exists := packages[name]; !exists {
+					packages[name] = version
+				}
+			}
+		}
+		result[platform] = packages
+	}
+	return result
+}
+
+// diffPlatforms diffs per-platform package sets. A platform missing on one
+// side is treated as having no packages there.
+func diffPlatforms(oldPlatforms, newPlatforms map[string]map[string]string) map[string]*LockSummary {
+	all := make(map[string]bool)
+	for p := range oldPlatforms {
+		all[p] = true
+	}
+	for p := range newPlatforms {
+		all[p] = true
+	}
+
+	var result map[string]*LockSummary
+	for platform := range all {
+		oldPkgs, newPkgs := oldPlatforms[platform], newPlatforms[platform]
+		if oldPkgs == nil {
+			oldPkgs = map[string]string{}
+		}
+		if newPkgs == nil {
+			newPkgs = map[string]string{}
+		}
+		s := diffPackages(oldPkgs, newPkgs)
+		if s.PackagesAdded+s.PackagesRemoved+s.PackagesUpdated == 0 {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]*LockSummary)
+		}
+		result[platform] = s
+	}
+	return result
 }
 
 // parseLockPackages extracts a deduplicated map of packages from lock file content.
-func parseLockPackages(content []byte) (map[string]string, error) {
+// When opts names an environment, only v6 packages referenced by that
+// environment are returned.
+func parseLockPackages(content []byte, opts LockOptions) (map[string]string, error) {
 	if len(content) == 0 {
 		return make(map[string]string), nil
 	}
@@ -54,8 +175,17 @@ func parseLockPackages(content []byte) (map[string]string, error) {
 		return make(map[string]string), fmt.Errorf("failed to parse lock YAML: %w", err)
 	}
 
+	if opts.Environment != "" {
+		urls := environmentPackageURLs(content, opts.Environment)
+		packages := parseV6Packages(content, urls)
+		if packages == nil {
+			packages = make(map[string]string)
+		}
+		return packages, nil
+	}
+
 	// Try v6 format
-	packages := parseV6Packages(content)
+	packages := parseV6Packages(content, nil)
 	if len(packages) > 0 {
 		return packages, nil
 	}
@@ -71,8 +201,59 @@ func parseLockPackages(content []byte) (map[string]string, error) {
 	return packages, nil
 }
 
-// parseV6Packages parses pixi.lock v6 format.
-func parseV6Packages(content []byte) map[string]string {
+// v6Environment is the per-environment section of a v6 lock file. Packages
+// maps a platform to the package URLs installed for it.
+type v6Environment struct {
+	Packages map[string][]map[string]interface{} `yaml:"packages"`
+}
+
+func parseV6Environments(content []byte) map[string]v6Environment {
+	var lf struct {
+		Environments map[string]v6Environment `yaml:"environments"`
+	}
+	if err := yaml.Unmarshal(content, &lf); err != nil {
+		return nil
+	}
+	return lf.Environments
+}
+
+func lockHasEnvironment(content []byte, env string) bool {
+	_, ok := parseV6Environments(content)[env]
+	return ok
+}
+
+// environmentPackageURLs returns the set of package URLs referenced by the
+// named environment across all of its platforms.
+func environmentPackageURLs(content []byte, env string) map[string]bool {
+	urls := make(map[string]bool)
+	e, ok := parseV6Environments(content)[env]
+	if !ok {
+		return urls
+	}
+	for _, refs := range e.Packages {
+		for _, ref := range refs {
+			if u := v6PackageURL(ref); u != "" {
+				urls[u] = true
+			}
+		}
+	}
+	return urls
+}
+
+// v6PackageURL returns the conda or pypi URL identifying a v6 package entry.
+func v6PackageURL(entry map[string]interface{}) string {
+	if u, ok := entry["conda"].(string); ok {
+		return u
+	}
+	if u, ok := entry["pypi"].(string); ok {
+		return u
+	}
+	return ""
+}
+
+// parseV6Packages parses pixi.lock v6 format. A non-nil only set restricts
+// the result to packages whose URL it contains.
+func parseV6Packages(content []byte, only map[string]bool) map[string]string {
 	type v6Lock struct {
 		Packages []map[string]interface{} `yaml:"packages"`
 	}
@@ -84,6 +265,9 @@ func parseV6Packages(content []byte) map[string]string {
 
 	packages := make(map[string]string)
 	for _, entry := range lf.Packages {
+		if only != nil && !only[v6PackageURL(entry)] {
+			continue
+		}
 		name, version := extractV6Package(entry)
 		if name != "" {
 			if _, exists := packages[name]; !exists {
@@ -278,7 +462,42 @@ func FormatLockDiffText(summary *LockSummary) string {
 
 	var sb strings.Builder
 	sb.WriteString("@@ pixi.lock @@\n")
+	writeLockChanges(&sb, summary)
+	sb.WriteString("\n")
+	writeLockTotals(&sb, summary)
+
+	return sb.String()
+}
+
+// FormatLockDiffTextByPlatform is like FormatLockDiffText but renders one
+// subsection per platform, so a change confined to e.g. win-64 stands out.
+// The totals line still counts each package once across platforms. Lock
+// files without platform information are formatted as by
+// FormatLockDiffText.
+func FormatLockDiffTextByPlatform(summary *LockSummary) string {
+	if summary == nil || len(summary.Platforms) == 0 {
+		return FormatLockDiffText(summary)
+	}
+
+	platforms := make([]string, 0, len(summary.Platforms))
+	for p := range summary.Platforms {
+		platforms = append(platforms, p)
+	}
+	sort.Strings(platforms)
+
+	var sb strings.Builder
+	for _, p := range platforms {
+		sb.WriteString("@@ pixi.lock [" + p + "] @@\n")
+		writeLockChanges(&sb, summary.Platforms[p])
+	}
+	sb.WriteString("\n")
+	writeLockTotals(&sb, summary)
+
+	return sb.String()
+}
 
+// writeLockChanges writes one +/- line per added, removed, or updated package.
+func writeLockChanges(sb *strings.Builder, summary *LockSummary) {
 	for _, pkg := range summary.Added {
 		sb.WriteString("+" + pkg + "\n")
 	}
@@ -289,9 +508,10 @@ func FormatLockDiffText(summary *LockSummary) string {
 		sb.WriteString("-" + u.Name + " " + u.OldVersion + "\n")
 		sb.WriteString("+" + u.Name + " " + u.NewVersion + "\n")
 	}
+}
 
-	sb.WriteString("\n")
-
+// writeLockTotals writes the "N packages added, ..." summary line.
+func writeLockTotals(sb *strings.Builder, summary *LockSummary) {
 	parts := []string{}
 	if summary.PackagesAdded > 0 {
 		parts = append(parts, pluralize(summary.PackagesAdded, "package", "packages")+" added")
@@ -303,8 +523,6 @@ func FormatLockDiffText(summary *LockSummary) string {
 		parts = append(parts, pluralize(summary.PackagesUpdated, "package", "packages")+" updated")
 	}
 	sb.WriteString(strings.Join(parts, ", ") + "\n")
-
-	return sb.String()
 }
 
 func pluralize(n int, singular, plural string) string {
@@ -115,6 +115,151 @@ packages:
 	}
 }
 
+const multiEnvLockOld = `
+version: 6
+environments:
+  default:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+  gpu:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/cudatoolkit-11.8.0-h1234_0.conda
+packages:
+- conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+- conda: https://conda.anaconda.org/conda-forge/linux-64/cudatoolkit-11.8.0-h1234_0.conda
+`
+
+const multiEnvLockNew = `
+version: 6
+environments:
+  default:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/pandas-2.1.0-py311h1234_0.conda
+  gpu:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/cudatoolkit-12.0.0-h1234_0.conda
+packages:
+- conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+- conda: https://conda.anaconda.org/conda-forge/linux-64/pandas-2.1.0-py311h1234_0.conda
+- conda: https://conda.anaconda.org/conda-forge/linux-64/cudatoolkit-12.0.0-h1234_0.conda
+`
+
+func TestCompareLockWithOptions_Environment(t *testing.T) {
+	oldLock, newLock := []byte(multiEnvLockOld), []byte(multiEnvLockNew)
+
+	gpu, err := CompareLockWithOptions(oldLock, newLock, LockOptions{Environment: "gpu"})
+	if err != nil {
+		t.Fatalf("CompareLockWithOptions(gpu) error = %v", err)
+	}
+	if gpu.PackagesAdded != 0 || gpu.PackagesRemoved != 0 || gpu.PackagesUpdated != 1 {
+		t.Errorf("gpu = +%d -%d ~%d, want +0 -0 ~1", gpu.PackagesAdded, gpu.PackagesRemoved, gpu.PackagesUpdated)
+	}
+	if len(gpu.Updated) != 1 || gpu.Updated[0].Name != "cudatoolkit" {
+		t.Errorf("gpu.Updated = %+v, want cudatoolkit", gpu.Updated)
+	}
+
+	def, err := CompareLockWithOptions(oldLock, newLock, LockOptions{Environment: "default"})
+	if err != nil {
+		t.Fatalf("CompareLockWithOptions(default) error = %v", err)
+	}
+	if def.PackagesAdded != 1 || def.PackagesUpdated != 0 {
+		t.Errorf("default = +%d ~%d, want +1 ~0", def.PackagesAdded, def.PackagesUpdated)
+	}
+
+	// Whole-file comparison sees both changes.
+	all, _ := CompareLock(oldLock, newLock)
+	if all.PackagesAdded != 1 || all.PackagesUpdated != 1 {
+		t.Errorf("whole file = +%d ~%d, want +1 ~1", all.PackagesAdded, all.PackagesUpdated)
+	}
+}
+
+func TestCompareLockWithOptions_UnknownEnvironment(t *testing.T) {
+	_, err := CompareLockWithOptions([]byte(multiEnvLockOld), []byte(multiEnvLockNew), LockOptions{Environment: "nope"})
+	if err == nil {
+		t.Fatal("expected error for unknown environment")
+	}
+}
+
+const multiPlatformLockOld = `
+version: 6
+environments:
+  default:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+      win-64:
+      - conda: https://conda.anaconda.org/conda-forge/win-64/numpy-1.24.0-py311h5678_0.conda
+      - conda: https://conda.anaconda.org/conda-forge/win-64/vs2015_runtime-14.38.0-h1234_0.conda
+packages:
+- conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+- conda: https://conda.anaconda.org/conda-forge/win-64/numpy-1.24.0-py311h5678_0.conda
+- conda: https://conda.anaconda.org/conda-forge/win-64/vs2015_runtime-14.38.0-h1234_0.conda
+`
+
+const multiPlatformLockNew = `
+version: 6
+environments:
+  default:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+      win-64:
+      - conda: https://conda.anaconda.org/conda-forge/win-64/numpy-1.24.0-py311h5678_0.conda
+      - conda: https://conda.anaconda.org/conda-forge/win-64/vs2015_runtime-14.40.0-h1234_0.conda
+packages:
+- conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+- conda: https://conda.anaconda.org/conda-forge/win-64/numpy-1.24.0-py311h5678_0.conda
+- conda: https://conda.anaconda.org/conda-forge/win-64/vs2015_runtime-14.40.0-h1234_0.conda
+`
+
+func TestCompareLock_PlatformAttribution(t *testing.T) {
+	flat, err := CompareLock([]byte(multiPlatformLockOld), []byte(multiPlatformLockNew))
+	if err != nil {
+		t.Fatalf("CompareLock() error = %v", err)
+	}
+	if flat.Platforms != nil {
+		t.Errorf("Platforms = %v without GroupByPlatform, want nil", flat.Platforms)
+	}
+
+	summary, err := CompareLockWithOptions([]byte(multiPlatformLockOld), []byte(multiPlatformLockNew), LockOptions{GroupByPlatform: true})
+	if err != nil {
+		t.Fatalf("CompareLockWithOptions() error = %v", err)
+	}
+
+	if len(summary.Platforms) != 1 {
+		t.Fatalf("Platforms = %v, want only win-64", summary.Platforms)
+	}
+	win := summary.Platforms["win-64"]
+	if win == nil || win.PackagesUpdated != 1 || win.Updated[0].Name != "vs2015_runtime" {
+		t.Errorf("win-64 = %+v, want vs2015_runtime updated", win)
+	}
+
+	result := FormatLockDiffTextByPlatform(summary)
+	if !strings.Contains(result, "@@ pixi.lock [win-64] @@\n-vs2015_runtime 14.38.0\n+vs2015_runtime 14.40.0\n") {
+		t.Errorf("expected win-64 subsection, got:\n%s", result)
+	}
+	if strings.Contains(result, "linux-64") {
+		t.Errorf("unchanged platform should not be shown, got:\n%s", result)
+	}
+	if !strings.Contains(result, "1 package updated") {
+		t.Errorf("expected totals line, got:\n%s", result)
+	}
+}
+
+func TestFormatLockDiffTextByPlatform_NoPlatforms(t *testing.T) {
+	summary := &LockSummary{PackagesAdded: 1, Added: []string{"scipy 1.11.0"}}
+	if got, want := FormatLockDiffTextByPlatform(summary), FormatLockDiffText(summary); got != want {
+		t.Errorf("got %q, want flattened output %q", got, want)
+	}
+}
+
 func TestCompareLock_InvalidYAML(t *testing.T) {
 	summary, err := CompareLock([]byte("not: valid: yaml: {{{"), []byte("different: content"))
 	if err != nil {
@@ -0,0 +1,234 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// StaleDependency is a pixi.toml dependency that the pixi.lock file does not
+// contain, meaning the lock was not re-solved after the manifest changed.
+type StaleDependency struct {
+	Environment string `json:"environment"`
+	Platform    string `json:"platform,omitempty"` // Empty when missing on every platform
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // "conda", "pypi", or "environment"
+}
+
+// String renders the dependency as one line of a stale-lock report.
+func (d StaleDependency) String() string {
+	if d.Kind == "environment" {
+		return fmt.Sprintf("environment %q is not in pixi.lock", d.Name)
+	}
+	where := d.Environment
+	if d.Platform != "" {
+		where += " [" + d.Platform + "]"
+	}
+	return fmt.Sprintf("%s: %s (%s) is not locked", where, d.Name, d.Kind)
+}
+
+// CheckLockStale reports the direct dependencies declared in a pixi.toml
+// that are missing from a v6 pixi.lock. Top-level, feature, and
+// target-specific dependency tables are checked against the lock
+// environments that use them. Version constraints are not compared, so a
+// lock that still satisfies the manifest by name is considered current.
+func CheckLockStale(manifest, lock []byte) ([]StaleDependency, error) {
+	var m map[string]interface{}
+	if err := toml.Unmarshal(manifest, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse pixi.toml: %w", err)
+	}
+	if len(strings.TrimSpace(string(lock))) == 0 {
+		return nil, fmt.Errorf("no pixi.lock to check")
+	}
+	envs := parseV6Environments(lock)
+	if len(envs) == 0 {
+		return nil, fmt.Errorf("pixi.lock has no environments (only v6 lock files are supported)")
+	}
+	names, err := v6PackageNames(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []StaleDependency
+	for _, env := range sortedEnvNames(m) {
+		lockEnv, ok := envs[env]
+		if !ok {
+			stale = append(stale, StaleDependency{Environment: env, Name: env, Kind: "environment"})
+			continue
+		}
+
+		// Locked package names per platform, keyed by "kind:name".
+		locked := make(map[string]map[string]bool)
+		var platforms []string
+		for platform, refs := range lockEnv.Packages {
+			platforms = append(platforms, platform)
+			set := make(map[string]bool)
+			for _, ref := range refs {
+				if key, ok := names[v6PackageURL(ref)]; ok {
+					set[key] = true
+				}
+			}
+			locked[platform] = set
+		}
+		sort.Strings(platforms)
+
+		for _, dep := range manifestDependencies(m, env) {
+			key := dep.kind + ":" + normalizePackageName(dep.name, dep.kind)
+			checkPlatforms := platforms
+			if dep.platform != "" {
+				checkPlatforms = []string{dep.platform}
+			}
+
+			var missing []string
+			for _, p := range checkPlatforms {
+				if !locked[p][key] {
+					missing = append(missing, p)
+				}
+			}
+			switch {
+			case len(checkPlatforms) > 0 && len(missing) == 0:
+			case len(missing) == len(platforms) && dep.platform == "":
+				stale = append(stale, StaleDependency{Environment: env, Name: dep.name, Kind: dep.kind})
+			default:
+				for _, p := range missing {
+					stale = append(stale, StaleDependency{Environment: env, Platform: p, Name: dep.name, Kind: dep.kind})
+				}
+			}
+		}
+	}
+	return stale, nil
+}
+
+type manifestDependency struct {
+	name     string
+	kind     string
+	platform string
+}
+
+// manifestDependencies lists the direct dependencies an environment pulls in
+// from its features, including target-specific ones.
+func manifestDependencies(m map[string]interface{}, env string) []manifestDependency {
+	var tables []map[string]interface{}
+	if includesDefaultFeature(m, env) {
+		tables = append(tables, m)
+	}
+	features, _ := m["feature"].(map[string]interface{})
+	for _, f := range environmentFeatures(m, env) {
+		if t, ok := features[f].(map[string]interface{}); ok {
+			tables = append(tables, t)
+		}
+	}
+
+	var deps []manifestDependency
+	for _, t := range tables {
+		deps = append(deps, tableDependencies(t, "")...)
+		targets, _ := t["target"].(map[string]interface{})
+		for _, platform := range sortedKeys(targets) {
+			if tt, ok := targets[platform].(map[string]interface{}); ok {
+				deps = append(deps, tableDependencies(tt, platform)...)
+			}
+		}
+	}
+	return deps
+}
+
+func tableDependencies(t map[string]interface{}, platform string) []manifestDependency {
+	var deps []manifestDependency
+	for section, kind := range map[string]string{"dependencies": "conda", "pypi-dependencies": "pypi"} {
+		table, _ := t[section].(map[string]interface{})
+		for _, name := range sortedKeys(table) {
+			deps = append(deps, manifestDependency{name: name, kind: kind, platform: platform})
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].kind != deps[j].kind {
+			return deps[i].kind < deps[j].kind
+		}
+		return deps[i].name < deps[j].name
+	})
+	return deps
+}
+
+// environmentFeatures returns the features listed for env in the
+// manifest's [environments] table, in either the list or table form.
+func environmentFeatures(m map[string]interface{}, env string) []string {
+	envs, _ := m["environments"].(map[string]interface{})
+	var raw []interface{}
+	switch v := envs[env].(type) {
+	case []interface{}:
+		raw = v
+	case map[string]interface{}:
+		raw, _ = v["features"].([]interface{})
+	}
+	var features []string
+	for _, f := range raw {
+		if s, ok := f.(string); ok {
+			features = append(features, s)
+		}
+	}
+	return features
+}
+
+func includesDefaultFeature(m map[string]interface{}, env string) bool {
+	envs, _ := m["environments"].(map[string]interface{})
+	if t, ok := envs[env].(map[string]interface{}); ok {
+		if noDefault, _ := t["no-default-feature"].(bool); noDefault {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedEnvNames returns the environments declared in the manifest, plus
+// "default", in sorted order. Environments only present in the lock are
+// ignored since they have nothing to check against.
+func sortedEnvNames(m map[string]interface{}) []string {
+	set := map[string]bool{"default": true}
+	if envs, ok := m["environments"].(map[string]interface{}); ok {
+		for name := range envs {
+			set[name] = true
+		}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// v6PackageNames maps each package URL in a v6 lock to "kind:name".
+func v6PackageNames(content []byte) (map[string]string, error) {
+	var lf struct {
+		Packages []map[string]interface{} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(content, &lf); err != nil {
+		return nil, fmt.Errorf("failed to parse pixi.lock: %w", err)
+	}
+	names := make(map[string]string, len(lf.Packages))
+	for _, entry := range lf.Packages {
+		name, _ := extractV6Package(entry)
+		if name == "" {
+			continue
+		}
+		kind := "conda"
+		if _, ok := entry["pypi"]; ok {
+			kind = "pypi"
+		}
+		names[v6PackageURL(entry)] = kind + ":" + normalizePackageName(name, kind)
+	}
+	return names, nil
+}
+
+// normalizePackageName lowercases names and, for PyPI, folds the
+// separators that PEP 503 treats as equivalent.
+func normalizePackageName(name, kind string) string {
+	name = strings.ToLower(name)
+	if kind == "pypi" {
+		name = strings.NewReplacer("_", "-", ".", "-").Replace(name)
+	}
+	return name
+}
@@ -0,0 +1,105 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+const staleCheckLock = `
+version: 6
+environments:
+  default:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+      - pypi: https://files.pythonhosted.org/packages/requests-2.31.0-py3-none-any.whl
+      win-64:
+      - conda: https://conda.anaconda.org/conda-forge/win-64/numpy-1.24.0-py311h5678_0.conda
+      - pypi: https://files.pythonhosted.org/packages/requests-2.31.0-py3-none-any.whl
+  test:
+    packages:
+      linux-64:
+      - conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+      - pypi: https://files.pythonhosted.org/packages/requests-2.31.0-py3-none-any.whl
+      win-64:
+      - conda: https://conda.anaconda.org/conda-forge/win-64/numpy-1.24.0-py311h5678_0.conda
+      - pypi: https://files.pythonhosted.org/packages/requests-2.31.0-py3-none-any.whl
+packages:
+- conda: https://conda.anaconda.org/conda-forge/linux-64/numpy-1.24.0-py311h1234_0.conda
+- conda: https://conda.anaconda.org/conda-forge/win-64/numpy-1.24.0-py311h5678_0.conda
+- pypi: https://files.pythonhosted.org/packages/requests-2.31.0-py3-none-any.whl
+  name: Requests
+  version: 2.31.0
+`
+
+func TestCheckLockStale_Current(t *testing.T) {
+	manifest := []byte(`
+[workspace]
+name = "demo"
+platforms = ["linux-64", "win-64"]
+
+[dependencies]
+numpy = ">=1.24"
+
+[pypi-dependencies]
+requests = "*"
+
+[environments]
+test = ["test"]
+`)
+	stale, err := CheckLockStale(manifest, []byte(staleCheckLock))
+	if err != nil {
+		t.Fatalf("CheckLockStale() error = %v", err)
+	}
+	if len(stale) != 0 {
+		t.Errorf("expected lock to be current, got %v", stale)
+	}
+}
+
+func TestCheckLockStale_MissingDependencies(t *testing.T) {
+	manifest := []byte(`
+[workspace]
+name = "demo"
+platforms = ["linux-64", "win-64"]
+
+[dependencies]
+numpy = ">=1.24"
+scipy = "*"
+
+[target.win-64.dependencies]
+pywin32 = "*"
+
+[feature.test.dependencies]
+pytest = "*"
+
+[environments]
+test = ["test"]
+gpu = { features = ["gpu"], no-default-feature = true }
+`)
+	stale, err := CheckLockStale(manifest, []byte(staleCheckLock))
+	if err != nil {
+		t.Fatalf("CheckLockStale() error = %v", err)
+	}
+
+	var got []string
+	for _, d := range stale {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"default: scipy (conda) is not locked",
+		"default [win-64]: pywin32 (conda) is not locked",
+		`environment "gpu" is not in pixi.lock`,
+		"test: scipy (conda) is not locked",
+		"test [win-64]: pywin32 (conda) is not locked",
+		"test: pytest (conda) is not locked",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckLockStale_NoLock(t *testing.T) {
+	if _, err := CheckLockStale([]byte("[workspace]\nname = \"x\"\n"), nil); err == nil {
+		t.Error("expected error when there is no lock file")
+	}
+}
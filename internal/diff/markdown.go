@@ -0,0 +1,142 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownCollapseThreshold is the number of lines above which a Markdown
+// section is wrapped in a collapsible <details> block.
+const markdownCollapseThreshold = 20
+
+// FormatMarkdown renders a TOML diff and lock summary as Markdown suitable
+// for a pull request comment: the pixi.toml changes as a fenced diff block
+// and the lock changes as a package table. Long sections are collapsible.
+// lock is nil when the lock files are identical.
+func FormatMarkdown(d *TomlDiff, lock *LockSummary, sourceLabel, targetLabel string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### nebi diff: `%s` → `%s`\n\n", markdownCode(sourceLabel), markdownCode(targetLabel)))
+
+	if !d.HasChanges() && lock == nil {
+		sb.WriteString("_No differences._\n")
+		return sb.String()
+	}
+
+	if d.HasChanges() {
+		body := FormatUnifiedDiff(d, sourceLabel, targetLabel)
+		fence := markdownFence(body)
+		block := fence + "diff\n" + body + fence + "\n"
+		writeMarkdownSection(&sb, "pixi.toml", pluralize(len(d.Changes), "change", "changes"), block, len(d.Changes))
+	}
+
+	if lock != nil {
+		writeMarkdownSection(&sb, "pixi.lock", lockMarkdownSummary(lock), lockMarkdownTable(lock), lockRowCount(lock))
+	}
+
+	return sb.String()
+}
+
+// writeMarkdownSection writes a heading and body, collapsing the body when
+// it has more than markdownCollapseThreshold rows.
+func writeMarkdownSection(sb *strings.Builder, title, summary, body string, rows int) {
+	sb.WriteString(fmt.Sprintf("#### %s\n\n", title))
+	if body == "" {
+		sb.WriteString(summary + "\n\n")
+		return
+	}
+	if rows > markdownCollapseThreshold {
+		sb.WriteString(fmt.Sprintf("<details>\n<summary>%s</summary>\n\n%s\n</details>\n\n", markdownEscape(summary), body))
+		return
+	}
+	sb.WriteString(summary + "\n\n" + body + "\n")
+}
+
+func lockMarkdownSummary(lock *LockSummary) string {
+	if lock.PackagesUpdated == -1 {
+		return "Changed (unable to parse package details)"
+	}
+	return fmt.Sprintf("%d added, %d removed, %d updated", lock.PackagesAdded, lock.PackagesRemoved, lock.PackagesUpdated)
+}
+
+func lockRowCount(lock *LockSummary) int {
+	return len(lock.Added) + len(lock.Removed) + len(lock.Updated)
+}
+
+// lockMarkdownTable renders the package changes as a table, or "" when
+// there are no package-level details.
+func lockMarkdownTable(lock *LockSummary) string {
+	if lock.PackagesUpdated == -1 || lockRowCount(lock) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Package | Change | Old | New |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	row := func(name, change, oldVer, newVer string) {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			markdownEscape(name), change, markdownEscape(oldVer), markdownEscape(newVer)))
+	}
+	for _, pkg := range lock.Added {
+		name, version := splitPackageDisplay(pkg)
+		row(name, "added", "", version)
+	}
+	for _, pkg := range lock.Removed {
+		name, version := splitPackageDisplay(pkg)
+		row(name, "removed", version, "")
+	}
+	for _, u := range lock.Updated {
+		row(u.Name, "updated", u.OldVersion, u.NewVersion)
+	}
+	return sb.String()
+}
+
+// splitPackageDisplay splits a "name version" entry from LockSummary.Added
+// or Removed.
+func splitPackageDisplay(s string) (name, version string) {
+	if i := strings.LastIndex(s, " "); i > 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// markdownEscape backslash-escapes characters that Markdown or GitHub's
+// HTML sanitizer would otherwise interpret in inline text and table cells.
+func markdownEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '`', '*', '_', '{', '}', '[', ']', '(', ')', '#', '+', '!', '|', '<', '>', '~':
+			sb.WriteRune('\\')
+		case '\n':
+			sb.WriteString(" ")
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// markdownCode makes s safe to place inside a single-backtick code span.
+func markdownCode(s string) string {
+	return strings.ReplaceAll(s, "`", "'")
+}
+
+// markdownFence returns a code fence longer than any backtick run in body,
+// so content can never close the block early.
+func markdownFence(body string) string {
+	longest, run := 0, 0
+	for _, r := range body {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
@@ -0,0 +1,69 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatMarkdown(t *testing.T) {
+	d := &TomlDiff{Changes: []Change{
+		{Section: "dependencies", Key: "numpy", Type: ChangeModified, OldValue: ">=2.0", NewValue: ">=2.4"},
+	}}
+	lock := &LockSummary{
+		PackagesAdded:   1,
+		PackagesUpdated: 1,
+		Added:           []string{"my_pkg 1.0|beta"},
+		Updated:         []PackageUpdate{{Name: "numpy", OldVersion: "2.0.0", NewVersion: "2.4.0"}},
+	}
+
+	result := FormatMarkdown(d, lock, "a", "b")
+
+	for _, want := range []string{
+		"### nebi diff: `a` → `b`",
+		"#### pixi.toml",
+		"```diff\n--- a\n+++ b\n",
+		"+numpy = \">=2.4\"",
+		"#### pixi.lock",
+		"| Package | Change | Old | New |",
+		"| numpy | updated | 2.0.0 | 2.4.0 |",
+		// Special characters in package data are escaped.
+		`| my\_pkg | added |  | 1.0\|beta |`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "<details>") {
+		t.Error("short diffs should not be collapsed")
+	}
+}
+
+func TestFormatMarkdown_CollapsesLongSections(t *testing.T) {
+	lock := &LockSummary{}
+	for i := 0; i < markdownCollapseThreshold+1; i++ {
+		lock.Added = append(lock.Added, fmt.Sprintf("pkg%d 1.0", i))
+		lock.PackagesAdded++
+	}
+
+	result := FormatMarkdown(&TomlDiff{}, lock, "a", "b")
+	if !strings.Contains(result, "<details>\n<summary>21 added, 0 removed, 0 updated</summary>") {
+		t.Errorf("expected collapsible lock section, got:\n%s", result)
+	}
+}
+
+func TestFormatMarkdown_NoChanges(t *testing.T) {
+	result := FormatMarkdown(&TomlDiff{}, nil, "a", "b")
+	if !strings.Contains(result, "_No differences._") {
+		t.Errorf("expected no-differences note, got:\n%s", result)
+	}
+}
+
+func TestMarkdownFence(t *testing.T) {
+	if got := markdownFence("plain"); got != "```" {
+		t.Errorf("markdownFence(plain) = %q", got)
+	}
+	if got := markdownFence("has ``` inside"); got != "````" {
+		t.Errorf("markdownFence with backticks = %q, want 4 backticks", got)
+	}
+}
@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bump is the most significant semver component that differs between two
+// versions of a package.
+type Bump int
+
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b Bump) String() string {
+	switch b {
+	case BumpMajor:
+		return "major"
+	case BumpMinor:
+		return "minor"
+	case BumpPatch:
+		return "patch"
+	default:
+		return "none"
+	}
+}
+
+// MarshalText renders the bump by name in JSON output.
+func (b Bump) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// ParseSemver reads the leading major[.minor[.patch]] numbers of a package
+// version, as used by most conda and PyPI packages. Missing components are
+// zero and anything after them (pre-release, post-release or build
+// suffixes) is ignored, so "1.26.4", "2.1" and "3.12.0rc1" all parse.
+// Versions that do not start with a number, or that carry an epoch
+// ("1!2.0"), are rejected.
+func ParseSemver(version string) ([3]int, error) {
+	var parts [3]int
+	s := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	for i := 0; i < len(parts); i++ {
+		n := 0
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		if n == 0 {
+			if i == 0 {
+				return parts, fmt.Errorf("version %q does not start with a number", version)
+			}
+			break
+		}
+		v, err := strconv.Atoi(s[:n])
+		if err != nil {
+			return parts, fmt.Errorf("version %q: %w", version, err)
+		}
+		parts[i] = v
+		s = s[n:]
+		if !strings.HasPrefix(s, ".") {
+			break
+		}
+		s = s[1:]
+	}
+	if strings.HasPrefix(s, "!") {
+		return parts, fmt.Errorf("version %q has an epoch", version)
+	}
+	return parts, nil
+}
+
+// ClassifyBump reports which component changed between oldVersion and
+// newVersion. Downgrades are classified the same way as upgrades.
+func ClassifyBump(oldVersion, newVersion string) (Bump, error) {
+	o, err := ParseSemver(oldVersion)
+	if err != nil {
+		return BumpNone, err
+	}
+	n, err := ParseSemver(newVersion)
+	if err != nil {
+		return BumpNone, err
+	}
+	switch {
+	case o[0] != n[0]:
+		return BumpMajor, nil
+	case o[1] != n[1]:
+		return BumpMinor, nil
+	case o[2] != n[2]:
+		return BumpPatch, nil
+	default:
+		return BumpNone, nil
+	}
+}
+
+// BumpedPackage is an updated package together with its classified bump.
+type BumpedPackage struct {
+	PackageUpdate
+	Bump Bump `json:"bump"`
+}
+
+// CheckBumps returns the updates whose bump is at least min, and
+// separately the updates whose versions could not be parsed.
+func CheckBumps(updates []PackageUpdate, min Bump) (offending []BumpedPackage, unparseable []PackageUpdate) {
+	for _, u := range updates {
+		b, err := ClassifyBump(u.OldVersion, u.NewVersion)
+		if err != nil {
+			unparseable = append(unparseable, u)
+			continue
+		}
+		if b >= min && b != BumpNone {
+			offending = append(offending, BumpedPackage{PackageUpdate: u, Bump: b})
+		}
+	}
+	return offending, unparseable
+}
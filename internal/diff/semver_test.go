@@ -0,0 +1,78 @@
+package diff
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    [3]int
+		wantErr bool
+	}{
+		{"1.26.4", [3]int{1, 26, 4}, false},
+		{"2.1", [3]int{2, 1, 0}, false},
+		{"3", [3]int{3, 0, 0}, false},
+		{"3.12.0rc1", [3]int{3, 12, 0}, false},
+		{"2024.1.post1", [3]int{2024, 1, 0}, false},
+		{"v1.2.3", [3]int{1, 2, 3}, false},
+		{"1.2.3.4", [3]int{1, 2, 3}, false},
+		{"1!2.0", [3]int{}, true},
+		{"abc", [3]int{}, true},
+		{"", [3]int{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSemver(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSemver(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseSemver(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyBump(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     Bump
+	}{
+		{"1.26.4", "2.0.0", BumpMajor},
+		{"2.0.0", "1.26.4", BumpMajor},
+		{"1.26.4", "1.27.0", BumpMinor},
+		{"1.26.4", "1.26.5", BumpPatch},
+		{"1.26", "1.26.0", BumpNone},
+		{"3.12.0rc1", "3.12.0", BumpNone},
+	}
+	for _, tt := range tests {
+		got, err := ClassifyBump(tt.old, tt.new)
+		if err != nil {
+			t.Errorf("ClassifyBump(%q, %q) error: %v", tt.old, tt.new, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ClassifyBump(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestCheckBumps(t *testing.T) {
+	updates := []PackageUpdate{
+		{Name: "numpy", OldVersion: "1.26.4", NewVersion: "2.0.0"},
+		{Name: "pandas", OldVersion: "2.1.0", NewVersion: "2.2.0"},
+		{Name: "python", OldVersion: "3.11.8", NewVersion: "3.11.9"},
+		{Name: "weird", OldVersion: "1!1.0", NewVersion: "1!2.0"},
+	}
+
+	offending, unparseable := CheckBumps(updates, BumpMajor)
+	if len(offending) != 1 || offending[0].Name != "numpy" || offending[0].Bump != BumpMajor {
+		t.Errorf("major gate offending = %+v, want numpy only", offending)
+	}
+	if len(unparseable) != 1 || unparseable[0].Name != "weird" {
+		t.Errorf("unparseable = %+v, want weird", unparseable)
+	}
+
+	offending, _ = CheckBumps(updates, BumpMinor)
+	if len(offending) != 2 || offending[1].Name != "pandas" {
+		t.Errorf("minor gate offending = %+v, want numpy and pandas", offending)
+	}
+}
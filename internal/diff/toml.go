@@ -56,6 +56,25 @@ func (d *TomlDiff) Modified() []Change {
 	return d.filterByType(ChangeModified)
 }
 
+// OnlySections returns a copy of the diff restricted to changes in the named
+// tables, given as dotted paths such as "dependencies" or
+// "feature.gpu.dependencies" (surrounding brackets are ignored). A path
+// also matches the tables nested below it, so "feature.gpu" covers all of
+// that feature's tables.
+func (d *TomlDiff) OnlySections(paths []string) *TomlDiff {
+	result := &TomlDiff{}
+	for _, c := range d.Changes {
+		for _, p := range paths {
+			p = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(p), "["), "]")
+			if c.Section == p || strings.HasPrefix(c.Section, p+".") {
+				result.Changes = append(result.Changes, c)
+				break
+			}
+		}
+	}
+	return result
+}
+
 func (d *TomlDiff) filterByType(t ChangeType) []Change {
 	var result []Change
 	for _, c := range d.Changes {
@@ -112,13 +131,13 @@ func compareMaps(oldMap, newMap map[string]interface{}, prefix string, diff *Tom
 
 		if !oldExists {
 			// Key was added
-			addChangesForValue(section, fullKey, newVal, ChangeAdded, diff)
+			addChangesForValue(valueSection(prefix, key, newVal), fullKey, newVal, ChangeAdded, diff)
 			continue
 		}
 
 		if !newExists {
 			// Key was removed
-			addChangesForValue(section, fullKey, oldVal, ChangeRemoved, diff)
+			addChangesForValue(valueSection(prefix, key, oldVal), fullKey, oldVal, ChangeRemoved, diff)
 			continue
 		}
 
@@ -150,6 +169,20 @@ func compareMaps(oldMap, newMap map[string]interface{}, prefix string, diff *Tom
 	}
 }
 
+// valueSection returns the section to report an added or removed value
+// under: the enclosing table for scalars, or the value's own table path for
+// tables, so a table added inside an existing one (e.g. feature.test in a
+// manifest that already has [feature.gpu]) keeps its full dotted path.
+func valueSection(prefix, key string, val interface{}) string {
+	if prefix == "" {
+		return key
+	}
+	if _, isMap := val.(map[string]interface{}); isMap {
+		return prefix + "." + key
+	}
+	return prefix
+}
+
 // addChangesForValue adds changes for a value, recursing into nested maps to
 // produce proper dotted section paths (e.g., "feature.test.dependencies").
 func addChangesForValue(section, key string, val interface{}, changeType ChangeType, diff *TomlDiff) {
@@ -274,3 +307,78 @@ func FormatUnifiedDiff(diff *TomlDiff, sourceLabel, targetLabel string) string {
 
 	return sb.String()
 }
+
+// FormatSectionContext formats a TomlDiff by printing every changed table in
+// full, before and after, under its section header. Unlike
+// FormatUnifiedDiff, unchanged keys of a changed table are included so a
+// whole-table rewrite can be reviewed in one place. The tables are read
+// from the parsed TOML rather than reconstructed from the changes.
+func FormatSectionContext(diff *TomlDiff, oldContent, newContent []byte, sourceLabel, targetLabel string) (string, error) {
+	if !diff.HasChanges() {
+		return "", nil
+	}
+
+	var oldMap, newMap map[string]interface{}
+	if err := toml.Unmarshal(oldContent, &oldMap); err != nil {
+		return "", fmt.Errorf("failed to parse old TOML: %w", err)
+	}
+	if err := toml.Unmarshal(newContent, &newMap); err != nil {
+		return "", fmt.Errorf("failed to parse new TOML: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n", sourceLabel))
+	sb.WriteString(fmt.Sprintf("+++ %s\n", targetLabel))
+
+	seen := make(map[string]bool)
+	for _, c := range diff.Changes {
+		if seen[c.Section] {
+			continue
+		}
+		seen[c.Section] = true
+
+		sb.WriteString(fmt.Sprintf("@@ [%s] @@\n", c.Section))
+		writeSectionTable(&sb, "-", lookupTable(oldMap, c.Section))
+		writeSectionTable(&sb, "+", lookupTable(newMap, c.Section))
+	}
+
+	return sb.String(), nil
+}
+
+// lookupTable returns the table at a dotted section path, or nil if the
+// path does not name a table. A top-level key that is not a table is
+// returned as a single-entry table so it can still be shown in context.
+func lookupTable(root map[string]interface{}, section string) map[string]interface{} {
+	if val, ok := root[section]; ok {
+		if table, isMap := val.(map[string]interface{}); isMap {
+			return table
+		}
+		return map[string]interface{}{section: val}
+	}
+
+	current := root
+	for _, part := range strings.Split(section, ".") {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// writeSectionTable writes the scalar entries of a table, one per line,
+// prefixed with marker. Nested tables are reported as their own sections
+// and are skipped here.
+func writeSectionTable(sb *strings.Builder, marker string, table map[string]interface{}) {
+	if table == nil {
+		sb.WriteString(fmt.Sprintf("%s (absent)\n", marker))
+		return
+	}
+	for _, k := range sortedKeys(table) {
+		if _, isMap := table[k].(map[string]interface{}); isMap {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s%s = %q\n", marker, k, formatValue(table[k])))
+	}
+}
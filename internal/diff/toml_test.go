@@ -133,6 +133,55 @@ pytest = "*"
 	}
 }
 
+func TestTomlDiff_OnlySections(t *testing.T) {
+	oldContent := []byte(`[workspace]
+name = "test"
+
+[dependencies]
+python = ">=3.11"
+
+[feature.gpu.dependencies]
+cuda = "12"
+`)
+	newContent := []byte(`[workspace]
+name = "renamed"
+
+[dependencies]
+python = ">=3.12"
+
+[feature.gpu.dependencies]
+cuda = "12.4"
+
+[feature.test.dependencies]
+pytest = "*"
+`)
+
+	diff, err := CompareToml(oldContent, newContent)
+	if err != nil {
+		t.Fatalf("CompareToml() error = %v", err)
+	}
+
+	tests := []struct {
+		paths []string
+		want  []string
+	}{
+		{[]string{"dependencies"}, []string{"python"}},
+		{[]string{"[feature.gpu.dependencies]"}, []string{"cuda"}},
+		{[]string{"feature"}, []string{"cuda", "pytest"}},
+		{[]string{"workspace", "feature.test"}, []string{"pytest", "name"}},
+		{[]string{"pypi-dependencies"}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range diff.OnlySections(tt.paths).Changes {
+			got = append(got, c.Key)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("OnlySections(%v) keys = %v, want %v", tt.paths, got, tt.want)
+		}
+	}
+}
+
 func TestCompareToml_EmptyFiles(t *testing.T) {
 	diff, err := CompareToml([]byte(""), []byte(""))
 	if err != nil {
@@ -209,3 +258,51 @@ func TestFormatValue_NoMapSyntax(t *testing.T) {
 		t.Errorf("formatValue(map) should not produce Go map syntax, got %q", result)
 	}
 }
+
+func TestFormatSectionContext(t *testing.T) {
+	oldToml := []byte(`[workspace]
+name = "test"
+
+[dependencies]
+numpy = ">=2.0"
+python = "3.11"
+`)
+	newToml := []byte(`[workspace]
+name = "test"
+
+[dependencies]
+numpy = ">=2.4"
+python = "3.11"
+
+[feature.test.dependencies]
+pytest = "*"
+`)
+
+	diff, err := CompareToml(oldToml, newToml)
+	if err != nil {
+		t.Fatalf("CompareToml: %v", err)
+	}
+
+	result, err := FormatSectionContext(diff, oldToml, newToml, "a", "b")
+	if err != nil {
+		t.Fatalf("FormatSectionContext: %v", err)
+	}
+
+	want := `--- a
++++ b
+@@ [dependencies] @@
+-numpy = ">=2.0"
+-python = "3.11"
++numpy = ">=2.4"
++python = "3.11"
+@@ [feature.test.dependencies] @@
+- (absent)
++pytest = "*"
+`
+	if result != want {
+		t.Errorf("got:\n%s\nwant:\n%s", result, want)
+	}
+	if strings.Contains(result, "[workspace]") {
+		t.Error("unchanged tables should not be shown")
+	}
+}
@@ -15,6 +15,7 @@ import (
 // create to keep staging from leaking.
 type CreateWorkspaceOptions struct {
 	PixiToml string
+	PixiLock string // written alongside PixiToml, so `pixi lock` keeps the given solve
 	SeedDir  string
 }
 
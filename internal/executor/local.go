@@ -123,6 +123,12 @@ func (e *LocalExecutor) CreateWorkspace(ctx context.Context, ws *models.Workspac
 		if err := os.WriteFile(pixiTomlPath, []byte(opts.PixiToml), 0o644); err != nil {
 			return fmt.Errorf("failed to write pixi.toml: %w", err)
 		}
+		if opts.PixiLock != "" {
+			fmt.Fprintf(logWriter, "Writing provided pixi.lock content\n")
+			if err := os.WriteFile(filepath.Join(envPath, "pixi.lock"), []byte(opts.PixiLock), 0o644); err != nil {
+				return fmt.Errorf("failed to write pixi.lock: %w", err)
+			}
+		}
 		if err := runPixiLock(ctx, pm, envPath, logWriter); err != nil {
 			return err
 		}
@@ -42,9 +42,15 @@ type Workspace struct {
 	Source         string          `gorm:"default:'managed'" json:"source"` // "managed", "local"
 	Path           string          `json:"path,omitempty"`                  // filesystem path (local-mode)
 	SizeBytes      int64           `gorm:"default:0" json:"size_bytes,omitempty"`
-	CreatedAt      time.Time       `json:"created_at"`
-	UpdatedAt      time.Time       `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"-"`
+	// DefaultPlatforms are the platforms a solve targets when the request
+	// names none. Empty means the platforms declared in pixi.toml.
+	DefaultPlatforms []string `gorm:"serializer:json" json:"default_platforms,omitempty"`
+	// AutoLatestTag, when set, is moved to every pushed version alongside
+	// "latest", without needing --force.
+	AutoLatestTag string         `json:"auto_latest_tag,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName ensures GORM uses the "workspaces" table
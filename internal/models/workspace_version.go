@@ -1,6 +1,12 @@
 package models
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +27,11 @@ type WorkspaceVersion struct {
 	ManifestContent string `gorm:"type:text;not null" json:"manifest_content"`  // pixi.toml content
 	PackageMetadata string `gorm:"type:text;not null" json:"package_metadata"`  // JSON of package list
 
+	// ContentEncoding records how LockFileContent and ManifestContent are
+	// stored: "" for plain text or ContentEncodingGzip. Hooks decode content
+	// on read, so in memory both fields are always plain text.
+	ContentEncoding string `gorm:"type:text;not null;default:''" json:"-"`
+
 	// Content hash for deduplication
 	ContentHash string `gorm:"type:text;index" json:"content_hash"`
 
@@ -34,6 +45,110 @@ type WorkspaceVersion struct {
 	// Timestamps
 	CreatedAt time.Time      `json:"created_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Plain content held while the compressed form is being written.
+	plainLock, plainManifest *string
+}
+
+// ContentEncodingGzip marks version content stored as base64-encoded gzip.
+const ContentEncodingGzip = "gzip+base64"
+
+var compressVersionContent atomic.Bool
+
+// SetVersionContentCompression controls whether versions saved from now on
+// store their lock and manifest gzip-compressed (storage.compress_versions).
+// Reads handle both forms regardless of this setting.
+func SetVersionContentCompression(enabled bool) {
+	compressVersionContent.Store(enabled)
+}
+
+// EncodeVersionContent compresses plain version content for storage.
+func EncodeVersionContent(plain string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(plain)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeVersionContent returns the plain text of stored version content.
+func DecodeVersionContent(stored, encoding string) (string, error) {
+	switch encoding {
+	case "":
+		return stored, nil
+	case ContentEncodingGzip:
+		if stored == "" {
+			return "", nil
+		}
+		raw, err := base64.StdEncoding.DecodeString(stored)
+		if err != nil {
+			return "", err
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return "", err
+		}
+		defer zr.Close()
+		plain, err := io.ReadAll(zr)
+		if err != nil {
+			return "", err
+		}
+		return string(plain), nil
+	default:
+		return "", fmt.Errorf("unknown version content encoding %q", encoding)
+	}
+}
+
+// BeforeSave compresses the lock and manifest when compression is enabled.
+func (wv *WorkspaceVersion) BeforeSave(tx *gorm.DB) error {
+	if !compressVersionContent.Load() || wv.ContentEncoding != "" {
+		return nil
+	}
+	lock, err := EncodeVersionContent(wv.LockFileContent)
+	if err != nil {
+		return fmt.Errorf("compressing lock file: %w", err)
+	}
+	manifest, err := EncodeVersionContent(wv.ManifestContent)
+	if err != nil {
+		return fmt.Errorf("compressing manifest: %w", err)
+	}
+	plainLock, plainManifest := wv.LockFileContent, wv.ManifestContent
+	wv.plainLock, wv.plainManifest = &plainLock, &plainManifest
+	wv.LockFileContent, wv.ManifestContent = lock, manifest
+	wv.ContentEncoding = ContentEncodingGzip
+	return nil
+}
+
+// AfterSave restores the plain content so callers never see the stored form.
+func (wv *WorkspaceVersion) AfterSave(tx *gorm.DB) error {
+	if wv.plainLock != nil {
+		wv.LockFileContent, wv.ManifestContent = *wv.plainLock, *wv.plainManifest
+		wv.plainLock, wv.plainManifest = nil, nil
+		wv.ContentEncoding = ""
+	}
+	return nil
+}
+
+// AfterFind decodes compressed content loaded from the database.
+func (wv *WorkspaceVersion) AfterFind(tx *gorm.DB) error {
+	if wv.ContentEncoding == "" {
+		return nil
+	}
+	lock, err := DecodeVersionContent(wv.LockFileContent, wv.ContentEncoding)
+	if err != nil {
+		return fmt.Errorf("decoding lock file of version %d: %w", wv.VersionNumber, err)
+	}
+	manifest, err := DecodeVersionContent(wv.ManifestContent, wv.ContentEncoding)
+	if err != nil {
+		return fmt.Errorf("decoding manifest of version %d: %w", wv.VersionNumber, err)
+	}
+	wv.LockFileContent, wv.ManifestContent = lock, manifest
+	wv.ContentEncoding = ""
+	return nil
 }
 
 // BeforeCreate hook to generate UUID and version number
@@ -0,0 +1,140 @@
+package pixi
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/nebari-dev/nebi/internal/pkgmgr"
+)
+
+// EnvPrefix returns the directory pixi installs the named environment of
+// the workspace in dir into.
+func EnvPrefix(dir, env string) string {
+	return filepath.Join(dir, ".pixi", "envs", env)
+}
+
+// CurrentPlatform returns the pixi platform name (e.g. "linux-64") of the
+// running system, or "" if pixi has no name for it.
+func CurrentPlatform() string {
+	return platformName(runtime.GOOS, runtime.GOARCH)
+}
+
+func platformName(goos, goarch string) string {
+	system := map[string]string{"linux": "linux", "darwin": "osx", "windows": "win"}[goos]
+	arch := map[string]string{"amd64": "64", "arm64": "arm64", "ppc64le": "ppc64le", "386": "32"}[goarch]
+	if system == "" || arch == "" {
+		return ""
+	}
+	if system == "linux" && arch == "arm64" {
+		arch = "aarch64"
+	}
+	return system + "-" + arch
+}
+
+// InstalledPackages lists the packages present in an installed environment
+// prefix. Unlike "pixi list", which reports what pixi.lock pins, it reads
+// what is actually on disk: conda packages from the conda-meta records and
+// Python packages from site-packages metadata. Python distributions
+// installed by conda are skipped there since conda-meta already lists them,
+// so a manual "pip install" shows up as an extra pypi package.
+func InstalledPackages(prefix string) ([]pkgmgr.Package, error) {
+	if _, err := os.Stat(filepath.Join(prefix, "conda-meta")); err != nil {
+		return nil, fmt.Errorf("%s is not an installed environment: %w", prefix, err)
+	}
+	metaFiles, err := filepath.Glob(filepath.Join(prefix, "conda-meta", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []pkgmgr.Package
+	for _, f := range metaFiles {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var record struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			Channel string `json:"channel"`
+		}
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", f, err)
+		}
+		if record.Name == "" {
+			continue
+		}
+		packages = append(packages, pkgmgr.Package{
+			Name:    record.Name,
+			Version: record.Version,
+			Channel: record.Channel,
+			Kind:    "conda",
+		})
+	}
+
+	var distInfos []string
+	for _, pattern := range []string{
+		filepath.Join(prefix, "lib", "python*", "site-packages", "*.dist-info"),
+		filepath.Join(prefix, "Lib", "site-packages", "*.dist-info"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		distInfos = append(distInfos, matches...)
+	}
+	for _, d := range distInfos {
+		installer, _ := os.ReadFile(filepath.Join(d, "INSTALLER"))
+		if strings.TrimSpace(string(installer)) == "conda" {
+			continue
+		}
+		name, version := distInfoNameVersion(d)
+		if name == "" {
+			continue
+		}
+		packages = append(packages, pkgmgr.Package{Name: name, Version: version, Kind: "pypi"})
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Kind != packages[j].Kind {
+			return packages[i].Kind < packages[j].Kind
+		}
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// distInfoNameVersion reads the Name and Version headers of a .dist-info
+// directory's METADATA, falling back to the "<name>-<version>.dist-info"
+// directory name.
+func distInfoNameVersion(dir string) (name, version string) {
+	if f, err := os.Open(filepath.Join(dir, "METADATA")); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				break // end of headers
+			}
+			if v, ok := strings.CutPrefix(line, "Name: "); ok {
+				name = strings.TrimSpace(v)
+			} else if v, ok := strings.CutPrefix(line, "Version: "); ok {
+				version = strings.TrimSpace(v)
+			}
+		}
+		if name != "" {
+			return name, version
+		}
+	}
+
+	base := strings.TrimSuffix(filepath.Base(dir), ".dist-info")
+	if i := strings.LastIndex(base, "-"); i > 0 {
+		return base[:i], base[i+1:]
+	}
+	return base, ""
+}
@@ -0,0 +1,68 @@
+package pixi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/pkgmgr"
+)
+
+func TestInstalledPackages(t *testing.T) {
+	prefix := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(prefix, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("conda-meta/numpy-1.26.4-py312_0.json", `{"name": "numpy", "version": "1.26.4", "channel": "https://conda.anaconda.org/conda-forge/linux-64"}`)
+	write("conda-meta/history", "")
+	// numpy's own dist-info was installed by conda and is already listed.
+	write("lib/python3.12/site-packages/numpy-1.26.4.dist-info/INSTALLER", "conda\n")
+	// A manual pip install.
+	write("lib/python3.12/site-packages/rich-13.7.0.dist-info/INSTALLER", "pip\n")
+	write("lib/python3.12/site-packages/rich-13.7.0.dist-info/METADATA", "Metadata-Version: 2.1\nName: rich\nVersion: 13.7.0\n\nBody\n")
+	// Metadata missing: fall back to the directory name.
+	write("lib/python3.12/site-packages/six-1.16.0.dist-info/INSTALLER", "uv\n")
+
+	got, err := InstalledPackages(prefix)
+	if err != nil {
+		t.Fatalf("InstalledPackages() error: %v", err)
+	}
+	want := []pkgmgr.Package{
+		{Name: "numpy", Version: "1.26.4", Channel: "https://conda.anaconda.org/conda-forge/linux-64", Kind: "conda"},
+		{Name: "rich", Version: "13.7.0", Kind: "pypi"},
+		{Name: "six", Version: "1.16.0", Kind: "pypi"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InstalledPackages() = %+v, want %+v", got, want)
+	}
+}
+
+func TestInstalledPackages_NotInstalled(t *testing.T) {
+	if _, err := InstalledPackages(t.TempDir()); err == nil {
+		t.Error("expected error for a prefix without conda-meta")
+	}
+}
+
+func TestPlatformName(t *testing.T) {
+	tests := map[[2]string]string{
+		{"linux", "amd64"}:   "linux-64",
+		{"linux", "arm64"}:   "linux-aarch64",
+		{"darwin", "arm64"}:  "osx-arm64",
+		{"windows", "amd64"}: "win-64",
+		{"plan9", "amd64"}:   "",
+	}
+	for in, want := range tests {
+		if got := platformName(in[0], in[1]); got != want {
+			t.Errorf("platformName(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
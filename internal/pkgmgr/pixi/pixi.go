@@ -494,12 +494,14 @@ func (p *PixiManager) executeCommand(ctx context.Context, workDir string, args .
 // Modern pixi uses [workspace], older versions used [project].
 type pixiManifestWithWorkspace struct {
 	Workspace struct {
-		Name     string   `toml:"name"`
-		Channels []string `toml:"channels"`
+		Name      string   `toml:"name"`
+		Channels  []string `toml:"channels"`
+		Platforms []string `toml:"platforms"`
 	} `toml:"workspace"`
 	Project struct {
-		Name     string   `toml:"name"`
-		Channels []string `toml:"channels"`
+		Name      string   `toml:"name"`
+		Channels  []string `toml:"channels"`
+		Platforms []string `toml:"platforms"`
 	} `toml:"project"`
 }
 
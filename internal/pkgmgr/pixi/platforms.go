@@ -0,0 +1,110 @@
+package pixi
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// KnownPlatforms lists the platform identifiers pixi accepts in a
+// manifest's platforms array.
+var KnownPlatforms = []string{
+	"emscripten-wasm32",
+	"linux-32", "linux-64", "linux-aarch64", "linux-armv6l", "linux-armv7l",
+	"linux-ppc64", "linux-ppc64le", "linux-riscv32", "linux-riscv64", "linux-s390x",
+	"osx-64", "osx-arm64",
+	"wasi-wasm32",
+	"win-32", "win-64", "win-arm64",
+	"zos-z",
+}
+
+// ValidatePlatforms checks that every entry is a known pixi platform and
+// that none is repeated.
+func ValidatePlatforms(platforms []string) error {
+	seen := make(map[string]bool, len(platforms))
+	for _, p := range platforms {
+		if !slices.Contains(KnownPlatforms, p) {
+			return fmt.Errorf("unknown platform %q (known platforms: %s)", p, strings.Join(KnownPlatforms, ", "))
+		}
+		if seen[p] {
+			return fmt.Errorf("platform %q is listed more than once", p)
+		}
+		seen[p] = true
+	}
+	return nil
+}
+
+// ExtractPlatforms reads the platforms array from pixi.toml content,
+// preferring [workspace] over the older [project] table.
+func ExtractPlatforms(content string) ([]string, error) {
+	var manifest pixiManifestWithWorkspace
+	if err := toml.Unmarshal([]byte(content), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse pixi.toml: %w", err)
+	}
+	if manifest.Workspace.Platforms != nil {
+		return manifest.Workspace.Platforms, nil
+	}
+	return manifest.Project.Platforms, nil
+}
+
+var platformsKeyRe = regexp.MustCompile(`^platforms\s*=`)
+
+// SetPlatforms returns content with the platforms array of the [workspace]
+// (or [project]) table replaced by platforms. Only that key is rewritten;
+// the rest of the manifest keeps its formatting and comments.
+func SetPlatforms(content string, platforms []string) (string, error) {
+	quoted := make([]string, len(platforms))
+	for i, p := range platforms {
+		quoted[i] = fmt.Sprintf("%q", p)
+	}
+	line := "platforms = [" + strings.Join(quoted, ", ") + "]"
+
+	lines := strings.Split(content, "\n")
+	header := -1
+	inTable := false
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "[") {
+			name, _, _ := strings.Cut(trimmed, "#")
+			name = strings.Trim(strings.TrimSpace(name), "[]")
+			inTable = (name == "workspace" || name == "project") && header < 0
+			if inTable {
+				header = i
+			}
+			continue
+		}
+		if !inTable || !platformsKeyRe.MatchString(trimmed) {
+			continue
+		}
+		// A multi-line array runs until its brackets balance.
+		end := i
+		depth := strings.Count(lines[i], "[") - strings.Count(lines[i], "]")
+		for depth > 0 && end+1 < len(lines) {
+			end++
+			depth += strings.Count(lines[end], "[") - strings.Count(lines[end], "]")
+		}
+		lines = slices.Replace(lines, i, end+1, line)
+		return checkPlatforms(strings.Join(lines, "\n"), platforms)
+	}
+	if header < 0 {
+		return "", fmt.Errorf("pixi.toml has no [workspace] or [project] table")
+	}
+	lines = slices.Insert(lines, header+1, line)
+	return checkPlatforms(strings.Join(lines, "\n"), platforms)
+}
+
+// checkPlatforms guards SetPlatforms' line-based edit by re-parsing the
+// result.
+func checkPlatforms(content string, want []string) (string, error) {
+	got, err := ExtractPlatforms(content)
+	if err != nil {
+		return "", fmt.Errorf("rewriting platforms: %w", err)
+	}
+	if !slices.Equal(got, want) {
+		return "", fmt.Errorf("rewriting platforms: got %v, want %v", got, want)
+	}
+	return content, nil
+}
@@ -0,0 +1,54 @@
+package pixi
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidatePlatforms(t *testing.T) {
+	if err := ValidatePlatforms([]string{"linux-64", "osx-arm64", "win-64"}); err != nil {
+		t.Errorf("expected known platforms to pass, got: %v", err)
+	}
+	if err := ValidatePlatforms([]string{"linux-x86_64"}); err == nil || !strings.Contains(err.Error(), "unknown platform") {
+		t.Errorf("expected unknown platform error, got: %v", err)
+	}
+	if err := ValidatePlatforms([]string{"linux-64", "linux-64"}); err == nil {
+		t.Error("expected duplicate platform error")
+	}
+}
+
+func TestSetPlatforms(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"single line", "[workspace]\nname = \"x\"\nplatforms = [\"linux-64\"] # solve targets\n\n[dependencies]\npython = \"*\"\n"},
+		{"multi line", "[workspace]\nname = \"x\"\nplatforms = [\n  \"linux-64\",\n  \"osx-64\",\n]\n\n[dependencies]\npython = \"*\"\n"},
+		{"project table", "[project]\nname = \"x\"\nplatforms = [\"linux-64\"]\n"},
+		{"missing key", "[workspace]\nname = \"x\"\n\n[dependencies]\npython = \"*\"\n"},
+	}
+	want := []string{"linux-64", "osx-arm64"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetPlatforms(tt.content, want)
+			if err != nil {
+				t.Fatalf("SetPlatforms: %v", err)
+			}
+			platforms, err := ExtractPlatforms(got)
+			if err != nil {
+				t.Fatalf("ExtractPlatforms: %v", err)
+			}
+			if !slices.Equal(platforms, want) {
+				t.Errorf("platforms = %v, want %v\n%s", platforms, want, got)
+			}
+			if strings.Contains(tt.content, "python") && !strings.Contains(got, "[dependencies]\npython = \"*\"") {
+				t.Errorf("expected the rest of the manifest to be kept:\n%s", got)
+			}
+		})
+	}
+
+	if _, err := SetPlatforms("[dependencies]\npython = \"*\"\n", want); err == nil {
+		t.Error("expected an error without a [workspace] table")
+	}
+}
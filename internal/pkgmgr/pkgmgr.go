@@ -70,6 +70,7 @@ type Package struct {
 	Name    string
 	Version string
 	Channel string // For conda-based managers
+	Kind    string // "conda" or "pypi" when known
 }
 
 // Manifest represents a package manager manifest file
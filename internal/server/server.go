@@ -16,18 +16,22 @@ import (
 
 	"github.com/nebari-dev/nebi/internal/api"
 	"github.com/nebari-dev/nebi/internal/api/handlers"
+	"github.com/nebari-dev/nebi/internal/audit"
 	"github.com/nebari-dev/nebi/internal/config"
 	nebicrypto "github.com/nebari-dev/nebi/internal/crypto"
 	"github.com/nebari-dev/nebi/internal/db"
 	"github.com/nebari-dev/nebi/internal/executor"
 	"github.com/nebari-dev/nebi/internal/logger"
 	"github.com/nebari-dev/nebi/internal/logstream"
+	"github.com/nebari-dev/nebi/internal/models"
 	"github.com/nebari-dev/nebi/internal/netguard"
 	"github.com/nebari-dev/nebi/internal/queue"
 	"github.com/nebari-dev/nebi/internal/rbac"
 	"github.com/nebari-dev/nebi/internal/service"
 	"github.com/nebari-dev/nebi/internal/store"
+	"github.com/nebari-dev/nebi/internal/versioncache"
 	"github.com/nebari-dev/nebi/internal/worker"
+	"github.com/nebari-dev/nebi/internal/wsevents"
 
 	"github.com/valkey-io/valkey-go"
 	"gorm.io/gorm"
@@ -103,6 +107,23 @@ func Run(ctx context.Context, cfg Config) error {
 	}
 	slog.Info("Database migrations completed")
 
+	models.SetVersionContentCompression(appCfg.Storage.CompressVersions)
+	if appCfg.Storage.CompressVersions && appCfg.Storage.CompressExistingVersions {
+		go func() {
+			n, err := db.CompressVersionContent(database, 100)
+			if err != nil {
+				slog.Error("Failed to compress existing version content", "compressed", n, "error", err)
+				return
+			}
+			slog.Info("Compressed existing version content", "versions", n)
+		}()
+	}
+
+	if sinks := audit.NewSinks(appCfg.Audit); len(sinks) > 0 {
+		audit.SetSinks(sinks...)
+		slog.Info("Audit sinks configured", "count", len(sinks))
+	}
+
 	// Create default admin user if configured (team mode only)
 	if !appCfg.IsLocalMode() {
 		// Initialize RBAC early so CreateDefaultAdmin can grant admin role
@@ -162,6 +183,15 @@ func Run(ctx context.Context, cfg Config) error {
 		return fmt.Errorf("failed to derive encryption key: %w", err)
 	}
 	workerSvc := service.New(database, jobQueue, exec, appCfg.IsLocalMode(), workerEncKey, rbac.NewDefaultProvider())
+	workerSvc.ApplyWorkspacesConfig(appCfg.Workspaces)
+	// Shared with the router so status changes made by an in-process worker
+	// reach the workspace events stream.
+	events := wsevents.NewBroker()
+	workerSvc.SetEventBroker(events)
+	// Shared with the router too, so workspaces deleted by the worker leave
+	// the cache the API serves version files from.
+	versionCache := versioncache.New(appCfg.Workspaces.VersionCacheBytes)
+	workerSvc.SetVersionCache(versionCache)
 	workerJobSvc := service.NewJobService(database, appCfg.IsLocalMode())
 
 	// Initialize and start worker if needed
@@ -186,7 +216,7 @@ func Run(ctx context.Context, cfg Config) error {
 		}
 
 		var valkeyClientInterface interface{} = valkeyClient
-		router := api.NewRouter(appCfg, database, jobQueue, exec, broker, valkeyClientInterface, slog.Default())
+		router := api.NewRouter(appCfg, database, jobQueue, exec, broker, events, versionCache, valkeyClientInterface, slog.Default())
 
 		var handler http.Handler = router
 		if appCfg.IsLocalMode() {
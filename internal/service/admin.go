@@ -177,6 +177,50 @@ func (s *AdminService) ListUserGroups(userID uuid.UUID) ([]models.Group, error)
 	return groups, nil
 }
 
+// UserWorkspace is a workspace together with the role a given user holds on it.
+type UserWorkspace struct {
+	WorkspaceResponse
+	Role string `json:"role"`
+}
+
+// ListUserWorkspaces returns every workspace the given user can access, across
+// all owners, with the strongest role they hold on each: "owner" for
+// workspaces they own, otherwise the best direct or group permission.
+func (s *AdminService) ListUserWorkspaces(userID uuid.UUID) ([]UserWorkspace, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &NotFoundError{Message: "User not found"}
+		}
+		return nil, err
+	}
+
+	grants := workspaceGrants(s.db, s.rbac, userID)
+	query := s.db.Where("owner_id = ?", userID)
+	if len(grants) > 0 {
+		wsIDs := make([]uuid.UUID, 0, len(grants))
+		for id := range grants {
+			wsIDs = append(wsIDs, id)
+		}
+		query = query.Or("id IN ?", wsIDs)
+	}
+
+	var workspaces []models.Workspace
+	if err := query.Preload("Owner").Order("created_at DESC").Find(&workspaces).Error; err != nil {
+		return nil, fmt.Errorf("list user workspaces: %w", err)
+	}
+
+	result := make([]UserWorkspace, len(workspaces))
+	for i, ws := range workspaces {
+		role := grants[ws.ID]
+		if ws.OwnerID == userID {
+			role = "owner"
+		}
+		result[i] = UserWorkspace{WorkspaceResponse: NewWorkspaceResponse(ws), Role: role}
+	}
+	return result, nil
+}
+
 // ListRoles returns all roles.
 func (s *AdminService) ListRoles() ([]models.Role, error) {
 	var roles []models.Role
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -402,3 +403,61 @@ func TestListUserGroups_ReturnsOnlyTheirs(t *testing.T) {
 		t.Fatalf("expected bob in 0 groups, got %d", len(bobGroups))
 	}
 }
+
+// --- ListUserWorkspaces ---
+
+func TestListUserWorkspaces_RolesAcrossOwners(t *testing.T) {
+	svc, wsSvc, db := adminTestSetup(t)
+	groupSvc := NewGroupService(db, rbac.NewDefaultProvider())
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+	carol := createTestUser(t, db, "carol")
+	db.Create(&models.Role{Name: "viewer"})
+	db.Create(&models.Role{Name: "editor"})
+
+	own := createReadyWorkspace(t, wsSvc, db, "bob-own", bob)
+	direct := createReadyWorkspace(t, wsSvc, db, "alice-direct", alice)
+	both := createReadyWorkspace(t, wsSvc, db, "alice-both", alice)
+	createReadyWorkspace(t, wsSvc, db, "carol-private", carol)
+
+	if _, err := wsSvc.ShareWorkspace(direct.ID.String(), alice, bob, "viewer"); err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	// bob holds viewer directly and editor via a group; editor wins.
+	if _, err := wsSvc.ShareWorkspace(both.ID.String(), alice, bob, "viewer"); err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	g, _ := groupSvc.CreateGroup(CreateGroupRequest{Name: "team"}, alice)
+	_ = groupSvc.AddMember(g.ID, alice, alice)
+	_ = groupSvc.AddMember(g.ID, bob, alice)
+	if _, err := wsSvc.ShareWorkspaceWithGroup(both.ID.String(), alice, g.ID, "editor"); err != nil {
+		t.Fatalf("share with group: %v", err)
+	}
+
+	results, err := svc.ListUserWorkspaces(bob)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	roles := map[uuid.UUID]string{}
+	for _, r := range results {
+		roles[r.ID] = r.Role
+	}
+	want := map[uuid.UUID]string{own.ID: "owner", direct.ID: "viewer", both.ID: "editor"}
+	if len(roles) != len(want) {
+		t.Fatalf("expected %d workspaces, got %+v", len(want), roles)
+	}
+	for id, role := range want {
+		if roles[id] != role {
+			t.Errorf("workspace %s: expected role %q, got %q", id, role, roles[id])
+		}
+	}
+}
+
+func TestListUserWorkspaces_UserNotFound(t *testing.T) {
+	svc, _, _ := adminTestSetup(t)
+
+	_, err := svc.ListUserWorkspaces(uuid.New())
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
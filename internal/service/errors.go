@@ -5,6 +5,17 @@ import "errors"
 // ErrNotFound indicates the requested resource was not found.
 var ErrNotFound = errors.New("not found")
 
+// NotFoundError is a not-found condition (HTTP 404) carrying a message for
+// the caller. It matches ErrNotFound with errors.Is.
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string { return e.Message }
+
+// Is reports whether target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool { return target == ErrNotFound }
+
 // ValidationError represents a bad-request condition (HTTP 400).
 type ValidationError struct {
 	Message string
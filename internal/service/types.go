@@ -11,9 +11,13 @@ type CreateRequest struct {
 	Name             string
 	PackageManager   string
 	PixiToml         string
+	PixiLock         string // optional; with PixiToml, seeds the lock and tags the initial version
+	InitialTag       string // tag for the initial version; defaults to workspaces.initial_tag
 	Source           string
 	Path             string
 	ImportStagingDir string // absolute path to a pre-extracted bundle directory; worker hands it to the executor as SeedDir
+	CloneHistoryFrom string // source workspace ID whose earlier versions the worker copies before the initial snapshot
+	CloneHistoryUpTo int    // with CloneHistoryFrom, the cloned version; only versions numbered below it are copied
 }
 
 // PushRequest holds parameters for pushing a new version.
@@ -30,7 +34,8 @@ type PushResult struct {
 	Tags          []string
 	ContentHash   string
 	Deduplicated  bool
-	Tag           string // kept for backwards compatibility
+	Tag           string   // kept for backwards compatibility
+	EvictedTags   []string // oldest tags removed to stay within workspaces.max_tags
 }
 
 // WorkspaceResponse wraps a workspace with computed fields.
@@ -40,6 +45,7 @@ type WorkspaceResponse struct {
 	models.Workspace
 	SizeFormatted string               `json:"size_formatted,omitempty"`
 	InstallStatus models.InstallStatus `json:"install_status,omitempty"`
+	TagLimit      *TagLimit            `json:"tag_limit,omitempty"` // set by Get only
 }
 
 // NewWorkspaceResponse creates a WorkspaceResponse with formatted size.
@@ -5,15 +5,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/config"
 	"github.com/nebari-dev/nebi/internal/contenthash"
 	"github.com/nebari-dev/nebi/internal/executor"
 	"github.com/nebari-dev/nebi/internal/models"
 	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
 	"github.com/nebari-dev/nebi/internal/queue"
 	"github.com/nebari-dev/nebi/internal/rbac"
+	"github.com/nebari-dev/nebi/internal/versioncache"
+	"github.com/nebari-dev/nebi/internal/wsevents"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 )
 
@@ -25,11 +31,70 @@ type WorkspaceService struct {
 	rbac     rbac.Provider
 	isLocal  bool
 	encKey   []byte
+	events   *wsevents.Broker
+
+	allowPushAutoCreate bool
+	maxVersionsListed   int
+	initialTag          string
+	versionCache        *versioncache.Cache
+	maxTags             int
+	tagLimitPolicy      string
 }
 
+// DefaultMaxVersionsListed is the page size cap used by ListVersions when
+// none is configured.
+const DefaultMaxVersionsListed = 100
+
+// DefaultInitialTag is the tag given to the initial version of a workspace
+// created with content when none is configured.
+const DefaultInitialTag = "v0"
+
 // New creates a new WorkspaceService.
 func New(db *gorm.DB, q queue.Queue, exec executor.Executor, isLocal bool, encKey []byte, rbacProvider rbac.Provider) *WorkspaceService {
-	return &WorkspaceService{db: db, queue: q, executor: exec, isLocal: isLocal, encKey: encKey, rbac: rbacProvider}
+	return &WorkspaceService{db: db, queue: q, executor: exec, isLocal: isLocal, encKey: encKey, rbac: rbacProvider, allowPushAutoCreate: true, maxVersionsListed: DefaultMaxVersionsListed, initialTag: DefaultInitialTag, tagLimitPolicy: TagLimitReject}
+}
+
+// ApplyWorkspacesConfig applies the workspaces.* policy settings. The
+// router's and the worker's services both call it, so settings enforced by
+// worker jobs (such as the tag limit on history clones) match the API.
+func (s *WorkspaceService) ApplyWorkspacesConfig(cfg config.WorkspacesConfig) {
+	s.SetAllowPushAutoCreate(cfg.AllowPushAutoCreate)
+	s.SetMaxVersionsListed(cfg.MaxVersionsListed)
+	s.SetInitialTag(cfg.InitialTag)
+	s.SetMaxTags(cfg.MaxTags)
+	s.SetTagLimitPolicy(cfg.TagLimitPolicy)
+}
+
+// SetAllowPushAutoCreate sets whether pushes may create missing workspaces
+// (workspaces.allow_push_autocreate). See CheckCreateAllowed.
+func (s *WorkspaceService) SetAllowPushAutoCreate(allow bool) {
+	s.allowPushAutoCreate = allow
+}
+
+// SetMaxVersionsListed caps how many versions a single ListVersions call
+// returns (workspaces.max_versions_listed). Non-positive values restore
+// the default.
+func (s *WorkspaceService) SetMaxVersionsListed(n int) {
+	if n <= 0 {
+		n = DefaultMaxVersionsListed
+	}
+	s.maxVersionsListed = n
+}
+
+// SetInitialTag sets the tag given to the initial version of a workspace
+// created with pixi_toml and pixi_lock (workspaces.initial_tag).
+func (s *WorkspaceService) SetInitialTag(tag string) {
+	if tag == "" {
+		tag = DefaultInitialTag
+	}
+	s.initialTag = tag
+}
+
+// SetVersionCache shares c as the version content cache. The server hands
+// the router's and the worker's services the same cache so a workspace
+// deleted by the worker is dropped from the cache the API reads through.
+func (s *WorkspaceService) SetVersionCache(c *versioncache.Cache) {
+	s.versionCache = c
 }
 
 // IsLocal reports whether the service is running in local/desktop mode.
@@ -48,26 +113,12 @@ func (s *WorkspaceService) List(userID uuid.UUID) ([]WorkspaceResponse, error) {
 		// Team mode: owner + permission-based filtering
 		query := s.db.Where("owner_id = ?", userID)
 
-		var permissions []models.Permission
-		s.db.Where("user_id = ?", userID).Find(&permissions)
-
-		wsIDs := []uuid.UUID{}
-		for _, p := range permissions {
-			wsIDs = append(wsIDs, p.WorkspaceID)
-		}
-
-		// Group-mediated permissions: include workspaces shared with any
-		// group the caller belongs to. Casbin grouping rules are the source
-		// of truth for membership (same query the matcher uses transitively).
-		if userGroups, err := s.rbac.GetUserGroups(userID); err == nil && len(userGroups) > 0 {
-			var groupPerms []models.GroupPermission
-			s.db.Where("group_id IN ?", userGroups).Find(&groupPerms)
-			for _, gp := range groupPerms {
-				wsIDs = append(wsIDs, gp.WorkspaceID)
+		grants := workspaceGrants(s.db, s.rbac, userID)
+		if len(grants) > 0 {
+			wsIDs := make([]uuid.UUID, 0, len(grants))
+			for id := range grants {
+				wsIDs = append(wsIDs, id)
 			}
-		}
-
-		if len(wsIDs) > 0 {
 			query = query.Or("id IN ?", wsIDs)
 		}
 
@@ -86,6 +137,40 @@ func (s *WorkspaceService) List(userID uuid.UUID) ([]WorkspaceResponse, error) {
 	return result, nil
 }
 
+// roleRank orders workspace roles so the strongest grant wins when a user
+// reaches a workspace through more than one permission.
+var roleRank = map[string]int{"viewer": 1, "editor": 2, "owner": 3}
+
+// workspaceGrants returns the workspaces userID can reach through a direct
+// or group-mediated permission, mapped to the strongest role granted.
+// Ownership is not included; callers match it separately on owner_id.
+func workspaceGrants(db *gorm.DB, provider rbac.Provider, userID uuid.UUID) map[uuid.UUID]string {
+	grants := map[uuid.UUID]string{}
+	grant := func(wsID uuid.UUID, role string) {
+		if cur, ok := grants[wsID]; !ok || roleRank[role] > roleRank[cur] {
+			grants[wsID] = role
+		}
+	}
+
+	var permissions []models.Permission
+	db.Preload("Role").Where("user_id = ?", userID).Find(&permissions)
+	for _, p := range permissions {
+		grant(p.WorkspaceID, p.Role.Name)
+	}
+
+	// Group-mediated permissions: include workspaces shared with any
+	// group the user belongs to. Casbin grouping rules are the source
+	// of truth for membership (same query the matcher uses transitively).
+	if userGroups, err := provider.GetUserGroups(userID); err == nil && len(userGroups) > 0 {
+		var groupPerms []models.GroupPermission
+		db.Preload("Role").Where("group_id IN ?", userGroups).Find(&groupPerms)
+		for _, gp := range groupPerms {
+			grant(gp.WorkspaceID, gp.Role.Name)
+		}
+	}
+	return grants
+}
+
 // Get returns a single workspace by ID.
 func (s *WorkspaceService) Get(id string) (*WorkspaceResponse, error) {
 	var ws models.Workspace
@@ -99,9 +184,33 @@ func (s *WorkspaceService) Get(id string) (*WorkspaceResponse, error) {
 	if s.isLocal {
 		resp.InstallStatus = s.installStatusFor(&ws)
 	}
+	limit, err := s.tagLimit(&ws)
+	if err != nil {
+		return nil, err
+	}
+	resp.TagLimit = limit
 	return &resp, nil
 }
 
+// CheckCreateAllowed enforces workspaces.allow_push_autocreate on direct
+// workspace creation (POST /workspaces), which is how `nebi push` creates a
+// missing workspace. When the policy is off only administrators may create
+// workspaces there, so a push can never provision one implicitly. Clones and
+// imports have their own endpoints and are not affected.
+func (s *WorkspaceService) CheckCreateAllowed(name string, userID uuid.UUID) error {
+	if s.allowPushAutoCreate || s.isLocal {
+		return nil
+	}
+	if isAdmin, err := s.rbac.IsAdmin(userID); err == nil && isAdmin {
+		return nil
+	}
+	audit.LogAction(s.db, userID, audit.ActionAutoCreateDenied, fmt.Sprintf("ws:%s", name), map[string]interface{}{
+		"name": name,
+	})
+	return &NotFoundError{Message: fmt.Sprintf(
+		"workspace %q does not exist and this server does not allow push to create workspaces; ask an administrator to create it first", name)}
+}
+
 // Create validates and creates a new workspace, queues the creation job,
 // grants RBAC owner access, and writes an audit log entry.
 func (s *WorkspaceService) Create(ctx context.Context, req CreateRequest, userID uuid.UUID) (*models.Workspace, error) {
@@ -128,6 +237,28 @@ func (s *WorkspaceService) Create(ctx context.Context, req CreateRequest, userID
 		return nil, &ValidationError{Message: fmt.Sprintf("invalid pixi.toml: %v", err)}
 	}
 
+	// Initial content (a lock file or an explicit tag) is validated up front
+	// so a bad request never leaves behind an empty workspace.
+	var initialTag string
+	if req.PixiLock != "" || req.InitialTag != "" {
+		if req.PixiToml == "" {
+			return nil, &ValidationError{Message: "pixi_lock and initial_tag require pixi_toml"}
+		}
+		if req.PixiLock != "" {
+			var lock map[string]interface{}
+			if err := yaml.Unmarshal([]byte(req.PixiLock), &lock); err != nil {
+				return nil, &ValidationError{Message: fmt.Sprintf("invalid pixi.lock: %v", err)}
+			}
+		}
+		initialTag = req.InitialTag
+		if initialTag == "" {
+			initialTag = s.initialTag
+		}
+		if initialTag == "latest" || strings.HasPrefix(initialTag, "sha-") || strings.ContainsAny(initialTag, ": /") {
+			return nil, &ValidationError{Message: fmt.Sprintf("invalid initial_tag %q", initialTag)}
+		}
+	}
+
 	ws := models.Workspace{
 		Name:           name,
 		OwnerID:        userID,
@@ -147,9 +278,19 @@ func (s *WorkspaceService) Create(ctx context.Context, req CreateRequest, userID
 		if req.PixiToml != "" {
 			metadata["pixi_toml"] = req.PixiToml
 		}
+		if req.PixiLock != "" {
+			metadata["pixi_lock"] = req.PixiLock
+		}
+		if initialTag != "" {
+			metadata["initial_tag"] = initialTag
+		}
 		if req.ImportStagingDir != "" {
 			metadata["import_staging_dir"] = req.ImportStagingDir
 		}
+		if req.CloneHistoryFrom != "" {
+			metadata["clone_history_from"] = req.CloneHistoryFrom
+			metadata["clone_history_up_to"] = strconv.Itoa(req.CloneHistoryUpTo)
+		}
 
 		job := &models.Job{
 			Type:        models.JobTypeCreate,
@@ -164,10 +305,14 @@ func (s *WorkspaceService) Create(ctx context.Context, req CreateRequest, userID
 			return fmt.Errorf("enqueue job: %w", err)
 		}
 
-		audit.LogAction(tx, userID, audit.ActionCreateWorkspace, fmt.Sprintf("ws:%s", ws.ID.String()), map[string]interface{}{
+		details := map[string]interface{}{
 			"name":            ws.Name,
 			"package_manager": ws.PackageManager,
-		})
+		}
+		if initialTag != "" {
+			details["initial_tag"] = initialTag
+		}
+		audit.LogAction(tx, userID, audit.ActionCreateWorkspace, fmt.Sprintf("ws:%s", ws.ID.String()), details)
 
 		return nil
 	})
@@ -181,6 +326,8 @@ func (s *WorkspaceService) Create(ctx context.Context, req CreateRequest, userID
 		return nil, fmt.Errorf("grant owner access: %w", err)
 	}
 
+	s.publishEvent(wsevents.TypeCreated, ws.ID, ws.Status)
+
 	return &ws, nil
 }
 
@@ -248,6 +395,7 @@ func (s *WorkspaceService) SavePixiToml(wsID string, content string) error {
 	if err := os.WriteFile(filepath.Join(wsPath, "pixi.toml"), []byte(content), 0644); err != nil {
 		return fmt.Errorf("write pixi.toml: %w", err)
 	}
+	s.publishEvent(wsevents.TypeUpdated, ws.ID, ws.Status)
 	return nil
 }
 
@@ -274,8 +422,33 @@ func (s *WorkspaceService) upsertTag(wsID uuid.UUID, tag string, versionNumber i
 	}).Error
 }
 
+// TagInitialVersion tags the newest version of a newly created workspace the
+// way PushVersion tags pushed content: with its content hash, "latest" and
+// the given tag. The worker calls it after the create job's snapshot.
+func (s *WorkspaceService) TagInitialVersion(wsID uuid.UUID, tag string, userID uuid.UUID) error {
+	var version models.WorkspaceVersion
+	if err := s.db.Where("workspace_id = ?", wsID).Order("version_number DESC").First(&version).Error; err != nil {
+		return fmt.Errorf("load initial version: %w", err)
+	}
+
+	hashTag := contentHash(version.ManifestContent, version.LockFileContent)
+	if err := s.db.Model(&models.WorkspaceVersion{}).Where("id = ?", version.ID).UpdateColumn("content_hash", hashTag).Error; err != nil {
+		return fmt.Errorf("set content hash: %w", err)
+	}
+
+	for _, t := range []string{hashTag, "latest", tag} {
+		if err := s.upsertTag(wsID, t, version.VersionNumber, userID); err != nil {
+			return fmt.Errorf("create tag %q: %w", t, err)
+		}
+	}
+
+	s.publishEvent(wsevents.TypeUpdated, wsID, models.WsStatusReady)
+	return nil
+}
+
 // PushVersion creates a new workspace version (or deduplicates), writes files,
-// handles tags (content hash, latest, optional user tag), and records audit logs.
+// handles tags (content hash, latest, auto-latest, optional user tag), and
+// records audit logs.
 func (s *WorkspaceService) PushVersion(ctx context.Context, wsID string, req PushRequest, userID uuid.UUID) (*PushResult, error) {
 	var ws models.Workspace
 	if err := s.db.Where("id = ?", wsID).First(&ws).Error; err != nil {
@@ -289,8 +462,10 @@ func (s *WorkspaceService) PushVersion(ctx context.Context, wsID string, req Pus
 		return nil, &ValidationError{Message: "Workspace must be in ready state to push"}
 	}
 
-	// Check user-tag conflict before any side effects
-	if req.Tag != "" {
+	// Check user-tag conflict and the tag limit before any side effects.
+	// The auto-latest tag moves on every push, so it never conflicts.
+	var evict []models.WorkspaceTag
+	if req.Tag != "" && req.Tag != ws.AutoLatestTag {
 		var existingUserTag models.WorkspaceTag
 		if err := s.db.Where("workspace_id = ? AND tag = ?", ws.ID, req.Tag).First(&existingUserTag).Error; err == nil {
 			if !req.Force {
@@ -298,6 +473,11 @@ func (s *WorkspaceService) PushVersion(ctx context.Context, wsID string, req Pus
 					Message: fmt.Sprintf("tag %q already exists at version %d; use --force to reassign", req.Tag, existingUserTag.VersionNumber),
 				}
 			}
+		} else if isUserTag(&ws, req.Tag) {
+			var err error
+			if evict, err = s.checkTagLimit(&ws); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -360,8 +540,20 @@ func (s *WorkspaceService) PushVersion(ctx context.Context, wsID string, req Pus
 
 	tags := []string{hashTag, "latest"}
 
+	if ws.AutoLatestTag != "" {
+		if err := s.upsertTag(ws.ID, ws.AutoLatestTag, versionNumber, userID); err != nil {
+			return nil, fmt.Errorf("update auto-latest tag: %w", err)
+		}
+		tags = append(tags, ws.AutoLatestTag)
+	}
+
 	// Handle optional user tag
-	if req.Tag != "" {
+	var evicted []string
+	if req.Tag != "" && req.Tag != ws.AutoLatestTag {
+		var err error
+		if evicted, err = s.evictTags(&ws, evict, userID); err != nil {
+			return nil, err
+		}
 		if err := s.upsertTag(ws.ID, req.Tag, versionNumber, userID); err != nil {
 			return nil, fmt.Errorf("create user tag: %w", err)
 		}
@@ -375,27 +567,61 @@ func (s *WorkspaceService) PushVersion(ctx context.Context, wsID string, req Pus
 		"deduplicated": deduplicated,
 	})
 
+	s.publishEvent(wsevents.TypeUpdated, ws.ID, ws.Status)
+
 	return &PushResult{
 		VersionNumber: versionNumber,
 		Tags:          tags,
 		ContentHash:   hashTag,
 		Deduplicated:  deduplicated,
 		Tag:           req.Tag,
+		EvictedTags:   evicted,
 	}, nil
 }
 
-// ListVersions returns versions for a workspace (excluding large file contents).
-func (s *WorkspaceService) ListVersions(wsID string) ([]models.WorkspaceVersion, error) {
-	var versions []models.WorkspaceVersion
-	err := s.db.
+// ListVersionsOptions controls paging for ListVersions.
+type ListVersionsOptions struct {
+	Limit  int // Maximum versions to return; 0 or above the server cap means the cap
+	Before int // Only return versions numbered below this; 0 starts from the newest
+}
+
+// VersionPage is one page of ListVersions results, newest first.
+type VersionPage struct {
+	Versions   []models.WorkspaceVersion
+	NextBefore int // Cursor for the next page; 0 when there are no older versions
+}
+
+// ListVersions returns a page of versions for a workspace (excluding large
+// file contents), ordered by version number descending so that the last
+// version number of one page is a stable cursor for the next.
+func (s *WorkspaceService) ListVersions(wsID string, opts ListVersionsOptions) (*VersionPage, error) {
+	if opts.Limit < 0 || opts.Before < 0 {
+		return nil, &ValidationError{Message: "limit and before must not be negative"}
+	}
+	limit := opts.Limit
+	if limit == 0 || limit > s.maxVersionsListed {
+		limit = s.maxVersionsListed
+	}
+
+	query := s.db.
 		Select("id", "workspace_id", "version_number", "job_id", "created_by", "description", "created_at").
-		Where("workspace_id = ?", wsID).
-		Order("version_number DESC").
-		Find(&versions).Error
-	if err != nil {
+		Where("workspace_id = ?", wsID)
+	if opts.Before > 0 {
+		query = query.Where("version_number < ?", opts.Before)
+	}
+
+	// Fetch one extra row to learn whether an older page exists.
+	var versions []models.WorkspaceVersion
+	if err := query.Order("version_number DESC").Limit(limit + 1).Find(&versions).Error; err != nil {
 		return nil, err
 	}
-	return versions, nil
+
+	page := &VersionPage{Versions: versions}
+	if len(versions) > limit {
+		page.Versions = versions[:limit]
+		page.NextBefore = page.Versions[limit-1].VersionNumber
+	}
+	return page, nil
 }
 
 // GetVersion returns a specific version by workspace ID and version number.
@@ -426,9 +652,16 @@ func (s *WorkspaceService) GetVersionFile(wsID string, versionNum string, field
 		return "", &ValidationError{Message: "field must be 'lock' or 'manifest'"}
 	}
 
+	// Version content never changes once written, so cached entries only
+	// need dropping when the workspace itself goes away.
+	key := versioncache.Key(wsID, versionNum, field)
+	if content, ok := s.versionCache.Get(key); ok {
+		return content, nil
+	}
+
 	var version models.WorkspaceVersion
 	err := s.db.
-		Select(selectField).
+		Select(selectField, "content_encoding").
 		Where("workspace_id = ? AND version_number = ?", wsID, versionNum).
 		First(&version).Error
 	if err != nil {
@@ -438,10 +671,12 @@ func (s *WorkspaceService) GetVersionFile(wsID string, versionNum string, field
 		return "", err
 	}
 
+	content := version.ManifestContent
 	if field == "lock" {
-		return version.LockFileContent, nil
+		content = version.LockFileContent
 	}
-	return version.ManifestContent, nil
+	s.versionCache.Add(key, content)
+	return content, nil
 }
 
 // ListTags returns tags for a workspace, ordered by creation time descending.
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/models"
+	"gorm.io/gorm"
+)
+
+// AutoLatestResult holds a workspace's auto-latest tag.
+type AutoLatestResult struct {
+	Tag string `json:"tag"` // empty when not set
+}
+
+// GetAutoLatestTag returns the tag every push to the workspace moves to the
+// pushed version, if any.
+func (s *WorkspaceService) GetAutoLatestTag(wsID string) (*AutoLatestResult, error) {
+	var ws models.Workspace
+	if err := s.db.Where("id = ?", wsID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &AutoLatestResult{Tag: ws.AutoLatestTag}, nil
+}
+
+// SetAutoLatestTag sets a tag that every push moves to the pushed version,
+// like "latest". The tag is mutable: pushes reassign it without --force, and
+// it does not count against workspaces.max_tags. An empty tag clears the
+// setting; an existing tag of that name is kept where it is.
+func (s *WorkspaceService) SetAutoLatestTag(wsID string, tag string, userID uuid.UUID) (*AutoLatestResult, error) {
+	if tag == "latest" || strings.HasPrefix(tag, "sha-") || strings.ContainsAny(tag, ": /") {
+		return nil, &ValidationError{Message: fmt.Sprintf("invalid auto-latest tag %q", tag)}
+	}
+
+	var ws models.Workspace
+	if err := s.db.Where("id = ?", wsID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.db.Model(&ws).Update("auto_latest_tag", tag).Error; err != nil {
+		return nil, fmt.Errorf("update auto-latest tag: %w", err)
+	}
+
+	audit.LogAction(s.db, userID, audit.ActionSetAutoLatest, fmt.Sprintf("ws:%s", ws.ID.String()), map[string]interface{}{
+		"tag": tag,
+	})
+
+	return &AutoLatestResult{Tag: tag}, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/models"
+)
+
+func TestPushVersion_AutoLatestTag(t *testing.T) {
+	svc, db := testSetup(t, true)
+	svc.SetMaxTags(1)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "rolling", userID)
+
+	if _, err := svc.SetAutoLatestTag(ws.ID.String(), "stable", userID); err != nil {
+		t.Fatalf("set auto-latest: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 1; i <= 2; i++ {
+		toml := fmt.Sprintf("[workspace]\nname = \"rolling\"\n# v%d\n", i)
+		r, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{PixiToml: toml}, userID)
+		if err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+		var tag models.WorkspaceTag
+		if err := db.Where("workspace_id = ? AND tag = ?", ws.ID, "stable").First(&tag).Error; err != nil {
+			t.Fatalf("push %d: auto-latest tag missing: %v", i, err)
+		}
+		if tag.VersionNumber != r.VersionNumber {
+			t.Errorf("push %d: stable at version %d, want %d", i, tag.VersionNumber, r.VersionNumber)
+		}
+	}
+
+	// Naming the auto-latest tag explicitly needs no --force, and the tag
+	// does not count against the limit of one user tag.
+	if _, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: "stable", PixiToml: "[workspace]\nname = \"rolling\"\n# v3\n"}, userID); err != nil {
+		t.Errorf("explicit auto-latest tag should not conflict: %v", err)
+	}
+	if _, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: "v4", PixiToml: "[workspace]\nname = \"rolling\"\n# v4\n"}, userID); err != nil {
+		t.Errorf("auto-latest tag should not count against the tag limit: %v", err)
+	}
+
+	if _, err := svc.SetAutoLatestTag(ws.ID.String(), "", userID); err != nil {
+		t.Fatalf("clear auto-latest: %v", err)
+	}
+	got, err := svc.GetAutoLatestTag(ws.ID.String())
+	if err != nil || got.Tag != "" {
+		t.Errorf("expected cleared setting, got %+v err=%v", got, err)
+	}
+}
+
+func TestSetAutoLatestTag_Invalid(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "rolling", userID)
+
+	for _, tag := range []string{"latest", "sha-abc", "a:b", "a b"} {
+		_, err := svc.SetAutoLatestTag(ws.ID.String(), tag, userID)
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("tag %q: expected ValidationError, got %v", tag, err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/models"
+	"gorm.io/gorm"
+)
+
+// CloneRequest describes a workspace clone.
+type CloneRequest struct {
+	Name    string // Name of the new workspace
+	History bool   // Also copy the source's earlier versions and their tags
+}
+
+// Clone creates a new workspace owned by userID from the newest version of
+// the source workspace. It goes through Create, so the clone is built by a
+// create job and its newest version is tagged like any workspace created
+// with initial content, using the source version's most recent user tag
+// when it has one and workspaces.initial_tag otherwise. With req.History
+// the worker also copies the source's earlier versions and their tags
+// before that snapshot. The source is not modified.
+func (s *WorkspaceService) Clone(ctx context.Context, srcID string, req CloneRequest, userID uuid.UUID) (*models.Workspace, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &ValidationError{Message: "name is required"}
+	}
+
+	var src models.Workspace
+	if err := s.db.Where("id = ?", srcID).First(&src).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	var latest models.WorkspaceVersion
+	if err := s.db.Where("workspace_id = ?", src.ID).Order("version_number DESC").First(&latest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ValidationError{Message: fmt.Sprintf("workspace %q has no versions to clone", src.Name)}
+		}
+		return nil, err
+	}
+
+	// The source's auto-latest tag moves on every push, so it says nothing
+	// about this version and is not carried over.
+	var userTag models.WorkspaceTag
+	if err := s.db.Where("workspace_id = ? AND version_number = ? AND tag <> ? AND tag <> ? AND tag NOT LIKE ?",
+		src.ID, latest.VersionNumber, "latest", src.AutoLatestTag, "sha-%").
+		Order("updated_at DESC").Limit(1).Find(&userTag).Error; err != nil {
+		return nil, err
+	}
+	initialTag := userTag.Tag
+	if initialTag == "" {
+		initialTag = s.initialTag
+	}
+
+	createReq := CreateRequest{
+		Name:           name,
+		PackageManager: src.PackageManager,
+		PixiToml:       latest.ManifestContent,
+		PixiLock:       latest.LockFileContent,
+		InitialTag:     initialTag,
+	}
+	if req.History {
+		tags, err := s.historyTags(&src, latest.VersionNumber)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.checkHistoryTagLimit(&src, tags); err != nil {
+			return nil, err
+		}
+		createReq.CloneHistoryFrom = src.ID.String()
+		createReq.CloneHistoryUpTo = latest.VersionNumber
+	}
+	ws, err := s.Create(ctx, createReq, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Log(s.db, userID, audit.ActionCloneWorkspace, audit.ResourceWorkspace, ws.ID, map[string]interface{}{
+		"source_id":      src.ID.String(),
+		"source_name":    src.Name,
+		"source_version": latest.VersionNumber,
+		"history":        req.History,
+	})
+
+	return ws, nil
+}
+
+// historyTags returns the source tags a history clone copies: those on
+// versions numbered below upTo except "latest" and the source's auto-latest
+// tag, oldest first.
+func (s *WorkspaceService) historyTags(src *models.Workspace, upTo int) ([]models.WorkspaceTag, error) {
+	var tags []models.WorkspaceTag
+	if err := s.db.Where("workspace_id = ? AND version_number < ? AND tag <> ? AND tag <> ?",
+		src.ID, upTo, "latest", src.AutoLatestTag).
+		Order("created_at ASC").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("load source tags: %w", err)
+	}
+	return tags, nil
+}
+
+// CopyVersionHistory copies the versions of the source workspace numbered
+// below upTo (the version the clone was made from) into wsID, oldest
+// first, together with the tags that point at them ("latest" and the
+// source's auto-latest tag excepted). Versions pushed to the source after
+// the clone was requested are not copied. Tags beyond workspaces.max_tags
+// are handled as on push: with TagLimitEvictOldest the oldest are left out
+// and each is audited as an eviction by userID; otherwise nothing is
+// copied. The worker calls it for history clones before the create job's
+// snapshot, which then becomes the clone's newest version.
+func (s *WorkspaceService) CopyVersionHistory(wsID uuid.UUID, srcID string, upTo int, userID uuid.UUID) error {
+	var src models.Workspace
+	if err := s.db.Where("id = ?", srcID).First(&src).Error; err != nil {
+		return fmt.Errorf("load source workspace: %w", err)
+	}
+
+	var versions []models.WorkspaceVersion
+	if err := s.db.Where("workspace_id = ? AND version_number < ?", srcID, upTo).Order("version_number ASC").Find(&versions).Error; err != nil {
+		return fmt.Errorf("load source versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+
+	tags, err := s.historyTags(&src, upTo)
+	if err != nil {
+		return err
+	}
+	evict, err := s.checkHistoryTagLimit(&src, tags)
+	if err != nil {
+		return err
+	}
+	skip := make(map[string]bool, len(evict))
+	for _, t := range evict {
+		skip[t.Tag] = true
+	}
+
+	renumbered := make(map[int]int, len(versions))
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, v := range versions {
+			cp := models.WorkspaceVersion{
+				WorkspaceID:     wsID,
+				ManifestContent: v.ManifestContent,
+				LockFileContent: v.LockFileContent,
+				PackageMetadata: v.PackageMetadata,
+				ContentHash:     v.ContentHash,
+				CreatedBy:       v.CreatedBy,
+				Description:     v.Description,
+				CreatedAt:       v.CreatedAt,
+			}
+			if err := tx.Create(&cp).Error; err != nil {
+				return fmt.Errorf("copy version %d: %w", v.VersionNumber, err)
+			}
+			renumbered[v.VersionNumber] = cp.VersionNumber
+		}
+
+		for _, t := range tags {
+			n, ok := renumbered[t.VersionNumber]
+			if !ok || skip[t.Tag] {
+				continue
+			}
+			if err := tx.Create(&models.WorkspaceTag{
+				WorkspaceID:   wsID,
+				Tag:           t.Tag,
+				VersionNumber: n,
+				CreatedBy:     t.CreatedBy,
+			}).Error; err != nil {
+				return fmt.Errorf("copy tag %q: %w", t.Tag, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range evict {
+		audit.Log(s.db, userID, audit.ActionEvictTag, audit.ResourceWorkspace, wsID, map[string]interface{}{
+			"tag":      t.Tag,
+			"version":  renumbered[t.VersionNumber],
+			"max_tags": s.maxTags,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/models"
+)
+
+func TestClone(t *testing.T) {
+	svc, db := testSetup(t, true)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+	ctx := context.Background()
+
+	src := createReadyWorkspace(t, svc, db, "shared", alice)
+	_, err := svc.Clone(ctx, src.ID.String(), CloneRequest{Name: "empty-fork"}, bob)
+	var ve *ValidationError
+	if !isValidationError(err, &ve) {
+		t.Fatalf("expected ValidationError for a source without versions, got %v", err)
+	}
+
+	for i, tag := range []string{"v1", "v2", "v3"} {
+		toml := "[workspace]\nname = \"shared\"\n# " + tag + "\n"
+		if _, err := svc.PushVersion(ctx, src.ID.String(), PushRequest{Tag: tag, PixiToml: toml, PixiLock: "version: 6\n"}, alice); err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+	}
+
+	if _, err := svc.Clone(ctx, "00000000-0000-0000-0000-000000000000", CloneRequest{Name: "x"}, bob); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown source, got %v", err)
+	}
+	if _, err := svc.Clone(ctx, src.ID.String(), CloneRequest{Name: " "}, bob); !isValidationError(err, &ve) {
+		t.Fatalf("expected ValidationError for empty name, got %v", err)
+	}
+
+	fork, err := svc.Clone(ctx, src.ID.String(), CloneRequest{Name: "bobs-fork", History: true}, bob)
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if fork.OwnerID != bob || fork.Name != "bobs-fork" {
+		t.Errorf("fork owner/name = %s/%s, want bob/bobs-fork", fork.OwnerID, fork.Name)
+	}
+
+	var job models.Job
+	if err := db.Where("workspace_id = ?", fork.ID).First(&job).Error; err != nil {
+		t.Fatalf("load job: %v", err)
+	}
+	if job.Metadata["clone_history_from"] != src.ID.String() {
+		t.Errorf("job metadata = %v, want clone_history_from", job.Metadata)
+	}
+	if toml, _ := job.Metadata["pixi_toml"].(string); toml != "[workspace]\nname = \"shared\"\n# v3\n" {
+		t.Errorf("clone should start from the newest version, got pixi_toml %q", toml)
+	}
+	if job.Metadata["initial_tag"] != "v3" {
+		t.Errorf("clone should carry the source version's tag, got initial_tag %v", job.Metadata["initial_tag"])
+	}
+
+	var audits int64
+	db.Model(&models.AuditLog{}).Where("user_id = ? AND action = ?", bob, "clone_workspace").Count(&audits)
+	if audits != 1 {
+		t.Errorf("expected 1 clone audit entry, got %d", audits)
+	}
+
+	// A push to the source between the request and the worker's copy is
+	// not part of the clone.
+	if _, err := svc.PushVersion(ctx, src.ID.String(), PushRequest{Tag: "v4", PixiToml: "[workspace]\nname = \"shared\"\n# v4\n", PixiLock: "version: 6\n"}, alice); err != nil {
+		t.Fatalf("push v4: %v", err)
+	}
+
+	// Stand in for the worker.
+	upTo, err := strconv.Atoi(job.Metadata["clone_history_up_to"].(string))
+	if err != nil {
+		t.Fatalf("clone_history_up_to = %v: %v", job.Metadata["clone_history_up_to"], err)
+	}
+	if err := svc.CopyVersionHistory(fork.ID, src.ID.String(), upTo, bob); err != nil {
+		t.Fatalf("CopyVersionHistory: %v", err)
+	}
+	var versions []models.WorkspaceVersion
+	db.Where("workspace_id = ?", fork.ID).Order("version_number").Find(&versions)
+	if len(versions) != 2 || versions[0].VersionNumber != 1 || versions[1].VersionNumber != 2 {
+		t.Fatalf("expected versions 1 and 2 copied, got %d", len(versions))
+	}
+	if versions[1].ManifestContent != "[workspace]\nname = \"shared\"\n# v2\n" {
+		t.Errorf("unexpected copied content %q", versions[1].ManifestContent)
+	}
+	for tag, want := range map[string]int{"v1": 1, "v2": 2} {
+		var wt models.WorkspaceTag
+		if err := db.Where("workspace_id = ? AND tag = ?", fork.ID, tag).First(&wt).Error; err != nil {
+			t.Errorf("expected tag %q: %v", tag, err)
+		} else if wt.VersionNumber != want {
+			t.Errorf("tag %q points at %d, want %d", tag, wt.VersionNumber, want)
+		}
+	}
+	for _, tag := range []string{"v3", "v4", "latest"} {
+		var n int64
+		db.Model(&models.WorkspaceTag{}).Where("workspace_id = ? AND tag = ?", fork.ID, tag).Count(&n)
+		if n != 0 {
+			t.Errorf("tag %q should be left to the initial snapshot", tag)
+		}
+	}
+
+	// The source is untouched.
+	var srcVersions int64
+	db.Model(&models.WorkspaceVersion{}).Where("workspace_id = ?", src.ID).Count(&srcVersions)
+	if srcVersions != 4 {
+		t.Errorf("source has %d versions, want 4", srcVersions)
+	}
+}
+
+func TestClone_InitialTag(t *testing.T) {
+	svc, db := testSetup(t, true)
+	alice := createTestUser(t, db, "alice")
+	ctx := context.Background()
+
+	cloneTag := func(srcID uuid.UUID, name string) interface{} {
+		t.Helper()
+		fork, err := svc.Clone(ctx, srcID.String(), CloneRequest{Name: name}, alice)
+		if err != nil {
+			t.Fatalf("Clone: %v", err)
+		}
+		var job models.Job
+		if err := db.Where("workspace_id = ?", fork.ID).First(&job).Error; err != nil {
+			t.Fatalf("load job: %v", err)
+		}
+		return job.Metadata["initial_tag"]
+	}
+
+	// A version without a lock file or user tag still gets the default tag.
+	bare := createReadyWorkspace(t, svc, db, "bare", alice)
+	if _, err := svc.PushVersion(ctx, bare.ID.String(), PushRequest{PixiToml: "[workspace]\nname = \"bare\"\n"}, alice); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if got := cloneTag(bare.ID, "bare-fork"); got != DefaultInitialTag {
+		t.Errorf("initial_tag = %v, want %q", got, DefaultInitialTag)
+	}
+
+	// The source's auto-latest tag is not mistaken for a user tag.
+	src := createReadyWorkspace(t, svc, db, "rolling", alice)
+	if _, err := svc.SetAutoLatestTag(src.ID.String(), "edge", alice); err != nil {
+		t.Fatalf("SetAutoLatestTag: %v", err)
+	}
+	if _, err := svc.PushVersion(ctx, src.ID.String(), PushRequest{Tag: "v1", PixiToml: "[workspace]\nname = \"rolling\"\n", PixiLock: "version: 6\n"}, alice); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	// Make the auto-latest tag the most recently moved one.
+	if err := db.Model(&models.WorkspaceTag{}).Where("workspace_id = ? AND tag = ?", src.ID, "edge").
+		Update("updated_at", time.Now().Add(time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := cloneTag(src.ID, "rolling-fork"); got != "v1" {
+		t.Errorf("initial_tag = %v, want v1", got)
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/models"
+	"github.com/nebari-dev/nebi/internal/wsevents"
+)
+
+// SetEventBroker sets the broker that workspace lifecycle events are
+// published to. When unset, events are silently dropped.
+func (s *WorkspaceService) SetEventBroker(b *wsevents.Broker) {
+	s.events = b
+}
+
+// SubscribeEvents registers a subscriber for workspace events. The returned
+// cancel function must be called to release the subscription. It returns a
+// nil channel when no broker is configured.
+func (s *WorkspaceService) SubscribeEvents() (<-chan wsevents.Event, func()) {
+	if s.events == nil {
+		return nil, func() {}
+	}
+	ch := s.events.Subscribe()
+	return ch, func() { s.events.Unsubscribe(ch) }
+}
+
+// CanSeeWorkspace reports whether the user may receive events for the
+// workspace. Local mode has no ownership filtering.
+func (s *WorkspaceService) CanSeeWorkspace(userID, wsID uuid.UUID) bool {
+	if s.isLocal {
+		return true
+	}
+	ok, err := s.rbac.CanReadWorkspace(userID, wsID)
+	return err == nil && ok
+}
+
+func (s *WorkspaceService) publishEvent(eventType string, wsID uuid.UUID, status models.WorkspaceStatus) {
+	if s.events == nil {
+		return
+	}
+	s.events.Publish(wsevents.Event{Type: eventType, WorkspaceID: wsID, Status: string(status)})
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/models"
+	"github.com/nebari-dev/nebi/internal/wsevents"
+)
+
+func TestWorkspaceEvents_LifecycleIsPublished(t *testing.T) {
+	svc, db := testSetup(t, true)
+	svc.SetEventBroker(wsevents.NewBroker())
+	userID := createTestUser(t, db, "alice")
+
+	events, cancel := svc.SubscribeEvents()
+	defer cancel()
+
+	ws, err := svc.Create(context.Background(), CreateRequest{Name: "evt-ws"}, userID)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := svc.SetWorkspaceStatus(ws.ID, models.WsStatusReady); err != nil {
+		t.Fatalf("set status: %v", err)
+	}
+	if err := svc.SoftDeleteWorkspace(ws.ID); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+
+	want := []wsevents.Event{
+		{Type: wsevents.TypeCreated, WorkspaceID: ws.ID, Status: string(models.WsStatusPending)},
+		{Type: wsevents.TypeStatus, WorkspaceID: ws.ID, Status: string(models.WsStatusReady)},
+		{Type: wsevents.TypeDeleted, WorkspaceID: ws.ID},
+	}
+	for i, w := range want {
+		select {
+		case got := <-events:
+			if got != w {
+				t.Errorf("event %d = %+v, want %+v", i, got, w)
+			}
+		default:
+			t.Fatalf("event %d missing, want %+v", i, w)
+		}
+	}
+}
+
+func TestWorkspaceEvents_NoBroker(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+
+	events, cancel := svc.SubscribeEvents()
+	defer cancel()
+	if events != nil {
+		t.Fatal("expected nil channel without a broker")
+	}
+
+	// Publishing without a broker must not panic.
+	if _, err := svc.Create(context.Background(), CreateRequest{Name: "no-broker"}, userID); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+}
+
+func TestCanSeeWorkspace_TeamMode(t *testing.T) {
+	svc, db := testSetup(t, false)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	ws := createReadyWorkspace(t, svc, db, "alice-ws", alice)
+
+	if !svc.CanSeeWorkspace(alice, ws.ID) {
+		t.Error("owner should see their workspace")
+	}
+	if svc.CanSeeWorkspace(bob, ws.ID) {
+		t.Error("unrelated user should not see the workspace")
+	}
+}
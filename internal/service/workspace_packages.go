@@ -9,6 +9,7 @@ import (
 	"github.com/nebari-dev/nebi/internal/audit"
 	"github.com/nebari-dev/nebi/internal/models"
 	"github.com/nebari-dev/nebi/internal/pkgmgr"
+	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
 	"gorm.io/gorm"
 )
 
@@ -56,11 +57,30 @@ func (s *WorkspaceService) InstallPackages(ctx context.Context, wsID string, pac
 	return s.submitJob(ctx, wsID, userID, models.JobTypeInstall, metadata, audit.ActionInstallPackage)
 }
 
-// SolveWorkspace creates and enqueues a solve job (pixi install from current pixi.toml).
-func (s *WorkspaceService) SolveWorkspace(ctx context.Context, wsID string, userID uuid.UUID) (*models.Job, error) {
+// SolveWorkspace creates and enqueues a solve job (pixi lock from current
+// pixi.toml). platforms overrides the workspace's default platforms for this
+// solve; when both are empty the platforms declared in pixi.toml are used.
+func (s *WorkspaceService) SolveWorkspace(ctx context.Context, wsID string, platforms []string, userID uuid.UUID) (*models.Job, error) {
+	var ws models.Workspace
+	if err := s.db.Where("id = ?", wsID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if len(platforms) == 0 {
+		platforms = ws.DefaultPlatforms
+	} else if err := pixi.ValidatePlatforms(platforms); err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+
 	metadata := map[string]interface{}{
 		"user_id": userID.String(),
 	}
+	if len(platforms) > 0 {
+		metadata["platforms"] = platforms
+	}
 	return s.submitJob(ctx, wsID, userID, models.JobTypeUpdate, metadata, audit.ActionSolveWorkspace)
 }
 
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/models"
+	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
+	"gorm.io/gorm"
+)
+
+// PlatformsResult holds the platforms a solve targets when the request does
+// not override them.
+type PlatformsResult struct {
+	Platforms []string `json:"platforms"`
+	Source    string   `json:"source"` // "workspace" when set explicitly, "manifest" when read from pixi.toml
+}
+
+// GetDefaultPlatforms returns the workspace's explicit default platforms,
+// or the platforms declared in its pixi.toml when none are set.
+func (s *WorkspaceService) GetDefaultPlatforms(wsID string) (*PlatformsResult, error) {
+	var ws models.Workspace
+	if err := s.db.Where("id = ?", wsID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if len(ws.DefaultPlatforms) > 0 {
+		return &PlatformsResult{Platforms: ws.DefaultPlatforms, Source: "workspace"}, nil
+	}
+
+	content, err := s.GetPixiToml(wsID)
+	if err != nil {
+		return nil, err
+	}
+	platforms, err := pixi.ExtractPlatforms(content)
+	if err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+	if platforms == nil {
+		platforms = []string{}
+	}
+	return &PlatformsResult{Platforms: platforms, Source: "manifest"}, nil
+}
+
+// SetDefaultPlatforms sets the platforms solves target when no override is
+// given. An empty list clears the setting, so solves go back to the
+// platforms declared in pixi.toml.
+func (s *WorkspaceService) SetDefaultPlatforms(wsID string, platforms []string, userID uuid.UUID) (*PlatformsResult, error) {
+	if err := pixi.ValidatePlatforms(platforms); err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+
+	var ws models.Workspace
+	if err := s.db.Where("id = ?", wsID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if len(platforms) == 0 {
+		platforms = nil
+	}
+	if err := s.db.Model(&ws).Select("DefaultPlatforms").Updates(&models.Workspace{DefaultPlatforms: platforms}).Error; err != nil {
+		return nil, fmt.Errorf("update default platforms: %w", err)
+	}
+
+	audit.LogAction(s.db, userID, audit.ActionSetPlatforms, fmt.Sprintf("ws:%s", ws.ID.String()), map[string]interface{}{
+		"platforms": platforms,
+	})
+
+	return s.GetDefaultPlatforms(wsID)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/models"
+)
+
+func TestDefaultPlatforms_FallsBackToManifest(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "platforms", userID)
+
+	os.MkdirAll(svc.executor.GetWorkspacePath(ws), 0755)
+	content := "[workspace]\nname = \"platforms\"\nplatforms = [\"linux-64\", \"osx-arm64\"]\n"
+	if err := svc.SavePixiToml(ws.ID.String(), content); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	got, err := svc.GetDefaultPlatforms(ws.ID.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Source != "manifest" || !slices.Equal(got.Platforms, []string{"linux-64", "osx-arm64"}) {
+		t.Errorf("expected manifest platforms, got %+v", got)
+	}
+
+	got, err = svc.SetDefaultPlatforms(ws.ID.String(), []string{"linux-64"}, userID)
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if got.Source != "workspace" || !slices.Equal(got.Platforms, []string{"linux-64"}) {
+		t.Errorf("expected explicit platforms, got %+v", got)
+	}
+
+	// Clearing goes back to the manifest.
+	got, err = svc.SetDefaultPlatforms(ws.ID.String(), nil, userID)
+	if err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if got.Source != "manifest" {
+		t.Errorf("expected manifest source after clearing, got %+v", got)
+	}
+}
+
+func TestSetDefaultPlatforms_RejectsUnknownPlatform(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "platforms", userID)
+
+	_, err := svc.SetDefaultPlatforms(ws.ID.String(), []string{"linux-x86_64"}, userID)
+	var ve *ValidationError
+	if !isValidationError(err, &ve) {
+		t.Fatalf("expected ValidationError, got %T: %v", err, err)
+	}
+}
+
+func TestSolveWorkspace_Platforms(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "solve", userID)
+
+	// No override and no defaults: the manifest decides.
+	job, err := svc.SolveWorkspace(context.Background(), ws.ID.String(), nil, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Type != models.JobTypeUpdate {
+		t.Errorf("expected job type %q, got %q", models.JobTypeUpdate, job.Type)
+	}
+	if _, ok := job.Metadata["platforms"]; ok {
+		t.Errorf("expected no platforms in metadata, got %v", job.Metadata["platforms"])
+	}
+
+	if _, err := svc.SetDefaultPlatforms(ws.ID.String(), []string{"linux-64", "win-64"}, userID); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	job, err = svc.SolveWorkspace(context.Background(), ws.ID.String(), nil, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := job.Metadata["platforms"].([]string); !slices.Equal(got, []string{"linux-64", "win-64"}) {
+		t.Errorf("expected default platforms in metadata, got %v", job.Metadata["platforms"])
+	}
+
+	job, err = svc.SolveWorkspace(context.Background(), ws.ID.String(), []string{"osx-arm64"}, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := job.Metadata["platforms"].([]string); !slices.Equal(got, []string{"osx-arm64"}) {
+		t.Errorf("expected override in metadata, got %v", job.Metadata["platforms"])
+	}
+
+	_, err = svc.SolveWorkspace(context.Background(), ws.ID.String(), []string{"macos"}, userID)
+	var ve *ValidationError
+	if !isValidationError(err, &ve) {
+		t.Fatalf("expected ValidationError for unknown platform, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/models"
+	"github.com/nebari-dev/nebi/internal/utils"
+	"gorm.io/gorm"
+)
+
+// WorkspaceStats holds aggregate usage metrics for a workspace.
+type WorkspaceStats struct {
+	WorkspaceID   string     `json:"workspace_id"`
+	Name          string     `json:"name"`
+	VersionCount  int64      `json:"version_count"`
+	TagCount      int64      `json:"tag_count"`
+	PushCount     int64      `json:"push_count"`
+	PullCount     int64      `json:"pull_count"`
+	LastActivity  *time.Time `json:"last_activity,omitempty"`
+	SizeBytes     int64      `json:"size_bytes"`
+	SizeFormatted string     `json:"size_formatted,omitempty"`
+}
+
+// GetStats aggregates version, tag, and audit counts for a workspace. Each
+// metric is a single COUNT or grouped query; no history rows are loaded.
+func (s *WorkspaceService) GetStats(wsID string) (*WorkspaceStats, error) {
+	var ws models.Workspace
+	if err := s.db.Where("id = ?", wsID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	stats := &WorkspaceStats{
+		WorkspaceID: ws.ID.String(),
+		Name:        ws.Name,
+		SizeBytes:   ws.SizeBytes,
+	}
+	if ws.SizeBytes > 0 {
+		stats.SizeFormatted = utils.FormatBytes(ws.SizeBytes)
+	}
+
+	if err := s.db.Model(&models.WorkspaceVersion{}).Where("workspace_id = ?", ws.ID).Count(&stats.VersionCount).Error; err != nil {
+		return nil, fmt.Errorf("count versions: %w", err)
+	}
+	if err := s.db.Model(&models.WorkspaceTag{}).Where("workspace_id = ?", ws.ID).Count(&stats.TagCount).Error; err != nil {
+		return nil, fmt.Errorf("count tags: %w", err)
+	}
+
+	var actionCounts []struct {
+		Action string
+		Count  int64
+	}
+	if err := s.workspaceAuditQuery(ws.ID.String()).
+		Where("action IN ?", []string{audit.ActionPush, audit.ActionPull}).
+		Select("action, COUNT(*) AS count").
+		Group("action").
+		Scan(&actionCounts).Error; err != nil {
+		return nil, fmt.Errorf("count audit actions: %w", err)
+	}
+	for _, ac := range actionCounts {
+		switch ac.Action {
+		case audit.ActionPush:
+			stats.PushCount = ac.Count
+		case audit.ActionPull:
+			stats.PullCount = ac.Count
+		}
+	}
+
+	// Last activity is the most recent of: workspace update, newest version,
+	// newest audit entry for this workspace.
+	last := ws.UpdatedAt
+	var latestVersion models.WorkspaceVersion
+	if err := s.db.Select("created_at").Where("workspace_id = ?", ws.ID).
+		Order("created_at DESC").Limit(1).Find(&latestVersion).Error; err == nil && latestVersion.CreatedAt.After(last) {
+		last = latestVersion.CreatedAt
+	}
+	var latestAudit models.AuditLog
+	if err := s.workspaceAuditQuery(ws.ID.String()).Select("timestamp").
+		Order("timestamp DESC").Limit(1).Find(&latestAudit).Error; err == nil && latestAudit.Timestamp.After(last) {
+		last = latestAudit.Timestamp
+	}
+	if !last.IsZero() {
+		stats.LastActivity = &last
+	}
+
+	return stats, nil
+}
+
+// WorkspaceImpact counts what deleting a workspace would remove.
+type WorkspaceImpact struct {
+	WorkspaceID       string `json:"workspace_id"`
+	Name              string `json:"name"`
+	VersionCount      int64  `json:"version_count"`
+	TagCount          int64  `json:"tag_count"`
+	PublicationCount  int64  `json:"publication_count"`
+	CollaboratorCount int64  `json:"collaborator_count"` // users and groups with shared access, excluding the owner
+}
+
+// GetDeletionImpact counts the versions, tags, publications, and
+// collaborators that are removed along with a workspace. Like GetStats it
+// only issues COUNT queries.
+func (s *WorkspaceService) GetDeletionImpact(wsID string) (*WorkspaceImpact, error) {
+	var ws models.Workspace
+	if err := s.db.Where("id = ?", wsID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	impact := &WorkspaceImpact{
+		WorkspaceID: ws.ID.String(),
+		Name:        ws.Name,
+	}
+
+	if err := s.db.Model(&models.WorkspaceVersion{}).Where("workspace_id = ?", ws.ID).Count(&impact.VersionCount).Error; err != nil {
+		return nil, fmt.Errorf("count versions: %w", err)
+	}
+	if err := s.db.Model(&models.WorkspaceTag{}).Where("workspace_id = ?", ws.ID).Count(&impact.TagCount).Error; err != nil {
+		return nil, fmt.Errorf("count tags: %w", err)
+	}
+	if err := s.db.Model(&models.Publication{}).Where("workspace_id = ?", ws.ID).Count(&impact.PublicationCount).Error; err != nil {
+		return nil, fmt.Errorf("count publications: %w", err)
+	}
+
+	var users, groups int64
+	if err := s.db.Model(&models.Permission{}).Where("workspace_id = ? AND user_id <> ?", ws.ID, ws.OwnerID).Count(&users).Error; err != nil {
+		return nil, fmt.Errorf("count collaborators: %w", err)
+	}
+	if err := s.db.Model(&models.GroupPermission{}).Where("workspace_id = ?", ws.ID).Count(&groups).Error; err != nil {
+		return nil, fmt.Errorf("count group collaborators: %w", err)
+	}
+	impact.CollaboratorCount = users + groups
+
+	return impact, nil
+}
+
+// workspaceAuditQuery scopes audit_logs to entries about one workspace. Older
+// entries use a "ws:<id>" resource; audit.Log entries use the "workspace"
+// resource type with the ID recorded in the details.
+func (s *WorkspaceService) workspaceAuditQuery(wsID string) *gorm.DB {
+	return s.db.Model(&models.AuditLog{}).Where(
+		"resource = ? OR (resource = ? AND details_json LIKE ?)",
+		"ws:"+wsID, audit.ResourceWorkspace, fmt.Sprintf(`%%"resource_id":"%s"%%`, wsID),
+	)
+}
+
+// RecordPull writes a pull audit entry for a workspace version download.
+func (s *WorkspaceService) RecordPull(wsID string, versionNumber string, userID uuid.UUID) error {
+	var ws models.Workspace
+	if err := s.db.Select("id").Where("id = ?", wsID).First(&ws).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return audit.Log(s.db, userID, audit.ActionPull, audit.ResourceWorkspace, ws.ID, map[string]interface{}{
+		"version": versionNumber,
+	})
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/contenthash"
+	"github.com/nebari-dev/nebi/internal/models"
+)
+
+// Policies applied when a push would exceed workspaces.max_tags.
+const (
+	TagLimitReject      = "reject"       // refuse the push with a conflict
+	TagLimitEvictOldest = "evict_oldest" // delete the oldest user tags to make room
+)
+
+// TagLimit describes a workspace's user tags against the configured limit.
+type TagLimit struct {
+	Count  int64  `json:"count"`
+	Max    int    `json:"max"` // 0 means unlimited
+	Policy string `json:"policy"`
+}
+
+// SetMaxTags caps the number of user tags per workspace
+// (workspaces.max_tags). Content-hash tags, "latest" and the workspace's
+// auto-latest tag are maintained by the server and do not count.
+// Non-positive values remove the cap.
+func (s *WorkspaceService) SetMaxTags(n int) {
+	if n < 0 {
+		n = 0
+	}
+	s.maxTags = n
+}
+
+// SetTagLimitPolicy sets what happens when a push would exceed the tag
+// limit (workspaces.tag_limit_policy): TagLimitReject or
+// TagLimitEvictOldest. Unknown values restore the default, TagLimitReject.
+func (s *WorkspaceService) SetTagLimitPolicy(policy string) {
+	if policy != TagLimitEvictOldest {
+		policy = TagLimitReject
+	}
+	s.tagLimitPolicy = policy
+}
+
+// isUserTag reports whether tag counts against workspaces.max_tags in ws.
+func isUserTag(ws *models.Workspace, tag string) bool {
+	return tag != "latest" && tag != ws.AutoLatestTag && !contenthash.IsHashTag(tag)
+}
+
+// userTags returns the workspace's user tags, oldest first.
+func (s *WorkspaceService) userTags(ws *models.Workspace) ([]models.WorkspaceTag, error) {
+	var tags []models.WorkspaceTag
+	if err := s.db.Where("workspace_id = ?", ws.ID).Order("created_at ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	user := tags[:0]
+	for _, t := range tags {
+		if isUserTag(ws, t.Tag) {
+			user = append(user, t)
+		}
+	}
+	return user, nil
+}
+
+// tagLimit returns the workspace's user tag count and the configured limit.
+func (s *WorkspaceService) tagLimit(ws *models.Workspace) (*TagLimit, error) {
+	tags, err := s.userTags(ws)
+	if err != nil {
+		return nil, err
+	}
+	return &TagLimit{Count: int64(len(tags)), Max: s.maxTags, Policy: s.tagLimitPolicy}, nil
+}
+
+// checkTagLimit is called before a new user tag is added to ws. It returns
+// the tags to evict to stay within the limit, or a ConflictError when the
+// limit is reached and the policy is TagLimitReject.
+func (s *WorkspaceService) checkTagLimit(ws *models.Workspace) ([]models.WorkspaceTag, error) {
+	if s.maxTags <= 0 {
+		return nil, nil
+	}
+	tags, err := s.userTags(ws)
+	if err != nil {
+		return nil, err
+	}
+	excess := len(tags) - s.maxTags + 1
+	if excess <= 0 {
+		return nil, nil
+	}
+	if s.tagLimitPolicy != TagLimitEvictOldest {
+		return nil, &ConflictError{Message: fmt.Sprintf(
+			"workspace %q has reached the limit of %d tags; reuse an existing tag with --force, push without a tag, or ask an administrator to raise workspaces.max_tags",
+			ws.Name, s.maxTags)}
+	}
+	return tags[:excess], nil
+}
+
+// checkHistoryTagLimit is the history clone counterpart of checkTagLimit.
+// tags are the source tags the clone copies, oldest first; the clone's own
+// initial tag takes one more slot. It returns the user tags to leave out to
+// stay within the limit, or a ConflictError when they do not fit and the
+// policy is TagLimitReject.
+func (s *WorkspaceService) checkHistoryTagLimit(src *models.Workspace, tags []models.WorkspaceTag) ([]models.WorkspaceTag, error) {
+	if s.maxTags <= 0 {
+		return nil, nil
+	}
+	var user []models.WorkspaceTag
+	for _, t := range tags {
+		if isUserTag(src, t.Tag) {
+			user = append(user, t)
+		}
+	}
+	excess := len(user) + 1 - s.maxTags
+	if excess <= 0 {
+		return nil, nil
+	}
+	if s.tagLimitPolicy != TagLimitEvictOldest {
+		return nil, &ConflictError{Message: fmt.Sprintf(
+			"cloning %q with history would copy %d tags, over the limit of %d with the clone's own tag; clone without history, or ask an administrator to raise workspaces.max_tags",
+			src.Name, len(user), s.maxTags)}
+	}
+	if excess > len(user) {
+		excess = len(user)
+	}
+	return user[:excess], nil
+}
+
+// evictTags deletes tags removed to make room under the tag limit and
+// records each eviction in the audit log.
+func (s *WorkspaceService) evictTags(ws *models.Workspace, tags []models.WorkspaceTag, userID uuid.UUID) ([]string, error) {
+	var evicted []string
+	for _, t := range tags {
+		if err := s.db.Delete(&t).Error; err != nil {
+			return evicted, fmt.Errorf("evict tag %q: %w", t.Tag, err)
+		}
+		audit.Log(s.db, userID, audit.ActionEvictTag, audit.ResourceWorkspace, ws.ID, map[string]interface{}{
+			"tag":      t.Tag,
+			"version":  t.VersionNumber,
+			"max_tags": s.maxTags,
+		})
+		evicted = append(evicted, t.Tag)
+	}
+	return evicted, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/models"
+)
+
+func TestPushVersion_TagLimitReject(t *testing.T) {
+	svc, db := testSetup(t, true)
+	svc.SetMaxTags(2)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "limited", userID)
+
+	ctx := context.Background()
+	for i := 1; i <= 2; i++ {
+		toml := fmt.Sprintf("[workspace]\nname = \"limited\"\n# v%d\n", i)
+		if _, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: fmt.Sprintf("v%d", i), PixiToml: toml}, userID); err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+	}
+
+	_, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: "v3", PixiToml: "[workspace]\nname = \"limited\"\n# v3\n"}, userID)
+	var ce *ConflictError
+	if !isConflictError(err, &ce) {
+		t.Fatalf("expected ConflictError at the limit, got %v", err)
+	}
+
+	// Reassigning an existing tag and untagged pushes are still allowed.
+	if _, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: "v2", PixiToml: "[workspace]\nname = \"limited\"\n# v3\n", Force: true}, userID); err != nil {
+		t.Errorf("force-reassigning an existing tag should not hit the limit: %v", err)
+	}
+	if _, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{PixiToml: "[workspace]\nname = \"limited\"\n# v4\n"}, userID); err != nil {
+		t.Errorf("untagged push should not hit the limit: %v", err)
+	}
+
+	got, err := svc.Get(ws.ID.String())
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.TagLimit == nil || got.TagLimit.Count != 2 || got.TagLimit.Max != 2 || got.TagLimit.Policy != TagLimitReject {
+		t.Errorf("tag limit = %+v, want 2 of 2 (reject)", got.TagLimit)
+	}
+}
+
+func TestPushVersion_TagLimitEvictOldest(t *testing.T) {
+	svc, db := testSetup(t, true)
+	svc.SetMaxTags(2)
+	svc.SetTagLimitPolicy(TagLimitEvictOldest)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "evicting", userID)
+
+	ctx := context.Background()
+	var last *PushResult
+	for i := 1; i <= 3; i++ {
+		toml := fmt.Sprintf("[workspace]\nname = \"evicting\"\n# v%d\n", i)
+		r, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: fmt.Sprintf("v%d", i), PixiToml: toml}, userID)
+		if err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+		last = r
+	}
+
+	if len(last.EvictedTags) != 1 || last.EvictedTags[0] != "v1" {
+		t.Errorf("evicted = %v, want [v1]", last.EvictedTags)
+	}
+	var tags []string
+	db.Model(&models.WorkspaceTag{}).Where("workspace_id = ?", ws.ID).Pluck("tag", &tags)
+	for _, tag := range tags {
+		if tag == "v1" {
+			t.Error("v1 should have been evicted")
+		}
+	}
+	// Content tags and "latest" are kept: 3 hashes + latest + v2 + v3.
+	if len(tags) != 6 {
+		t.Errorf("tags = %v, want 6", tags)
+	}
+
+	var logs int64
+	db.Model(&models.AuditLog{}).Where("action = ?", audit.ActionEvictTag).Count(&logs)
+	if logs != 1 {
+		t.Errorf("expected 1 evict_tag audit entry, got %d", logs)
+	}
+}
+
+func TestClone_HistoryTagLimit(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+	src := createReadyWorkspace(t, svc, db, "tagged", userID)
+
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		toml := fmt.Sprintf("[workspace]\nname = \"tagged\"\n# v%d\n", i)
+		if _, err := svc.PushVersion(ctx, src.ID.String(), PushRequest{Tag: fmt.Sprintf("v%d", i), PixiToml: toml}, userID); err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+	}
+
+	// v1 and v2 are copied and v3 becomes the clone's initial tag: 3 tags.
+	svc.SetMaxTags(2)
+	_, err := svc.Clone(ctx, src.ID.String(), CloneRequest{Name: "rejected", History: true}, userID)
+	var ce *ConflictError
+	if !isConflictError(err, &ce) {
+		t.Fatalf("expected ConflictError for a history clone over the limit, got %v", err)
+	}
+	if _, err := svc.Clone(ctx, src.ID.String(), CloneRequest{Name: "no-history"}, userID); err != nil {
+		t.Errorf("clone without history should not hit the limit: %v", err)
+	}
+
+	// Under evict_oldest the clone is accepted; the worker drops the extra
+	// tags (see TestExecuteJob_HistoryCloneAppliesTagLimit).
+	svc.SetTagLimitPolicy(TagLimitEvictOldest)
+	if _, err := svc.Clone(ctx, src.ID.String(), CloneRequest{Name: "evicting", History: true}, userID); err != nil {
+		t.Errorf("history clone should be accepted under evict_oldest: %v", err)
+	}
+}
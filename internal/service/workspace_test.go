@@ -2,18 +2,23 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/glebarez/sqlite"
 	"github.com/google/uuid"
+	"github.com/nebari-dev/nebi/internal/audit"
 	"github.com/nebari-dev/nebi/internal/config"
 	"github.com/nebari-dev/nebi/internal/executor"
 	"github.com/nebari-dev/nebi/internal/models"
 	"github.com/nebari-dev/nebi/internal/queue"
 	"github.com/nebari-dev/nebi/internal/rbac"
+	"github.com/nebari-dev/nebi/internal/versioncache"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -262,6 +267,64 @@ func TestDelete_NotFound(t *testing.T) {
 	}
 }
 
+func TestCreate_InitialContent(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+
+	toml := "[workspace]\nname = \"seeded\"\n"
+	lock := "version: 6\n"
+
+	_, err := svc.Create(context.Background(), CreateRequest{PixiLock: lock}, userID)
+	var ve *ValidationError
+	if !isValidationError(err, &ve) {
+		t.Fatalf("expected ValidationError for pixi_lock without pixi_toml, got %v", err)
+	}
+	_, err = svc.Create(context.Background(), CreateRequest{PixiToml: toml, PixiLock: "version: [6"}, userID)
+	if !isValidationError(err, &ve) {
+		t.Fatalf("expected ValidationError for malformed pixi_lock, got %v", err)
+	}
+	_, err = svc.Create(context.Background(), CreateRequest{PixiToml: toml, PixiLock: lock, InitialTag: "latest"}, userID)
+	if !isValidationError(err, &ve) {
+		t.Fatalf("expected ValidationError for reserved initial_tag, got %v", err)
+	}
+	var wsCount int64
+	db.Model(&models.Workspace{}).Count(&wsCount)
+	if wsCount != 0 {
+		t.Fatalf("rejected requests must not create workspaces, got %d", wsCount)
+	}
+
+	svc.SetInitialTag("v1.0")
+	ws, err := svc.Create(context.Background(), CreateRequest{PixiToml: toml, PixiLock: lock}, userID)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var job models.Job
+	if err := db.Where("workspace_id = ?", ws.ID).First(&job).Error; err != nil {
+		t.Fatalf("load job: %v", err)
+	}
+	if job.Metadata["pixi_lock"] != lock || job.Metadata["initial_tag"] != "v1.0" {
+		t.Fatalf("unexpected job metadata: %v", job.Metadata)
+	}
+
+	// Stand in for the worker: snapshot, then tag.
+	if err := db.Create(&models.WorkspaceVersion{
+		WorkspaceID: ws.ID, ManifestContent: toml, LockFileContent: lock, PackageMetadata: "[]", CreatedBy: userID,
+	}).Error; err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	if err := svc.TagInitialVersion(ws.ID, "v1.0", userID); err != nil {
+		t.Fatalf("TagInitialVersion: %v", err)
+	}
+	for _, tag := range []string{"v1.0", "latest", contentHash(toml, lock)} {
+		var wt models.WorkspaceTag
+		if err := db.Where("workspace_id = ? AND tag = ?", ws.ID, tag).First(&wt).Error; err != nil {
+			t.Errorf("expected tag %q: %v", tag, err)
+		} else if wt.VersionNumber != 1 {
+			t.Errorf("tag %q points at version %d, want 1", tag, wt.VersionNumber)
+		}
+	}
+}
+
 // --- PushVersion tag conflict tests ---
 
 func TestPushVersion_TagConflictWithoutForce(t *testing.T) {
@@ -477,12 +540,145 @@ func TestListVersions_Empty(t *testing.T) {
 	userID := createTestUser(t, db, "alice")
 	ws := createReadyWorkspace(t, svc, db, "no-versions", userID)
 
-	versions, err := svc.ListVersions(ws.ID.String())
+	page, err := svc.ListVersions(ws.ID.String(), ListVersionsOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(versions) != 0 {
-		t.Errorf("expected 0 versions, got %d", len(versions))
+	if len(page.Versions) != 0 {
+		t.Errorf("expected 0 versions, got %d", len(page.Versions))
+	}
+	if page.NextBefore != 0 {
+		t.Errorf("expected no cursor, got %d", page.NextBefore)
+	}
+}
+
+func TestListVersions_Paging(t *testing.T) {
+	svc, db := testSetup(t, true)
+	svc.SetMaxVersionsListed(2)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "paged", userID)
+
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		toml := fmt.Sprintf("[workspace]\nname = \"paged\"\n# v%d\n", i)
+		if _, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: fmt.Sprintf("v%d", i), PixiToml: toml}, userID); err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+	}
+
+	var got []int
+	before := 0
+	for {
+		// Asking for more than the cap still returns at most 2 per page.
+		page, err := svc.ListVersions(ws.ID.String(), ListVersionsOptions{Limit: 10, Before: before})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Versions) > 2 {
+			t.Fatalf("page exceeded cap: %d versions", len(page.Versions))
+		}
+		for _, v := range page.Versions {
+			got = append(got, v.VersionNumber)
+		}
+		if page.NextBefore == 0 {
+			break
+		}
+		before = page.NextBefore
+	}
+
+	want := []int{5, 4, 3, 2, 1}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got versions %v, want %v", got, want)
+	}
+
+	if _, err := svc.ListVersions(ws.ID.String(), ListVersionsOptions{Limit: -1}); err == nil {
+		t.Error("expected validation error for negative limit")
+	}
+}
+
+func TestVersionContentCompression(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "compressed", userID)
+
+	models.SetVersionContentCompression(true)
+	t.Cleanup(func() { models.SetVersionContentCompression(false) })
+
+	toml := "[workspace]\nname = \"compressed\"\n"
+	lock := strings.Repeat("version: 6\n", 50)
+	r, err := svc.PushVersion(context.Background(), ws.ID.String(), PushRequest{Tag: "v1", PixiToml: toml, PixiLock: lock}, userID)
+	if err != nil {
+		t.Fatalf("push: %v", err)
+	}
+
+	var raw struct {
+		LockFileContent string
+		ContentEncoding string
+	}
+	db.Raw("SELECT lock_file_content, content_encoding FROM workspace_versions WHERE workspace_id = ?", ws.ID).Scan(&raw)
+	if raw.ContentEncoding != models.ContentEncodingGzip || raw.LockFileContent == lock {
+		t.Fatalf("expected compressed row, got encoding %q", raw.ContentEncoding)
+	}
+
+	versionNum := fmt.Sprint(r.VersionNumber)
+	got, err := svc.GetVersionFile(ws.ID.String(), versionNum, "lock")
+	if err != nil || got != lock {
+		t.Errorf("GetVersionFile(lock) = %q, %v; want original content", got, err)
+	}
+	v, err := svc.GetVersion(ws.ID.String(), versionNum)
+	if err != nil || v.ManifestContent != toml || v.LockFileContent != lock {
+		t.Errorf("GetVersion returned undecoded content: %+v, %v", v, err)
+	}
+
+	// Plain rows written before compression was enabled still read back.
+	models.SetVersionContentCompression(false)
+	r2, err := svc.PushVersion(context.Background(), ws.ID.String(), PushRequest{Tag: "v2", PixiToml: toml + "# v2\n"}, userID)
+	if err != nil {
+		t.Fatalf("push v2: %v", err)
+	}
+	got, err = svc.GetVersionFile(ws.ID.String(), fmt.Sprint(r2.VersionNumber), "manifest")
+	if err != nil || got != toml+"# v2\n" {
+		t.Errorf("GetVersionFile(manifest) = %q, %v", got, err)
+	}
+}
+
+func TestGetVersionFile_Cache(t *testing.T) {
+	svc, db := testSetup(t, true)
+	cache := versioncache.New(1 << 20)
+	svc.SetVersionCache(cache)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "cached", userID)
+
+	toml := "[workspace]\nname = \"cached\"\n"
+	r, err := svc.PushVersion(context.Background(), ws.ID.String(), PushRequest{Tag: "v1", PixiToml: toml}, userID)
+	if err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	versionNum := fmt.Sprint(r.VersionNumber)
+
+	for i := 0; i < 2; i++ {
+		got, err := svc.GetVersionFile(ws.ID.String(), versionNum, "manifest")
+		if err != nil || got != toml {
+			t.Fatalf("GetVersionFile(manifest) = %q, %v", got, err)
+		}
+	}
+	if st := cache.Stats(); st.Hits != 1 || st.Misses != 1 || st.Entries != 1 {
+		t.Errorf("stats after two reads = %+v, want 1 hit, 1 miss", st)
+	}
+
+	// Missing versions are not cached.
+	if _, err := svc.GetVersionFile(ws.ID.String(), "99", "manifest"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if st := cache.Stats(); st.Entries != 1 {
+		t.Errorf("entries = %d, want 1", st.Entries)
+	}
+
+	if err := svc.SoftDeleteWorkspace(ws.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if st := cache.Stats(); st.Entries != 0 {
+		t.Errorf("entries after workspace delete = %d, want 0", st.Entries)
 	}
 }
 
@@ -589,3 +785,89 @@ func TestWorkspaceService_IsLocal(t *testing.T) {
 		t.Error("expected IsLocal()=false when service constructed with isLocal=false")
 	}
 }
+
+func TestCheckCreateAllowed_PushAutoCreateDisabled(t *testing.T) {
+	svc, db := testSetup(t, false)
+	userID := createTestUser(t, db, "alice")
+	adminID := createTestUser(t, db, "root")
+	if err := rbac.MakeAdmin(adminID); err != nil {
+		t.Fatalf("make admin: %v", err)
+	}
+
+	if err := svc.CheckCreateAllowed("auto-ws", userID); err != nil {
+		t.Fatalf("create should be allowed by default, got %v", err)
+	}
+
+	svc.SetAllowPushAutoCreate(false)
+	err := svc.CheckCreateAllowed("auto-ws", userID)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not-found error, got %v", err)
+	}
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || !strings.Contains(nf.Message, "administrator") {
+		t.Errorf("expected guidance in error message, got %v", err)
+	}
+
+	var count int64
+	db.Model(&models.AuditLog{}).Where("action = ?", audit.ActionAutoCreateDenied).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 denial audit entry, got %d", count)
+	}
+
+	// Administrators provision workspaces explicitly.
+	if err := svc.CheckCreateAllowed("provisioned-ws", adminID); err != nil {
+		t.Errorf("admin create should be allowed, got %v", err)
+	}
+}
+
+// --- Stats tests ---
+
+func TestGetStats_CountsVersionsTagsAndAudit(t *testing.T) {
+	svc, db := testSetup(t, true)
+	userID := createTestUser(t, db, "alice")
+	ws := createReadyWorkspace(t, svc, db, "stats-ws", userID)
+	other := createReadyWorkspace(t, svc, db, "other-ws", userID)
+
+	ctx := context.Background()
+	if _, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: "v1", PixiToml: "[workspace]\nname = \"stats-ws\"\n"}, userID); err != nil {
+		t.Fatalf("push v1: %v", err)
+	}
+	if _, err := svc.PushVersion(ctx, ws.ID.String(), PushRequest{Tag: "v2", PixiToml: "[workspace]\nname = \"stats-ws\"\n# v2\n"}, userID); err != nil {
+		t.Fatalf("push v2: %v", err)
+	}
+	// Activity on another workspace must not be counted.
+	if _, err := svc.PushVersion(ctx, other.ID.String(), PushRequest{Tag: "v1", PixiToml: "[workspace]\nname = \"other-ws\"\n"}, userID); err != nil {
+		t.Fatalf("push other: %v", err)
+	}
+	if err := svc.RecordPull(ws.ID.String(), "1", userID); err != nil {
+		t.Fatalf("record pull: %v", err)
+	}
+
+	stats, err := svc.GetStats(ws.ID.String())
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.VersionCount != 2 {
+		t.Errorf("VersionCount = %d, want 2", stats.VersionCount)
+	}
+	// Two content-hash tags, "latest", and two user tags.
+	if stats.TagCount != 5 {
+		t.Errorf("TagCount = %d, want 5", stats.TagCount)
+	}
+	if stats.PushCount != 2 {
+		t.Errorf("PushCount = %d, want 2", stats.PushCount)
+	}
+	if stats.PullCount != 1 {
+		t.Errorf("PullCount = %d, want 1", stats.PullCount)
+	}
+	if stats.LastActivity == nil {
+		t.Error("LastActivity should be set")
+	}
+}
+
+func TestGetStats_NotFound(t *testing.T) {
+	svc, _ := testSetup(t, true)
+	if _, err := svc.GetStats(uuid.New().String()); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/nebari-dev/nebi/internal/models"
 	"github.com/nebari-dev/nebi/internal/pkgmgr"
 	"github.com/nebari-dev/nebi/internal/utils"
+	"github.com/nebari-dev/nebi/internal/wsevents"
 	"gorm.io/gorm"
 )
 
@@ -141,7 +142,11 @@ func (s *WorkspaceService) ResetWorkspaceSize(wsID uuid.UUID) error {
 
 // SetWorkspaceStatus updates the workspace status in the database.
 func (s *WorkspaceService) SetWorkspaceStatus(wsID uuid.UUID, status models.WorkspaceStatus) error {
-	return s.db.Model(&models.Workspace{}).Where("id = ?", wsID).Update("status", status).Error
+	if err := s.db.Model(&models.Workspace{}).Where("id = ?", wsID).Update("status", status).Error; err != nil {
+		return err
+	}
+	s.publishEvent(wsevents.TypeStatus, wsID, status)
+	return nil
 }
 
 // SetWorkspacePath updates the workspace path in the database.
@@ -151,7 +156,12 @@ func (s *WorkspaceService) SetWorkspacePath(wsID uuid.UUID, path string) error {
 
 // SoftDeleteWorkspace soft-deletes a workspace.
 func (s *WorkspaceService) SoftDeleteWorkspace(wsID uuid.UUID) error {
-	return s.db.Delete(&models.Workspace{}, wsID).Error
+	if err := s.db.Delete(&models.Workspace{}, wsID).Error; err != nil {
+		return err
+	}
+	s.versionCache.InvalidateWorkspace(wsID.String())
+	s.publishEvent(wsevents.TypeDeleted, wsID, "")
+	return nil
 }
 
 // GetWorkspacePath returns the filesystem path for a workspace.
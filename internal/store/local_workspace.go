@@ -22,6 +22,8 @@ type LocalWorkspace struct {
 	OriginAction   string         `json:"origin_action,omitempty"`
 	OriginTomlHash string         `json:"origin_toml_hash,omitempty"`
 	OriginLockHash string         `json:"origin_lock_hash,omitempty"`
+	OriginVersion  int            `json:"origin_version,omitempty"` // Server version number of the last push/pull
+	PullHook       string         `json:"pull_hook,omitempty"`      // Command run after a successful pull into Path
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
@@ -1,9 +1,11 @@
 package store
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func testStore(t *testing.T) *Store {
@@ -526,3 +528,64 @@ func TestDefaults(t *testing.T) {
 		t.Errorf("expected package_manager 'pixi', got %q", got.PackageManager)
 	}
 }
+
+func TestRecordPull(t *testing.T) {
+	s := testStore(t)
+
+	pulled := func(version int, tomlHash string) *LocalWorkspace {
+		return &LocalWorkspace{
+			Name:           "project",
+			Path:           "/home/user/project",
+			OriginID:       "remote-1",
+			OriginName:     "my-env",
+			OriginTag:      "v1",
+			OriginAction:   "pull",
+			OriginVersion:  version,
+			OriginTomlHash: tomlHash,
+		}
+	}
+
+	ws, err := s.RecordPull(pulled(1, "hash-1"), "toml-1", "lock-1", "Pulled my-env:v1 (version 1)")
+	if err != nil {
+		t.Fatalf("RecordPull: %v", err)
+	}
+	versions, _ := s.ListVersions(ws.ID)
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 local version, got %d", len(versions))
+	}
+
+	// Simulate a crash after the origin is written but before the version
+	// snapshot: the whole update must roll back.
+	crash := errors.New("simulated crash")
+	if err := s.db.Callback().Create().Before("gorm:create").Register("test:crash", func(db *gorm.DB) {
+		if db.Statement.Table == "workspace_versions" {
+			db.AddError(crash)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RecordPull(pulled(2, "hash-2"), "toml-2", "lock-2", "Pulled my-env:v1 (version 2)"); !errors.Is(err, crash) {
+		t.Fatalf("expected simulated crash, got %v", err)
+	}
+
+	got, _ := s.FindWorkspaceByPath("/home/user/project")
+	if got.OriginVersion != 1 || got.OriginTomlHash != "hash-1" {
+		t.Fatalf("origin changed by failed pull: version=%d hash=%q", got.OriginVersion, got.OriginTomlHash)
+	}
+
+	// Pulling again after the crash repairs the index.
+	if err := s.db.Callback().Create().Remove("test:crash"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RecordPull(pulled(2, "hash-2"), "toml-2", "lock-2", "Pulled my-env:v1 (version 2)"); err != nil {
+		t.Fatalf("RecordPull retry: %v", err)
+	}
+	got, _ = s.FindWorkspaceByPath("/home/user/project")
+	if got.ID != ws.ID || got.OriginVersion != 2 || got.OriginTomlHash != "hash-2" {
+		t.Fatalf("unexpected workspace after retry: id=%s version=%d hash=%q", got.ID, got.OriginVersion, got.OriginTomlHash)
+	}
+	versions, _ = s.ListVersions(ws.ID)
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 local versions, got %d", len(versions))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // ListWorkspaces returns all workspaces.
@@ -62,6 +63,10 @@ func (s *Store) FindWorkspacesByName(name string) ([]LocalWorkspace, error) {
 
 // CreateWorkspace creates a new workspace record.
 func (s *Store) CreateWorkspace(ws *LocalWorkspace) error {
+	return createWorkspace(s.db, ws)
+}
+
+func createWorkspace(db *gorm.DB, ws *LocalWorkspace) error {
 	if ws.ID == uuid.Nil {
 		ws.ID = uuid.New()
 	}
@@ -74,7 +79,50 @@ func (s *Store) CreateWorkspace(ws *LocalWorkspace) error {
 	if ws.PackageManager == "" {
 		ws.PackageManager = "pixi"
 	}
-	return s.db.Create(ws).Error
+	return db.Create(ws).Error
+}
+
+// RecordPull records a completed pull into the directory at ws.Path. The
+// directory is tracked if it is not already, its origin fields are replaced
+// with those on ws, and the pulled content is snapshotted as a local version.
+// All of this happens in one transaction, so a pull that dies part way
+// leaves the index either as it was or fully updated. Returns the stored
+// workspace record.
+func (s *Store) RecordPull(ws *LocalWorkspace, manifest, lock, description string) (*LocalWorkspace, error) {
+	var saved *LocalWorkspace
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing LocalWorkspace
+		result := tx.Where("path = ?", ws.Path).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return fmt.Errorf("finding workspace by path: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			if err := createWorkspace(tx, ws); err != nil {
+				return fmt.Errorf("saving workspace: %w", err)
+			}
+			saved = ws
+		} else {
+			existing.OriginID = ws.OriginID
+			existing.OriginName = ws.OriginName
+			existing.OriginTag = ws.OriginTag
+			existing.OriginAction = ws.OriginAction
+			existing.OriginVersion = ws.OriginVersion
+			existing.OriginTomlHash = ws.OriginTomlHash
+			existing.OriginLockHash = ws.OriginLockHash
+			if err := tx.Save(&existing).Error; err != nil {
+				return fmt.Errorf("saving origin: %w", err)
+			}
+			saved = &existing
+		}
+
+		_, _, err := createVersion(tx, s.localUserID, saved.ID, manifest, lock, description)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
 }
 
 // SaveWorkspace updates an existing workspace record.
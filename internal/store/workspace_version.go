@@ -21,13 +21,17 @@ func (s *Store) CreateVersion(
 	lockContent string,
 	description string,
 ) (*LocalWorkspaceVersion, bool, error) {
+	return createVersion(s.db, s.localUserID, wsID, manifestContent, lockContent, description)
+}
+
+func createVersion(db *gorm.DB, createdBy, wsID uuid.UUID, manifestContent, lockContent, description string) (*LocalWorkspaceVersion, bool, error) {
 	hash := contenthash.Hash(manifestContent, lockContent)
 
 	// Dedup: if the latest version already has this hash, reuse it. Only
 	// select the small scalar columns — the manifest/lock TEXT columns can
 	// be large and we don't need them for the hash comparison.
 	var latest LocalWorkspaceVersion
-	err := s.db.
+	err := db.
 		Select("id", "workspace_id", "version_number", "content_hash").
 		Where("workspace_id = ?", wsID).
 		Order("version_number DESC").
@@ -46,9 +50,9 @@ func (s *Store) CreateVersion(
 		PackageMetadata: "[]",
 		ContentHash:     hash,
 		Description:     description,
-		CreatedBy:       s.localUserID,
+		CreatedBy:       createdBy,
 	}
-	if err := s.db.Create(v).Error; err != nil {
+	if err := db.Create(v).Error; err != nil {
 		return nil, false, fmt.Errorf("creating version: %w", err)
 	}
 	return v, true, nil
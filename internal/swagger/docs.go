@@ -1328,6 +1328,66 @@ const docTemplate = `{
                 }
             }
         },
+        "/admin/users/{id}/workspaces": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List the workspaces a user can access, with their role on each (admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.UserWorkspace"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/auth/login": {
             "post": {
                 "description": "Authenticate user and return JWT token",
@@ -1376,6 +1436,15 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "429": {
+                        "description": "too many failed attempts; see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
@@ -1805,6 +1874,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "description": "Queues creation of a workspace. When pixi_lock (or initial_tag) is\ngiven alongside pixi_toml, the initial version is tagged (default \"v0\")\nand \"latest\" once the workspace is ready, so it can be pulled immediately.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1845,6 +1915,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Non-admin create rejected by workspaces.allow_push_autocreate",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1854,6 +1930,45 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Emits an event whenever a workspace visible to the caller is created, updated, deleted, or changes status.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Stream workspace lifecycle events via Server-Sent Events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Auth token (alternative to Bearer header for EventSource compatibility)",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{id}": {
             "get": {
                 "security": [
@@ -1881,7 +1996,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Workspace"
+                            "$ref": "#/definitions/service.WorkspaceResponse"
                         }
                     },
                     "401": {
@@ -1948,6 +2063,178 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/{id}/auto-latest-tag": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the tag every push moves to the pushed version; empty when not set",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Get the workspace's auto-latest tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.AutoLatestResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every push moves this tag to the pushed version, like \"latest\", without needing force. An empty tag clears the setting",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Set the workspace's auto-latest tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Auto-latest tag",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetAutoLatestTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.AutoLatestResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/clone": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new workspace owned by the caller from the newest version of\nthe source workspace. With history=true, the source's earlier versions\nand their tags are copied too, subject to workspaces.max_tags. The source\nis not modified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Clone a workspace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Copy the full version history",
+                        "name": "history",
+                        "in": "query"
+                    },
+                    {
+                        "description": "New workspace name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CloneWorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Workspace"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{id}/collaborators": {
             "get": {
                 "security": [
@@ -1984,6 +2271,147 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/{id}/default-platforms": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the workspace's default platforms, or the platforms declared in pixi.toml when none are set",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Get the platforms a solve targets by default",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.PlatformsResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "An empty list clears the setting so solves use the platforms declared in pixi.toml",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Set the platforms a solve targets by default",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Default platforms",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetDefaultPlatformsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.PlatformsResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{id}/impact": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Count what deleting a workspace would remove",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.WorkspaceImpact"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{id}/install": {
             "post": {
                 "security": [
@@ -2893,6 +3321,10 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "description": "Solves for the platforms in the request body when given, otherwise for the workspace's default platforms",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -2907,6 +3339,14 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Platform override",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SolveWorkspaceRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -2943,6 +3383,45 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/{id}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Get aggregate usage metrics for a workspace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.WorkspaceStats"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{id}/tags": {
             "get": {
                 "security": [
@@ -3049,13 +3528,14 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "description": "Results are capped by the server (workspaces.max_versions_listed). When older versions remain, the X-Next-Before header holds the cursor to pass as ?before= for the next page.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "workspaces"
                 ],
-                "summary": "List all versions for an workspace",
+                "summary": "List versions for a workspace, newest first",
                 "parameters": [
                     {
                         "type": "string",
@@ -3063,6 +3543,18 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum versions to return (capped by the server)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return versions numbered below this",
+                        "name": "before",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3073,6 +3565,18 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/models.WorkspaceVersion"
                             }
+                        },
+                        "headers": {
+                            "X-Next-Before": {
+                                "type": "integer",
+                                "description": "Cursor for the next page, if any"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
@@ -3186,6 +3690,12 @@ const docTemplate = `{
                         "name": "version",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Record the download as a pull in the audit log",
+                        "name": "pull",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3237,6 +3747,17 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.CloneWorkspaceRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "handlers.CreateGroupRequest": {
             "type": "object",
             "required": [
@@ -3306,6 +3827,10 @@ const docTemplate = `{
         "handlers.CreateWorkspaceRequest": {
             "type": "object",
             "properties": {
+                "initial_tag": {
+                    "description": "tag for that version; defaults to workspaces.initial_tag",
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -3315,6 +3840,10 @@ const docTemplate = `{
                 "path": {
                     "type": "string"
                 },
+                "pixi_lock": {
+                    "description": "optional; with pixi_toml, creates a tagged initial version",
+                    "type": "string"
+                },
                 "pixi_toml": {
                     "type": "string"
                 },
@@ -3452,6 +3981,13 @@ const docTemplate = `{
                 "deduplicated": {
                     "type": "boolean"
                 },
+                "evicted_tags": {
+                    "description": "oldest tags removed to stay within workspaces.max_tags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "tag": {
                     "type": "string"
                 },
@@ -3488,6 +4024,26 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.SetAutoLatestTagRequest": {
+            "type": "object",
+            "properties": {
+                "tag": {
+                    "description": "empty clears the setting",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SetDefaultPlatformsRequest": {
+            "type": "object",
+            "properties": {
+                "platforms": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "handlers.ShareWorkspaceRequest": {
             "type": "object",
             "required": [
@@ -3520,6 +4076,18 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.SolveWorkspaceRequest": {
+            "type": "object",
+            "properties": {
+                "platforms": {
+                    "description": "overrides the default platforms for this solve",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "handlers.UpdateGroupRequest": {
             "type": "object",
             "properties": {
@@ -3700,6 +4268,23 @@ const docTemplate = `{
                 "GroupSourceOIDC"
             ]
         },
+        "models.InstallStatus": {
+            "type": "string",
+            "enum": [
+                "not_installed",
+                "installing",
+                "installed",
+                "uninstalling",
+                "install_failed"
+            ],
+            "x-enum-varnames": [
+                "InstallStatusNotInstalled",
+                "InstallStatusInstalling",
+                "InstallStatusInstalled",
+                "InstallStatusUninstalling",
+                "InstallStatusFailed"
+            ]
+        },
         "models.Job": {
             "type": "object",
             "properties": {
@@ -3893,9 +4478,20 @@ const docTemplate = `{
         "models.Workspace": {
             "type": "object",
             "properties": {
+                "auto_latest_tag": {
+                    "description": "AutoLatestTag, when set, is moved to every pushed version alongside\n\"latest\", without needing --force.",
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "default_platforms": {
+                    "description": "DefaultPlatforms are the platforms a solve targets when the request\nnames none. Empty means the platforms declared in pixi.toml.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "id": {
                     "type": "string"
                 },
@@ -4004,6 +4600,15 @@ const docTemplate = `{
                 }
             }
         },
+        "service.AutoLatestResult": {
+            "type": "object",
+            "properties": {
+                "tag": {
+                    "description": "empty when not set",
+                    "type": "string"
+                }
+            }
+        },
         "service.CollaboratorKind": {
             "type": "string",
             "enum": [
@@ -4085,6 +4690,21 @@ const docTemplate = `{
                 }
             }
         },
+        "service.PlatformsResult": {
+            "type": "object",
+            "properties": {
+                "platforms": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "source": {
+                    "description": "\"workspace\" when set explicitly, \"manifest\" when read from pixi.toml",
+                    "type": "string"
+                }
+            }
+        },
         "service.PublicationResult": {
             "type": "object",
             "properties": {
@@ -4172,6 +4792,21 @@ const docTemplate = `{
                 }
             }
         },
+        "service.TagLimit": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "max": {
+                    "description": "0 means unlimited",
+                    "type": "integer"
+                },
+                "policy": {
+                    "type": "string"
+                }
+            }
+        },
         "service.UserWithAdmin": {
             "type": "object",
             "properties": {
@@ -4197,6 +4832,197 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "service.UserWorkspace": {
+            "type": "object",
+            "properties": {
+                "auto_latest_tag": {
+                    "description": "AutoLatestTag, when set, is moved to every pushed version alongside\n\"latest\", without needing --force.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_platforms": {
+                    "description": "DefaultPlatforms are the platforms a solve targets when the request\nnames none. Empty means the platforms declared in pixi.toml.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "install_status": {
+                    "$ref": "#/definitions/models.InstallStatus"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "package_manager": {
+                    "description": "\"pixi\" or \"uv\"",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "filesystem path (local-mode)",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "size_bytes": {
+                    "type": "integer"
+                },
+                "size_formatted": {
+                    "type": "string"
+                },
+                "source": {
+                    "description": "\"managed\", \"local\"",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkspaceStatus"
+                },
+                "tag_limit": {
+                    "description": "set by Get only",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.TagLimit"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.WorkspaceImpact": {
+            "type": "object",
+            "properties": {
+                "collaborator_count": {
+                    "description": "users and groups with shared access, excluding the owner",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "publication_count": {
+                    "type": "integer"
+                },
+                "tag_count": {
+                    "type": "integer"
+                },
+                "version_count": {
+                    "type": "integer"
+                },
+                "workspace_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.WorkspaceResponse": {
+            "type": "object",
+            "properties": {
+                "auto_latest_tag": {
+                    "description": "AutoLatestTag, when set, is moved to every pushed version alongside\n\"latest\", without needing --force.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_platforms": {
+                    "description": "DefaultPlatforms are the platforms a solve targets when the request\nnames none. Empty means the platforms declared in pixi.toml.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "install_status": {
+                    "$ref": "#/definitions/models.InstallStatus"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "package_manager": {
+                    "description": "\"pixi\" or \"uv\"",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "filesystem path (local-mode)",
+                    "type": "string"
+                },
+                "size_bytes": {
+                    "type": "integer"
+                },
+                "size_formatted": {
+                    "type": "string"
+                },
+                "source": {
+                    "description": "\"managed\", \"local\"",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkspaceStatus"
+                },
+                "tag_limit": {
+                    "description": "set by Get only",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.TagLimit"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.WorkspaceStats": {
+            "type": "object",
+            "properties": {
+                "last_activity": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pull_count": {
+                    "type": "integer"
+                },
+                "push_count": {
+                    "type": "integer"
+                },
+                "size_bytes": {
+                    "type": "integer"
+                },
+                "size_formatted": {
+                    "type": "string"
+                },
+                "tag_count": {
+                    "type": "integer"
+                },
+                "version_count": {
+                    "type": "integer"
+                },
+                "workspace_id": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
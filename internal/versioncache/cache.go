@@ -0,0 +1,129 @@
+// Package versioncache is a size-bounded in-memory LRU for workspace version
+// content. Version content is immutable once written, so entries only leave
+// the cache through eviction or when their workspace is deleted.
+package versioncache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// Cache holds version file content, evicting least recently used entries
+// once the total size of the cached content exceeds the byte budget.
+// A nil *Cache is valid and caches nothing.
+type Cache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	ll       *list.List
+	items    map[string]*list.Element
+
+	hits, misses, evictions uint64
+}
+
+type entry struct {
+	key   string
+	value string
+}
+
+// Stats is a snapshot of cache counters.
+type Stats struct {
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+	MaxBytes  int64  `json:"max_bytes"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// New returns a cache bounded to maxBytes of content. A non-positive
+// budget returns nil, which disables caching.
+func New(maxBytes int64) *Cache {
+	if maxBytes <= 0 {
+		return nil
+	}
+	return &Cache{maxBytes: maxBytes, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Key builds the cache key for one file of a workspace version.
+func Key(wsID, versionNum, field string) string {
+	return wsID + "/" + versionNum + "/" + field
+}
+
+// Get returns the cached content for key and marks it recently used.
+func (c *Cache) Get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		c.hits++
+		return el.Value.(*entry).value, true
+	}
+	c.misses++
+	return "", false
+}
+
+// Add stores content under key. Content larger than the whole budget is
+// not cached.
+func (c *Cache) Add(key, value string) {
+	if c == nil || int64(len(value)) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry)
+		c.size += int64(len(value)) - int64(len(e.value))
+		e.value = value
+		c.ll.MoveToFront(el)
+	} else {
+		c.items[key] = c.ll.PushFront(&entry{key: key, value: value})
+		c.size += int64(len(value))
+	}
+	for c.size > c.maxBytes {
+		c.removeElement(c.ll.Back())
+		c.evictions++
+	}
+}
+
+// InvalidateWorkspace drops every cached file belonging to wsID.
+func (c *Cache) InvalidateWorkspace(wsID string) {
+	if c == nil {
+		return
+	}
+	prefix := wsID + "/"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(el)
+		}
+	}
+}
+
+// Stats returns the current counters.
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		Entries:   c.ll.Len(),
+		Bytes:     c.size,
+		MaxBytes:  c.maxBytes,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+func (c *Cache) removeElement(el *list.Element) {
+	e := c.ll.Remove(el).(*entry)
+	delete(c.items, e.key)
+	c.size -= int64(len(e.value))
+}
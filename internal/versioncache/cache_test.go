@@ -0,0 +1,111 @@
+package versioncache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCache_EvictsByBytes(t *testing.T) {
+	c := New(10)
+	c.Add("a", "1234")
+	c.Add("b", "1234")
+	if _, ok := c.Get("a"); !ok { // a is now most recently used
+		t.Fatal("expected hit for a")
+	}
+	c.Add("c", "1234")
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("expected %s to be cached", k)
+		}
+	}
+	st := c.Stats()
+	if st.Bytes != 8 || st.Entries != 2 || st.Evictions != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+	if st.Hits != 3 || st.Misses != 1 {
+		t.Errorf("hits/misses = %d/%d, want 3/1", st.Hits, st.Misses)
+	}
+}
+
+func TestCache_SkipsOversizedValues(t *testing.T) {
+	c := New(4)
+	c.Add("a", "12")
+	c.Add("big", "12345")
+	if _, ok := c.Get("big"); ok {
+		t.Error("oversized value should not be cached")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("oversized value should not evict existing entries")
+	}
+}
+
+func TestCache_ReplaceUpdatesSize(t *testing.T) {
+	c := New(10)
+	c.Add("a", "12345678")
+	c.Add("a", "12")
+	if st := c.Stats(); st.Bytes != 2 || st.Entries != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestCache_InvalidateWorkspace(t *testing.T) {
+	c := New(100)
+	c.Add(Key("ws1", "1", "lock"), "x")
+	c.Add(Key("ws1", "2", "manifest"), "y")
+	c.Add(Key("ws10", "1", "lock"), "z")
+
+	c.InvalidateWorkspace("ws1")
+
+	if _, ok := c.Get(Key("ws1", "1", "lock")); ok {
+		t.Error("ws1 entries should be dropped")
+	}
+	if _, ok := c.Get(Key("ws10", "1", "lock")); !ok {
+		t.Error("ws10 entries should survive invalidating ws1")
+	}
+	if st := c.Stats(); st.Bytes != 1 {
+		t.Errorf("bytes = %d, want 1", st.Bytes)
+	}
+}
+
+func TestCache_NilIsDisabled(t *testing.T) {
+	c := New(0)
+	if c != nil {
+		t.Fatal("New(0) should disable the cache")
+	}
+	c.Add("a", "1")
+	if _, ok := c.Get("a"); ok {
+		t.Error("nil cache should never hit")
+	}
+	c.InvalidateWorkspace("ws")
+	_ = c.Stats()
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	c := New(64)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				k := fmt.Sprintf("ws%d/%d/lock", i, j%10)
+				if _, ok := c.Get(k); !ok {
+					c.Add(k, strings.Repeat("x", j%16))
+				}
+				if j%50 == 0 {
+					c.InvalidateWorkspace(fmt.Sprintf("ws%d", i))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if st := c.Stats(); st.Bytes > 64 || st.Bytes < 0 {
+		t.Errorf("bytes = %d, want within budget", st.Bytes)
+	}
+}
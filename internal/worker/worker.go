@@ -8,6 +8,9 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 	"github.com/nebari-dev/nebi/internal/executor"
 	"github.com/nebari-dev/nebi/internal/logstream"
 	"github.com/nebari-dev/nebi/internal/models"
+	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
 	"github.com/nebari-dev/nebi/internal/queue"
 	"github.com/nebari-dev/nebi/internal/service"
 	"github.com/valkey-io/valkey-go"
@@ -277,9 +281,32 @@ func (w *Worker) executeJob(ctx context.Context, job *models.Job, logWriter io.W
 
 		w.svc.SetWorkspaceStatus(ws.ID, models.WsStatusReady)
 
-		// Create version snapshot
+		// A history clone copies the source's earlier versions first, so the
+		// snapshot below becomes the clone's newest version.
+		if src, ok := job.Metadata["clone_history_from"].(string); ok && src != "" {
+			upTo, err := strconv.Atoi(fmt.Sprint(job.Metadata["clone_history_up_to"]))
+			if err == nil {
+				err = w.svc.CopyVersionHistory(ws.ID, src, upTo, userID)
+			}
+			if err != nil {
+				w.logger.Error("Failed to copy version history", "source", src, "error", err)
+				fmt.Fprintf(logWriter, "Warning: failed to copy version history: %v\n", err)
+			}
+		}
+
+		// Create version snapshot. When the request carried initial content,
+		// tag it so the workspace is immediately pullable. A tagging failure
+		// is logged but leaves the workspace ready, so it can still be pushed
+		// to or deleted rather than being stranded.
 		if err := w.svc.CreateVersionSnapshot(ctx, ws, job.ID, userID, "Initial workspace creation"); err != nil {
 			w.logger.Error("Failed to create version snapshot", "error", err)
+		} else if tag, ok := job.Metadata["initial_tag"].(string); ok && tag != "" {
+			if err := w.svc.TagInitialVersion(ws.ID, tag, userID); err != nil {
+				w.logger.Error("Failed to tag initial version", "tag", tag, "error", err)
+				fmt.Fprintf(logWriter, "Warning: failed to tag initial version as %q: %v\n", tag, err)
+			} else {
+				fmt.Fprintf(logWriter, "Tagged initial version as %q\n", tag)
+			}
 		}
 
 	case models.JobTypeInstall:
@@ -332,9 +359,27 @@ func (w *Worker) executeJob(ctx context.Context, job *models.Job, logWriter io.W
 
 		fmt.Fprintf(logWriter, "Solving environment from current pixi.toml...\n")
 
-		if err := w.executor.SolveEnvironment(ctx, ws, logWriter); err != nil {
+		// A platform override applies to this solve only: pixi.toml is put
+		// back before the snapshot, so the declared platforms stay as they
+		// were.
+		restoreManifest := func() error { return nil }
+		if platforms := parseStringsFromMetadata(job.Metadata, "platforms"); len(platforms) > 0 {
+			restore, err := w.overrideManifestPlatforms(ws, platforms, logWriter)
+			if err != nil {
+				w.svc.SetWorkspaceStatus(ws.ID, models.WsStatusFailed)
+				return err
+			}
+			restoreManifest = restore
+		}
+
+		solveErr := w.executor.SolveEnvironment(ctx, ws, logWriter)
+		if err := restoreManifest(); err != nil {
 			w.svc.SetWorkspaceStatus(ws.ID, models.WsStatusFailed)
-			return err
+			return fmt.Errorf("restore pixi.toml after solve: %w", err)
+		}
+		if solveErr != nil {
+			w.svc.SetWorkspaceStatus(ws.ID, models.WsStatusFailed)
+			return solveErr
 		}
 
 		if err := w.svc.SyncPackagesFromWorkspace(ctx, ws); err != nil {
@@ -489,6 +534,9 @@ func buildCreateWorkspaceOptions(metadata map[string]interface{}) executor.Creat
 	if v, ok := metadata["pixi_toml"].(string); ok {
 		opts.PixiToml = v
 	}
+	if v, ok := metadata["pixi_lock"].(string); ok {
+		opts.PixiLock = v
+	}
 	if v, ok := metadata["import_staging_dir"].(string); ok {
 		opts.SeedDir = v
 	}
@@ -498,25 +546,63 @@ func buildCreateWorkspaceOptions(metadata map[string]interface{}) executor.Creat
 // parsePackagesFromMetadata extracts the packages list from job metadata,
 // handling both []string and []interface{} (from JSON unmarshaling).
 func parsePackagesFromMetadata(metadata map[string]any) []string {
-	packagesInterface, ok := metadata["packages"]
+	return parseStringsFromMetadata(metadata, "packages")
+}
+
+// parseStringsFromMetadata reads a string list from job metadata, which
+// holds []interface{} once it has round-tripped through the database.
+func parseStringsFromMetadata(metadata map[string]any, key string) []string {
+	value, ok := metadata[key]
 	if !ok {
 		return nil
 	}
 
-	switch v := packagesInterface.(type) {
+	switch v := value.(type) {
 	case []string:
 		return v
 	case []interface{}:
-		packages := make([]string, len(v))
-		for i, p := range v {
-			packages[i] = fmt.Sprint(p)
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = fmt.Sprint(item)
 		}
-		return packages
+		return values
 	default:
 		return nil
 	}
 }
 
+// overrideManifestPlatforms rewrites the platforms array of the workspace's
+// pixi.toml so the following pixi lock solves for exactly platforms, and
+// returns a function that puts the original manifest back. The manifest is
+// left alone when it already declares them.
+func (w *Worker) overrideManifestPlatforms(ws *models.Workspace, platforms []string, logWriter io.Writer) (func() error, error) {
+	envPath := w.executor.GetWorkspacePath(ws)
+	content, err := os.ReadFile(filepath.Join(envPath, "pixi.toml"))
+	if err != nil {
+		return nil, fmt.Errorf("read pixi.toml: %w", err)
+	}
+	current, err := pixi.ExtractPlatforms(string(content))
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(current, platforms) {
+		fmt.Fprintf(logWriter, "Solving for platforms: %s\n", strings.Join(platforms, ", "))
+		return func() error { return nil }, nil
+	}
+
+	updated, err := pixi.SetPlatforms(string(content), platforms)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(logWriter, "Solving for platforms: %s (pixi.toml declares %s and is left unchanged)\n", strings.Join(platforms, ", "), strings.Join(current, ", "))
+	if err := writeFile(envPath, "pixi.toml", updated); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return writeFile(envPath, "pixi.toml", string(content))
+	}, nil
+}
+
 // writeFile writes content to a file at the given base path.
 func writeFile(basePath, filename, content string) error {
 	return os.WriteFile(filepath.Join(basePath, filename), []byte(content), 0644)
@@ -8,15 +8,20 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/glebarez/sqlite"
+	"github.com/nebari-dev/nebi/internal/audit"
+	"github.com/nebari-dev/nebi/internal/config"
 	"github.com/nebari-dev/nebi/internal/executor"
 	"github.com/nebari-dev/nebi/internal/models"
 	"github.com/nebari-dev/nebi/internal/pkgmgr"
+	"github.com/nebari-dev/nebi/internal/pkgmgr/pixi"
 	"github.com/nebari-dev/nebi/internal/queue"
 	"github.com/nebari-dev/nebi/internal/rbac"
 	"github.com/nebari-dev/nebi/internal/service"
+	"github.com/nebari-dev/nebi/internal/versioncache"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -57,6 +62,7 @@ type fakeExecutor struct {
 	installCalls   int
 	uninstallCalls int
 	solveCalls     int
+	solvedManifest string // pixi.toml as seen by the last solve
 }
 
 func (e *fakeExecutor) CreateWorkspace(ctx context.Context, ws *models.Workspace, w io.Writer, opts executor.CreateWorkspaceOptions) error {
@@ -80,8 +86,11 @@ func (e *fakeExecutor) RemovePackages(context.Context, *models.Workspace, []stri
 func (e *fakeExecutor) DeleteWorkspace(context.Context, *models.Workspace, io.Writer) error {
 	return nil
 }
-func (e *fakeExecutor) SolveEnvironment(context.Context, *models.Workspace, io.Writer) error {
+func (e *fakeExecutor) SolveEnvironment(_ context.Context, ws *models.Workspace, _ io.Writer) error {
 	e.solveCalls++
+	if content, err := os.ReadFile(filepath.Join(e.GetWorkspacePath(ws), "pixi.toml")); err == nil {
+		e.solvedManifest = string(content)
+	}
 	return e.solveErr
 }
 
@@ -354,6 +363,100 @@ func TestExecuteJob_EnvUninstallRemovesEnv(t *testing.T) {
 	}
 }
 
+// TestExecuteJob_DeleteInvalidatesSharedVersionCache proves a delete job run
+// by the worker drops the workspace from the version cache the API's service
+// reads through, when the two services share one cache as the server wires
+// them.
+func TestExecuteJob_DeleteInvalidatesSharedVersionCache(t *testing.T) {
+	db, svc, jobSvc, exec := setupWorkerTest(t)
+
+	cache := versioncache.New(1 << 20)
+	svc.SetVersionCache(cache)
+	apiSvc := service.New(db, queue.NewMemoryQueue(10), exec, true, nil, rbac.NewDefaultProvider())
+	apiSvc.SetVersionCache(cache)
+
+	ws, job := newTestWorkspace(t, db, exec, "cached-delete", models.JobTypeDelete, nil)
+	version := &models.WorkspaceVersion{
+		WorkspaceID:     ws.ID,
+		VersionNumber:   1,
+		ManifestContent: "[workspace]\nname = \"cached-delete\"\n",
+		CreatedBy:       ws.OwnerID,
+	}
+	if err := db.Create(version).Error; err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+	if _, err := apiSvc.GetVersionFile(ws.ID.String(), "1", "manifest"); err != nil {
+		t.Fatalf("GetVersionFile: %v", err)
+	}
+	if st := cache.Stats(); st.Entries != 1 {
+		t.Fatalf("entries before delete = %d, want 1", st.Entries)
+	}
+
+	w := New(queue.NewMemoryQueue(10), exec, svc, jobSvc, slog.Default(), nil)
+	if err := w.executeJob(context.Background(), job, &bytes.Buffer{}); err != nil {
+		t.Fatalf("executeJob: %v", err)
+	}
+
+	if st := cache.Stats(); st.Entries != 0 {
+		t.Errorf("entries after worker delete = %d, want 0", st.Entries)
+	}
+}
+
+// TestExecuteJob_HistoryCloneAppliesTagLimit proves a history clone run by
+// the worker enforces workspaces.max_tags, with the worker's service
+// configured the way the server configures it.
+func TestExecuteJob_HistoryCloneAppliesTagLimit(t *testing.T) {
+	db, svc, jobSvc, exec := setupWorkerTest(t)
+
+	apiSvc := service.New(db, queue.NewMemoryQueue(10), exec, true, nil, rbac.NewDefaultProvider())
+
+	user := models.User{Username: "alice", Email: "alice@example.com"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	src := &models.Workspace{Name: "tagged", OwnerID: user.ID, Status: models.WsStatusReady, PackageManager: testPackageManager}
+	if err := db.Create(src).Error; err != nil {
+		t.Fatalf("create ws: %v", err)
+	}
+	ctx := context.Background()
+	for _, tag := range []string{"v1", "v2", "v3"} {
+		toml := "[workspace]\nname = \"tagged\"\n# " + tag + "\n"
+		if _, err := apiSvc.PushVersion(ctx, src.ID.String(), service.PushRequest{Tag: tag, PixiToml: toml, PixiLock: "version: 6\n"}, user.ID); err != nil {
+			t.Fatalf("push %s: %v", tag, err)
+		}
+	}
+
+	// With the limit in place, v1 and v2 are copied and v3 becomes the
+	// clone's initial tag: one over.
+	cfg := config.WorkspacesConfig{MaxTags: 2, TagLimitPolicy: service.TagLimitEvictOldest}
+	apiSvc.ApplyWorkspacesConfig(cfg)
+	svc.ApplyWorkspacesConfig(cfg)
+	fork, err := apiSvc.Clone(ctx, src.ID.String(), service.CloneRequest{Name: "fork", History: true}, user.ID)
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	var job models.Job
+	if err := db.Where("workspace_id = ?", fork.ID).First(&job).Error; err != nil {
+		t.Fatalf("load job: %v", err)
+	}
+
+	w := New(queue.NewMemoryQueue(10), exec, svc, jobSvc, slog.Default(), nil)
+	if err := w.executeJob(ctx, &job, &bytes.Buffer{}); err != nil {
+		t.Fatalf("executeJob: %v", err)
+	}
+
+	var tags []string
+	db.Model(&models.WorkspaceTag{}).Where("workspace_id = ? AND tag LIKE ?", fork.ID, "v%").Order("tag").Pluck("tag", &tags)
+	if !slices.Equal(tags, []string{"v2", "v3"}) {
+		t.Errorf("clone user tags = %v, want [v2 v3]", tags)
+	}
+	var logs int64
+	db.Model(&models.AuditLog{}).Where("action = ?", audit.ActionEvictTag).Count(&logs)
+	if logs != 1 {
+		t.Errorf("expected 1 evict_tag audit entry, got %d", logs)
+	}
+}
+
 // TestExecuteJob_UpdateAutoInstallsWhenPreviouslyInstalled proves a manifest
 // update keeps an installed environment in sync: solve refreshes the lock,
 // then the environment is reinstalled automatically (local mode).
@@ -375,6 +478,54 @@ func TestExecuteJob_UpdateAutoInstallsWhenPreviouslyInstalled(t *testing.T) {
 	}
 }
 
+// TestExecuteJob_UpdateOverridesManifestPlatforms proves a solve with
+// platforms in its metadata locks for exactly those platforms, while the
+// workspace's pixi.toml and the snapshot taken from it keep the declared
+// platforms.
+func TestExecuteJob_UpdateOverridesManifestPlatforms(t *testing.T) {
+	db, svc, jobSvc, exec := setupWorkerTest(t)
+
+	ws, job := newTestWorkspace(t, db, exec, "update-platforms", models.JobTypeUpdate, map[string]interface{}{
+		"platforms": []interface{}{"linux-64", "osx-arm64"},
+	})
+	const declared = "[workspace]\nname = \"x\"\nplatforms = [\"linux-64\"]\n"
+	manifest := filepath.Join(exec.GetWorkspacePath(ws), "pixi.toml")
+	if err := os.WriteFile(manifest, []byte(declared), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := New(queue.NewMemoryQueue(10), exec, svc, jobSvc, slog.Default(), nil)
+	if err := w.executeJob(context.Background(), job, &bytes.Buffer{}); err != nil {
+		t.Fatalf("executeJob: %v", err)
+	}
+
+	if exec.solveCalls != 1 {
+		t.Fatalf("expected one solve, got %d", exec.solveCalls)
+	}
+	platforms, err := pixi.ExtractPlatforms(exec.solvedManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(platforms, []string{"linux-64", "osx-arm64"}) {
+		t.Errorf("expected the solve to see the override, got %v", platforms)
+	}
+
+	content, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != declared {
+		t.Errorf("pixi.toml changed by the override:\n%s", content)
+	}
+	var version models.WorkspaceVersion
+	if err := db.Where("workspace_id = ?", ws.ID).Order("version_number DESC").First(&version).Error; err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	if version.ManifestContent != declared {
+		t.Errorf("snapshot recorded the override:\n%s", version.ManifestContent)
+	}
+}
+
 // TestExecuteJob_UpdateSkipsAutoInstallWhenNotInstalled proves updating a
 // never-installed workspace stops at the lockfile.
 func TestExecuteJob_UpdateSkipsAutoInstallWhenNotInstalled(t *testing.T) {
@@ -0,0 +1,72 @@
+// Package wsevents fans out workspace lifecycle events (create, update,
+// delete, status change) to live subscribers such as the SSE endpoint.
+package wsevents
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Event types emitted for workspaces.
+const (
+	TypeCreated = "created"
+	TypeUpdated = "updated"
+	TypeDeleted = "deleted"
+	TypeStatus  = "status"
+)
+
+// Event describes a change to a single workspace.
+type Event struct {
+	Type        string    `json:"type"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	Status      string    `json:"status,omitempty"`
+}
+
+// Broker manages subscribers for workspace events
+type Broker struct {
+	subscribers map[chan Event]bool
+	mu          sync.RWMutex
+}
+
+// NewBroker creates a new workspace event broker
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[chan Event]bool),
+	}
+}
+
+// Subscribe registers a new subscriber and returns its event channel
+func (b *Broker) Subscribe() chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, 100) // Buffered channel to prevent blocking
+	b.subscribers[ch] = true
+	return ch
+}
+
+// Unsubscribe removes a subscription and closes its channel
+func (b *Broker) Unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.subscribers[ch]; exists {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Publish sends an event to all subscribers
+func (b *Broker) Publish(ev Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		// Non-blocking send - drop if channel is full
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}